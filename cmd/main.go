@@ -1,8 +1,12 @@
 package main
 
 import (
+	"context"
+
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/app/calendar"
 	"github.com/AthulKrishna2501/zyra-vendor-service/internals/app/config"
 	"github.com/AthulKrishna2501/zyra-vendor-service/internals/app/grpc"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/app/invoice"
 	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/database"
 	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/repository"
 	"github.com/AthulKrishna2501/zyra-vendor-service/internals/logger"
@@ -24,7 +28,40 @@ func main() {
 		return
 	}
 
-	VendorRepo := repository.NewVendorRepository(db)
+	escrowEmail := configEnv.TREASURY_ESCROW_EMAIL
+	if escrowEmail == "" {
+		escrowEmail = configEnv.ADMIN_EMAIL
+	}
+
+	VendorRepo := repository.NewVendorRepository(db, repository.Treasury{
+		Escrow:         escrowEmail,
+		Fees:           configEnv.TREASURY_FEES_EMAIL,
+		Refunds:        configEnv.TREASURY_REFUNDS_EMAIL,
+		ClientPayments: configEnv.ADMIN_EMAIL,
+	})
+
+	if err := VendorRepo.CheckTreasury(context.Background()); err != nil {
+		log.Error("Treasury accounts are not set up", err)
+		return
+	}
+
+	merged, err := VendorRepo.MergeDuplicateClientWallets(context.Background())
+	if err != nil {
+		log.Error("Failed to merge duplicate client wallets", err)
+		return
+	}
+	if merged > 0 {
+		log.Info("Merged duplicate client wallets", merged)
+	}
+
+	seeded, err := VendorRepo.BackfillLedgerOpeningBalances(context.Background())
+	if err != nil {
+		log.Error("Failed to backfill ledger opening balances", err)
+		return
+	}
+	if seeded > 0 {
+		log.Info("Seeded ledger opening balances", seeded)
+	}
 
 	err = grpc.StartgRPCServer(VendorRepo, log, configEnv)
 
@@ -34,6 +71,11 @@ func main() {
 	}
 
 	router := gin.Default()
+	calendarHandler := calendar.NewHandler(VendorRepo, log)
+	router.GET("/calendar/:token", calendarHandler.ServeFeed)
+	invoiceHandler := invoice.NewHandler(VendorRepo, log)
+	router.GET("/invoices/:token", invoiceHandler.ServeInvoice)
+
 	log.Info("HTTP Server started on port 3004")
 	router.Run(":3004")
 
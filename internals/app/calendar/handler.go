@@ -0,0 +1,67 @@
+package calendar
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/repository"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/logger"
+	"github.com/AthulKrishna2501/zyra-vendor-service/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	vendorRepo repository.VendorRepository
+	log        logger.Logger
+}
+
+func NewHandler(vendorRepo repository.VendorRepository, log logger.Logger) *Handler {
+	return &Handler{vendorRepo: vendorRepo, log: log}
+}
+
+func (h *Handler) ServeFeed(c *gin.Context) {
+	token := strings.TrimSuffix(c.Param("token"), ".ics")
+	if token == "" {
+		c.String(http.StatusNotFound, "calendar not found")
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	feed, err := h.vendorRepo.GetCalendarFeedByToken(ctx, token)
+	if err != nil {
+		c.String(http.StatusNotFound, "calendar not found")
+		return
+	}
+
+	bookings, err := h.vendorRepo.GetVendorCalendarBookings(ctx, feed.VendorID.String())
+	if err != nil {
+		h.log.Error("Failed to fetch calendar bookings", feed.VendorID, err)
+		c.String(http.StatusInternalServerError, "failed to build calendar")
+		return
+	}
+
+	var events []utils.CalendarEvent
+	for _, booking := range bookings {
+		eventStatus := "TENTATIVE"
+		if booking.Status == "approved" {
+			eventStatus = "CONFIRMED"
+		}
+
+		events = append(events, utils.CalendarEvent{
+			UID:          booking.BookingID + "@zyra",
+			Summary:      fmt.Sprintf("%s - %s", booking.Service, booking.ClientName),
+			Description:  fmt.Sprintf("Client: %s\nStatus: %s", booking.ClientName, booking.Status),
+			Start:        booking.Date,
+			End:          booking.Date.Add(time.Duration(booking.ServiceDuration) * time.Hour),
+			Status:       eventStatus,
+			LastModified: booking.UpdatedAt,
+		})
+	}
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Content-Disposition", `inline; filename="bookings.ics"`)
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", []byte(utils.BuildICalendar("Zyra Bookings", events)))
+}
@@ -7,15 +7,36 @@ import (
 )
 
 type Config struct {
-	PORT        string `mapstructure:"PORT"`
-	DB_URL      string `mapstructure:"DB_URL"`
+	PORT   string `mapstructure:"PORT"`
+	DB_URL string `mapstructure:"DB_URL"`
+
+	DISPUTE_WINDOW_HOURS         int `mapstructure:"DISPUTE_WINDOW_HOURS"`
+	PAYOUT_RELEASE_INTERVAL_MINS int `mapstructure:"PAYOUT_RELEASE_INTERVAL_MINS"`
+	SETTLEMENT_PERIOD_DAYS       int `mapstructure:"SETTLEMENT_PERIOD_DAYS"`
+
+	PUBLIC_BASE_URL             string `mapstructure:"PUBLIC_BASE_URL"`
+	CALENDAR_SYNC_INTERVAL_MINS int    `mapstructure:"CALENDAR_SYNC_INTERVAL_MINS"`
+
+	WAITLIST_OFFER_HOURS int `mapstructure:"WAITLIST_OFFER_HOURS"`
+
+	WITHDRAWAL_MIN_AMOUNT  int64 `mapstructure:"WITHDRAWAL_MIN_AMOUNT"`
+	WITHDRAWAL_DAILY_LIMIT int64 `mapstructure:"WITHDRAWAL_DAILY_LIMIT"`
+
+	PAYOUT_PROVIDER string `mapstructure:"PAYOUT_PROVIDER"`
+
+	// ADMIN_EMAIL is the admin wallet the client service pays booking money
+	// into. TREASURY_ESCROW_EMAIL defaults to it and must match it.
 	ADMIN_EMAIL string `mapstructure:"ADMIN_EMAIL"`
+
+	TREASURY_ESCROW_EMAIL  string `mapstructure:"TREASURY_ESCROW_EMAIL"`
+	TREASURY_FEES_EMAIL    string `mapstructure:"TREASURY_FEES_EMAIL"`
+	TREASURY_REFUNDS_EMAIL string `mapstructure:"TREASURY_REFUNDS_EMAIL"`
 }
 
 func LoadConfig() (cfg Config, err error) {
 	viper.SetConfigType("env")
 	viper.AutomaticEnv()
- 
+
 	viper.SetConfigFile(".env")
 	if err := viper.ReadInConfig(); err == nil {
 		log.Println("Loaded .env from the current directory")
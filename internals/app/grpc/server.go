@@ -1,10 +1,12 @@
 package grpc
 
 import (
+	"context"
 	"net"
 
 	"github.com/AthulKrishna2501/proto-repo/vendor"
 	"github.com/AthulKrishna2501/zyra-vendor-service/internals/app/config"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/app/payout"
 	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/repository"
 	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/services"
 	"github.com/AthulKrishna2501/zyra-vendor-service/internals/logger"
@@ -12,6 +14,14 @@ import (
 )
 
 func StartgRPCServer(VendorRepo repository.VendorRepository, log logger.Logger, cfg config.Config) error {
+	payouts, err := payout.New(cfg.PAYOUT_PROVIDER)
+	if err != nil {
+		return err
+	}
+	if payouts == nil {
+		log.Warn("No payout provider configured, withdrawals cannot be approved")
+	}
+
 	go func() {
 		lis, err := net.Listen("tcp", ":5004")
 		if err != nil {
@@ -23,9 +33,14 @@ func StartgRPCServer(VendorRepo repository.VendorRepository, log logger.Logger,
 			grpc.MaxRecvMsgSize(1024*1024*100),
 			grpc.MaxSendMsgSize(1024*1024*100),
 		)
-		vendorService := services.NewVendorService(VendorRepo, log, cfg)
+		vendorService := services.NewVendorService(VendorRepo, log, cfg, payouts)
 		vendor.RegisterVendorSeviceServer(grpcServer, vendorService)
 
+		go vendorService.StartSettlementScheduler(context.Background())
+		go vendorService.StartCalendarSync(context.Background())
+		go vendorService.StartWaitlistOfferExpiry(context.Background())
+		go vendorService.StartWithdrawalReconciler(context.Background())
+
 		log.Info("gRPC Server started on port 5004")
 		if err := grpcServer.Serve(lis); err != nil {
 			log.Error("Failed to serve gRPC: %v", err)
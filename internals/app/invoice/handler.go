@@ -0,0 +1,63 @@
+package invoice
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/invoice"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/repository"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/logger"
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	vendorRepo repository.VendorRepository
+	log        logger.Logger
+}
+
+func NewHandler(vendorRepo repository.VendorRepository, log logger.Logger) *Handler {
+	return &Handler{vendorRepo: vendorRepo, log: log}
+}
+
+// ServeInvoice serves /invoices/<token>.pdf or /invoices/<token>.html. A token
+// without an extension gets the PDF.
+func (h *Handler) ServeInvoice(c *gin.Context) {
+	name := c.Param("token")
+	format := strings.TrimPrefix(path.Ext(name), ".")
+	token := strings.TrimSuffix(name, path.Ext(name))
+	if format == "" {
+		format = invoice.FormatPDF
+	}
+	if token == "" {
+		c.String(http.StatusNotFound, "invoice not found")
+		return
+	}
+
+	inv, err := h.vendorRepo.GetInvoiceByToken(c.Request.Context(), token)
+	if err != nil {
+		c.String(http.StatusNotFound, "invoice not found")
+		return
+	}
+
+	content, contentType, err := invoice.Render(inv, format)
+	if errors.Is(err, invoice.ErrUnknownFormat) {
+		c.String(http.StatusNotFound, "invoice not found")
+		return
+	} else if err != nil {
+		h.log.Error("Failed to render invoice", inv.ID, err)
+		c.String(http.StatusInternalServerError, "failed to render invoice")
+		return
+	}
+
+	disposition := "inline"
+	if format == invoice.FormatPDF {
+		disposition = "attachment"
+	}
+
+	c.Header("Cache-Control", "private, no-cache")
+	c.Header("Content-Disposition", fmt.Sprintf(`%s; filename="%s"`, disposition, invoice.FileName(inv, format)))
+	c.Data(http.StatusOK, contentType, content)
+}
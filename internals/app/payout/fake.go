@@ -0,0 +1,45 @@
+package payout
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/money"
+	"github.com/google/uuid"
+)
+
+var ErrFakePayoutDeclined = fmt.Errorf("fake %w", ErrDeclined)
+
+// FakeProvider settles payouts in memory so withdrawals can be exercised
+// without a real payment provider. Amounts above FailAbove are declined when
+// it is set. It is only used when PAYOUT_PROVIDER is "fake".
+type FakeProvider struct {
+	FailAbove money.Money
+
+	mu      sync.Mutex
+	payouts map[string]string
+}
+
+func NewFakeProvider() *FakeProvider {
+	return &FakeProvider{payouts: make(map[string]string)}
+}
+
+func (p *FakeProvider) Send(ctx context.Context, req Request) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if ref, ok := p.payouts[req.Reference]; ok {
+		return ref, nil
+	}
+
+	if p.FailAbove.IsPositive() {
+		if below, err := p.FailAbove.LessThan(req.Amount); err != nil || below {
+			return "", ErrFakePayoutDeclined
+		}
+	}
+
+	ref := "fake_" + uuid.NewString()
+	p.payouts[req.Reference] = ref
+	return ref, nil
+}
@@ -0,0 +1,107 @@
+package payout
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/money"
+	"github.com/google/uuid"
+)
+
+func TestFakeProvider(t *testing.T) {
+	major := func(amount int64) money.Money {
+		m, err := money.FromMajor(amount)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return m
+	}
+
+	tests := []struct {
+		name        string
+		failAbove   money.Money
+		amount      money.Money
+		wantDecline bool
+	}{
+		{name: "no limit", amount: major(1000000)},
+		{name: "below the limit", failAbove: major(1000), amount: major(999)},
+		{name: "at the limit", failAbove: major(1000), amount: major(1000)},
+		{name: "above the limit", failAbove: major(1000), amount: major(1001), wantDecline: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := NewFakeProvider()
+			provider.FailAbove = tt.failAbove
+			req := Request{Reference: uuid.NewString(), VendorID: uuid.New(), Amount: tt.amount}
+
+			ref, err := provider.Send(context.Background(), req)
+			if tt.wantDecline {
+				if !errors.Is(err, ErrDeclined) {
+					t.Fatalf("Send error = %v, want a decline", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Send: %v", err)
+			}
+			if ref == "" {
+				t.Fatal("Send returned an empty reference")
+			}
+
+			again, err := provider.Send(context.Background(), req)
+			if err != nil || again != ref {
+				t.Errorf("resending = %q, %v; want the original reference %q", again, err, ref)
+			}
+
+			other, err := provider.Send(context.Background(), Request{Reference: uuid.NewString(), VendorID: req.VendorID, Amount: req.Amount})
+			if err != nil || other == ref {
+				t.Errorf("a new reference got %q, %v; want a new payout", other, err)
+			}
+		})
+	}
+}
+
+func TestFakeProviderPaidBeforeLimitChange(t *testing.T) {
+	provider := NewFakeProvider()
+	amount, _ := money.FromMajor(5000)
+	req := Request{Reference: uuid.NewString(), VendorID: uuid.New(), Amount: amount}
+
+	ref, err := provider.Send(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	provider.FailAbove, _ = money.FromMajor(100)
+	again, err := provider.Send(context.Background(), req)
+	if err != nil || again != ref {
+		t.Errorf("resending a paid payout = %q, %v; want %q", again, err, ref)
+	}
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name     string
+		wantNil  bool
+		wantFake bool
+		wantErr  bool
+	}{
+		{name: "", wantNil: true},
+		{name: "fake", wantFake: true},
+		{name: "stripe", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		provider, err := New(tt.name)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("New(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+		if (provider == nil) != (tt.wantNil || tt.wantErr) {
+			t.Errorf("New(%q) = %v", tt.name, provider)
+		}
+		if _, fake := provider.(*FakeProvider); fake != tt.wantFake {
+			t.Errorf("New(%q) fake = %v, want %v", tt.name, fake, tt.wantFake)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package payout
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/money"
+	"github.com/google/uuid"
+)
+
+// ErrDeclined marks a payout the provider definitely refused, so no money left
+// the platform. Providers wrap it; any other error leaves the outcome unknown.
+var ErrDeclined = errors.New("payout declined")
+
+// Request describes money to send to a vendor. Reference is unique per
+// withdrawal and lets providers recognise a retried payout.
+type Request struct {
+	Reference string
+	VendorID  uuid.UUID
+	Amount    money.Money
+}
+
+// Provider sends approved withdrawals to vendors and returns the provider's
+// reference for the transfer. Calling Send again with the same Reference
+// must not pay the vendor twice.
+type Provider interface {
+	Send(ctx context.Context, req Request) (string, error)
+}
+
+// New returns the provider configured by name. An empty name means no
+// provider is configured and returns nil, which disables payouts.
+func New(name string) (Provider, error) {
+	switch name {
+	case "":
+		return nil, nil
+	case "fake":
+		return NewFakeProvider(), nil
+	default:
+		return nil, fmt.Errorf("unknown payout provider %q", name)
+	}
+}
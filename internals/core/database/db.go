@@ -10,7 +10,7 @@ import (
 )
 
 func ConnectDatabase(env config.Config) *gorm.DB {
-	db, err := gorm.Open(postgres.Open(env.DB_URL), &gorm.Config{})
+	db, err := gorm.Open(postgres.Open(env.DB_URL), &gorm.Config{TranslateError: true})
 	if err != nil {
 		log.Fatal("Failed to connect to database", err)
 		return nil
@@ -27,11 +27,87 @@ func ConnectDatabase(env config.Config) *gorm.DB {
 }
 
 func AutoMigrate(db *gorm.DB) error {
-	return db.AutoMigrate(
+	err := db.AutoMigrate(
 		&models.Category{},
 		&models.CategoryRequest{},
 		&models.Service{},
+		&models.InstantBookingRule{},
+		&models.PaymentMilestone{},
 		&models.VendorCategory{},
 		&models.Wallet{},
+		&models.BookingCompletion{},
+		&models.BookingDispute{},
+		&models.BookingMessage{},
+		&models.MessageAttachment{},
+		&models.CalendarFeed{},
+		&models.ExternalCalendar{},
+		&models.BlockedTime{},
+		&models.BookingEvent{},
+		&models.BookingSnapshot{},
+		&models.BookingTransaction{},
+		&models.BookingInstallment{},
+		&models.OvertimeCharge{},
+		&models.Quote{},
+		&models.QuoteLineItem{},
+		&models.WaitlistEntry{},
+		&models.BundleDiscount{},
+		&models.BundleBooking{},
+		&models.BundleItem{},
+		&models.ClientBlock{},
+		&models.LedgerAccount{},
+		&models.LedgerTransaction{},
+		&models.LedgerEntry{},
+		&models.Withdrawal{},
+		&models.CommissionRule{},
+		&models.VendorTier{},
+		&models.PayoutCommission{},
+		&models.VendorBusinessProfile{},
+		&models.InvoiceSequence{},
+		&models.Invoice{},
+		&models.InvoiceLine{},
+		&models.Settlement{},
+		&models.SettlementLine{},
+		&models.QueuedPayout{},
+		&models.FundHold{},
 	)
+	if err != nil {
+		return err
+	}
+
+	if err := repairWallets(db); err != nil {
+		return err
+	}
+
+	if err := splitServicePrices(db); err != nil {
+		return err
+	}
+
+	if err := migrateMoneyToMinorUnits(db); err != nil {
+		return err
+	}
+
+	if err := addCurrencyColumns(db); err != nil {
+		return err
+	}
+
+	if err := releasePaidOvertime(db); err != nil {
+		return err
+	}
+
+	if err := renameApprovedWithdrawals(db); err != nil {
+		return err
+	}
+
+	if err := allowRepeatSettlements(db); err != nil {
+		return err
+	}
+
+	if err := reopenableFundHolds(db); err != nil {
+		return err
+	}
+
+	if err := protectBookingEvents(db); err != nil {
+		return err
+	}
+	return protectLedger(db)
 }
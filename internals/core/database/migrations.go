@@ -0,0 +1,81 @@
+package database
+
+import "gorm.io/gorm"
+
+// applyDataMigration runs migrate once per database. The marker row is
+// inserted in the same transaction as the migration, so a concurrent start
+// waits on it and then skips the migration.
+func applyDataMigration(db *gorm.DB, name string, migrate func(tx *gorm.DB) error) error {
+	if err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS data_migrations (
+			name       text PRIMARY KEY,
+			applied_at timestamptz NOT NULL DEFAULT now()
+		)
+	`).Error; err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Exec(`INSERT INTO data_migrations (name) VALUES (?) ON CONFLICT DO NOTHING`, name)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		return migrate(tx)
+	})
+}
+
+// releasePaidOvertime marks overtime charges paid before they were held in
+// escrow as released, since they went straight to the vendor's wallet.
+func releasePaidOvertime(db *gorm.DB) error {
+	return applyDataMigration(db, "overtime_escrow", func(tx *gorm.DB) error {
+		return tx.Exec(`UPDATE overtime_charges SET released_at = decided_at WHERE status = 'paid' AND released_at IS NULL`).Error
+	})
+}
+
+// renameApprovedWithdrawals moves withdrawals left approved by earlier
+// releases to processing, the status payouts in flight now use.
+func renameApprovedWithdrawals(db *gorm.DB) error {
+	return applyDataMigration(db, "withdrawal_processing", func(tx *gorm.DB) error {
+		return tx.Exec(`UPDATE withdrawals SET status = 'processing' WHERE status = 'approved'`).Error
+	})
+}
+
+// allowRepeatSettlements drops the unique indexes that allowed one settlement
+// per vendor and period and one settlement line per booking. A booking can now
+// be settled on approval and again on completion, and a booking left out of a
+// settlement is paid in another one for the same period.
+func allowRepeatSettlements(db *gorm.DB) error {
+	return applyDataMigration(db, "repeat_settlements", func(tx *gorm.DB) error {
+		for _, statement := range []string{
+			`DROP INDEX IF EXISTS idx_settlements_vendor_period`,
+			`CREATE INDEX idx_settlements_vendor_period ON settlements (vendor_id, period_start)`,
+			`DROP INDEX IF EXISTS idx_settlement_lines_booking_id`,
+			`CREATE INDEX idx_settlement_lines_booking_id ON settlement_lines (booking_id)`,
+		} {
+			if err := tx.Exec(statement).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// reopenableFundHolds limits the one-hold-per-source index to open holds, so
+// a booking that holds money again after its hold was released gets a new
+// hold and the old one keeps its released_at.
+func reopenableFundHolds(db *gorm.DB) error {
+	return applyDataMigration(db, "open_fund_holds", func(tx *gorm.DB) error {
+		for _, statement := range []string{
+			`DROP INDEX IF EXISTS idx_fund_holds_source`,
+			`CREATE UNIQUE INDEX idx_fund_holds_source ON fund_holds (source_type, source_id) WHERE released_at IS NULL`,
+		} {
+			if err := tx.Exec(statement).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
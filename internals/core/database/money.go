@@ -0,0 +1,117 @@
+package database
+
+import (
+	"fmt"
+
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/money"
+	"gorm.io/gorm"
+)
+
+// minorUnitColumns are the amounts this service owns, which used to be
+// stored in whole units and are now stored in minor units. Tables shared
+// with the other services keep whole units; services is one of them and is
+// handled by splitServicePrices.
+var minorUnitColumns = map[string][]string{
+	"booking_disputes":     {"refund_amount"},
+	"booking_installments": {"amount"},
+	"overtime_charges":     {"hourly_rate", "amount"},
+	"quotes":               {"total_price"},
+	"quote_line_items":     {"unit_price", "amount"},
+	"bundle_bookings":      {"subtotal", "discount_amount", "total"},
+	"bundle_items":         {"list_price", "price"},
+	"withdrawals":          {"amount"},
+	"ledger_entries":       {"amount"},
+}
+
+// migrateMoneyToMinorUnits scales existing amounts to minor units once.
+func migrateMoneyToMinorUnits(db *gorm.DB) error {
+	return applyDataMigration(db, "money_minor_units", func(tx *gorm.DB) error {
+		// The ledger is append-only; lift that for this one rewrite.
+		if err := tx.Exec(`ALTER TABLE ledger_entries DISABLE TRIGGER USER`).Error; err != nil {
+			return err
+		}
+
+		for table, columns := range minorUnitColumns {
+			for _, column := range columns {
+				query := fmt.Sprintf(`UPDATE %s SET %s = %s * ?`, table, column, column)
+				if err := tx.Exec(query, money.MinorPerMajor).Error; err != nil {
+					return fmt.Errorf("failed to convert %s.%s: %w", table, column, err)
+				}
+			}
+		}
+
+		return tx.Exec(`ALTER TABLE ledger_entries ENABLE TRIGGER USER`).Error
+	})
+}
+
+// splitServicePrices moves service prices into service_price_minor and
+// additional_hour_price_minor, leaving service_price and
+// additional_hour_price in whole units for the client service. An earlier
+// release scaled those shared columns in place as part of
+// money_minor_units; where it ran, they are scaled back. It must run before
+// migrateMoneyToMinorUnits so a fresh database is not mistaken for one.
+func splitServicePrices(db *gorm.DB) error {
+	return applyDataMigration(db, "service_prices_minor", func(tx *gorm.DB) error {
+		var scaled int64
+		if err := tx.Raw(`SELECT count(*) FROM data_migrations WHERE name = 'money_minor_units'`).Scan(&scaled).Error; err != nil {
+			return err
+		}
+
+		if scaled > 0 {
+			return tx.Exec(`
+				UPDATE services SET
+					service_price_minor = service_price,
+					additional_hour_price_minor = COALESCE(additional_hour_price, 0),
+					service_price = service_price / ?,
+					additional_hour_price = additional_hour_price / ?
+			`, money.MinorPerMajor, money.MinorPerMajor).Error
+		}
+		return tx.Exec(`
+			UPDATE services SET
+				service_price_minor = service_price * ?,
+				additional_hour_price_minor = COALESCE(additional_hour_price, 0) * ?
+		`, money.MinorPerMajor, money.MinorPerMajor).Error
+	})
+}
+
+// moneyTables are the tables holding amounts this service owns.
+var moneyTables = []string{
+	"services",
+	"booking_disputes",
+	"booking_installments",
+	"booking_snapshots",
+	"overtime_charges",
+	"quotes",
+	"quote_line_items",
+	"bundle_bookings",
+	"bundle_items",
+	"commission_rules",
+	"payout_commissions",
+	"fund_holds",
+	"invoices",
+	"invoice_lines",
+	"settlements",
+	"settlement_lines",
+	"queued_payouts",
+	"withdrawals",
+	"ledger_entries",
+}
+
+// addCurrencyColumns records the currency of every amount this service
+// owns. All of them are in money.DefaultCurrency, which the check
+// constraint enforces until rows can carry another currency.
+func addCurrencyColumns(db *gorm.DB) error {
+	return applyDataMigration(db, "money_currency", func(tx *gorm.DB) error {
+		for _, table := range moneyTables {
+			query := fmt.Sprintf(`
+				ALTER TABLE %s
+					ADD COLUMN IF NOT EXISTS currency char(3) NOT NULL DEFAULT '%s'
+					CONSTRAINT %s_currency_check CHECK (currency = '%s')
+			`, table, money.DefaultCurrency, table, money.DefaultCurrency)
+			if err := tx.Exec(query).Error; err != nil {
+				return fmt.Errorf("failed to add %s.currency: %w", table, err)
+			}
+		}
+		return nil
+	})
+}
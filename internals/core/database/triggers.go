@@ -0,0 +1,62 @@
+package database
+
+import "gorm.io/gorm"
+
+// protectBookingEvents makes booking_events append-only at the database level.
+func protectBookingEvents(db *gorm.DB) error {
+	return db.Exec(`
+		CREATE OR REPLACE FUNCTION reject_booking_event_change() RETURNS trigger AS $$
+		BEGIN
+			RAISE EXCEPTION 'booking_events is append-only';
+		END;
+		$$ LANGUAGE plpgsql;
+
+		DROP TRIGGER IF EXISTS booking_events_append_only ON booking_events;
+		CREATE TRIGGER booking_events_append_only
+			BEFORE UPDATE OR DELETE ON booking_events
+			FOR EACH ROW EXECUTE FUNCTION reject_booking_event_change();
+	`).Error
+}
+
+// protectLedger makes the ledger append-only and refuses to commit any ledger
+// transaction whose debits and credits differ.
+func protectLedger(db *gorm.DB) error {
+	return db.Exec(`
+		CREATE OR REPLACE FUNCTION reject_ledger_change() RETURNS trigger AS $$
+		BEGIN
+			RAISE EXCEPTION '% is append-only', TG_TABLE_NAME;
+		END;
+		$$ LANGUAGE plpgsql;
+
+		DROP TRIGGER IF EXISTS ledger_transactions_append_only ON ledger_transactions;
+		CREATE TRIGGER ledger_transactions_append_only
+			BEFORE UPDATE OR DELETE ON ledger_transactions
+			FOR EACH ROW EXECUTE FUNCTION reject_ledger_change();
+
+		DROP TRIGGER IF EXISTS ledger_entries_append_only ON ledger_entries;
+		CREATE TRIGGER ledger_entries_append_only
+			BEFORE UPDATE OR DELETE ON ledger_entries
+			FOR EACH ROW EXECUTE FUNCTION reject_ledger_change();
+
+		CREATE OR REPLACE FUNCTION check_ledger_balance() RETURNS trigger AS $$
+		DECLARE
+			diff bigint;
+		BEGIN
+			SELECT COALESCE(SUM(CASE WHEN direction = 'debit' THEN amount ELSE -amount END), 0)
+				INTO diff
+				FROM ledger_entries
+				WHERE transaction_id = NEW.transaction_id;
+			IF diff <> 0 THEN
+				RAISE EXCEPTION 'ledger transaction % does not balance (off by %)', NEW.transaction_id, diff;
+			END IF;
+			RETURN NULL;
+		END;
+		$$ LANGUAGE plpgsql;
+
+		DROP TRIGGER IF EXISTS ledger_entries_balanced ON ledger_entries;
+		CREATE CONSTRAINT TRIGGER ledger_entries_balanced
+			AFTER INSERT ON ledger_entries
+			DEFERRABLE INITIALLY DEFERRED
+			FOR EACH ROW EXECUTE FUNCTION check_ledger_balance();
+	`).Error
+}
@@ -0,0 +1,94 @@
+package database
+
+import (
+	"log"
+
+	"gorm.io/gorm"
+)
+
+// repairWallets moves wallets created before owner typing onto owner_type and
+// owner_id and drops the old unique index on vendor_id, which allowed only
+// one client wallet to exist. It only touches rows without an owner, so it is
+// safe to run on every start. A trigger keeps rows inserted by other services
+// owned as well.
+func repairWallets(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`DROP INDEX IF EXISTS idx_wallets_vendor_id`).Error; err != nil {
+			return err
+		}
+
+		err := tx.Exec(`
+			UPDATE wallets
+			SET owner_type = 'vendor', owner_id = vendor_id
+			WHERE owner_id IS NULL
+				AND vendor_id IS NOT NULL
+				AND vendor_id <> '00000000-0000-0000-0000-000000000000'
+		`).Error
+		if err != nil {
+			return err
+		}
+
+		// client_id was never indexed, so keep only the oldest wallet per
+		// client. Duplicates stay unowned here; the repository's
+		// MergeDuplicateClientWallets moves their balances into the kept
+		// wallet once the ledger is available.
+		err = tx.Exec(`
+			UPDATE wallets w
+			SET owner_type = 'client', owner_id = w.client_id
+			FROM (
+				SELECT DISTINCT ON (client_id) id
+				FROM wallets
+				WHERE owner_id IS NULL
+					AND client_id IS NOT NULL
+					AND client_id <> '00000000-0000-0000-0000-000000000000'
+				ORDER BY client_id, created_at
+			) oldest
+			WHERE w.id = oldest.id
+				AND NOT EXISTS (
+					SELECT 1 FROM wallets o
+					WHERE o.owner_type = 'client' AND o.owner_id = w.client_id
+				)
+		`).Error
+		if err != nil {
+			return err
+		}
+
+		// The client service still creates wallets by client_id alone, so
+		// fill in the owner for any row inserted without one. Together with
+		// idx_wallets_owner this means a second wallet for the same client
+		// now fails with a unique violation instead of being created.
+		err = tx.Exec(`
+			CREATE OR REPLACE FUNCTION fill_wallet_owner() RETURNS trigger AS $$
+			BEGIN
+				IF NEW.owner_id IS NULL THEN
+					IF NEW.vendor_id IS NOT NULL AND NEW.vendor_id <> '00000000-0000-0000-0000-000000000000' THEN
+						NEW.owner_type := 'vendor';
+						NEW.owner_id := NEW.vendor_id;
+					ELSIF NEW.client_id IS NOT NULL AND NEW.client_id <> '00000000-0000-0000-0000-000000000000' THEN
+						NEW.owner_type := 'client';
+						NEW.owner_id := NEW.client_id;
+					END IF;
+				END IF;
+				RETURN NEW;
+			END;
+			$$ LANGUAGE plpgsql;
+
+			DROP TRIGGER IF EXISTS wallets_fill_owner ON wallets;
+			CREATE TRIGGER wallets_fill_owner
+				BEFORE INSERT ON wallets
+				FOR EACH ROW EXECUTE FUNCTION fill_wallet_owner();
+		`).Error
+		if err != nil {
+			return err
+		}
+
+		var unowned int64
+		if err := tx.Table("wallets").Where("owner_id IS NULL").Count(&unowned).Error; err != nil {
+			return err
+		}
+		if unowned > 0 {
+			log.Printf("%d wallets have no owner after repair and need manual review", unowned)
+		}
+		return nil
+	})
+}
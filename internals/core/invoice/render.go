@@ -0,0 +1,296 @@
+// Package invoice renders stored invoices as HTML or PDF for download.
+package invoice
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"strings"
+
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/models"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/money"
+	"github.com/AthulKrishna2501/zyra-vendor-service/utils"
+)
+
+const (
+	FormatHTML = "html"
+	FormatPDF  = "pdf"
+)
+
+var ErrUnknownFormat = errors.New("invoice format must be html or pdf")
+
+// Render returns the invoice in format along with its content type.
+func Render(inv *models.Invoice, format string) ([]byte, string, error) {
+	switch format {
+	case FormatHTML:
+		content, err := renderHTML(inv)
+		return content, "text/html; charset=utf-8", err
+	case FormatPDF:
+		return renderPDF(inv), "application/pdf", nil
+	default:
+		return nil, "", ErrUnknownFormat
+	}
+}
+
+func FileName(inv *models.Invoice, format string) string {
+	return fmt.Sprintf("%s.%s", inv.Number, format)
+}
+
+func Title(inv *models.Invoice) string {
+	switch inv.Kind {
+	case models.InvoiceKindPayout:
+		return "Payout Invoice"
+	case models.InvoiceKindCreditNote:
+		return "Credit Note"
+	default:
+		return "Receipt"
+	}
+}
+
+// subtotal is the total before the tax it includes.
+func subtotal(inv *models.Invoice) money.Money {
+	amount, err := inv.Total.Sub(inv.TaxTotal)
+	if err != nil {
+		return inv.Total
+	}
+	return amount
+}
+
+var htmlTemplate = template.Must(template.New("invoice").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}} {{.Invoice.Number}}</title>
+<style>
+body { font-family: Helvetica, Arial, sans-serif; color: #222; margin: 40px; }
+h1 { margin-bottom: 4px; }
+.parties { display: flex; justify-content: space-between; margin: 24px 0; }
+table { width: 100%; border-collapse: collapse; }
+th, td { padding: 6px 8px; border-bottom: 1px solid #ddd; text-align: left; }
+td.num, th.num { text-align: right; }
+tr.tax td { color: #666; font-style: italic; }
+tfoot td { font-weight: bold; border-bottom: none; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<div>{{.Invoice.Number}} &middot; Issued {{.Invoice.IssuedAt.Format "02 Jan 2006"}}</div>
+<div class="parties">
+<div>
+<strong>{{.Invoice.SellerName}}</strong><br>
+{{if .Invoice.SellerAddress}}{{.Invoice.SellerAddress}}<br>{{end}}
+{{if .Invoice.SellerTaxID}}Tax ID: {{.Invoice.SellerTaxID}}<br>{{end}}
+{{if .Invoice.SellerEmail}}{{.Invoice.SellerEmail}}<br>{{end}}
+{{if .Invoice.SellerPhone}}{{.Invoice.SellerPhone}}{{end}}
+</div>
+{{if .Invoice.BuyerName}}<div>
+<strong>Billed to</strong><br>
+{{.Invoice.BuyerName}}<br>
+{{if .Invoice.BuyerEmail}}{{.Invoice.BuyerEmail}}{{end}}
+</div>{{end}}
+</div>
+<table>
+<thead>
+<tr><th>Description</th><th class="num">Qty</th><th class="num">Unit price</th><th class="num">Amount</th></tr>
+</thead>
+<tbody>
+{{range .Invoice.Lines}}<tr{{if eq .Kind "tax"}} class="tax"{{end}}>
+<td>{{.Description}}</td>
+<td class="num">{{if ne .Kind "tax"}}{{.Quantity}}{{end}}</td>
+<td class="num">{{if ne .Kind "tax"}}{{.UnitPrice}}{{end}}</td>
+<td class="num">{{.Amount}}</td>
+</tr>
+{{end}}</tbody>
+<tfoot>
+{{if not .Invoice.TaxTotal.IsZero}}<tr><td colspan="3" class="num">Subtotal</td><td class="num">{{.Subtotal}}</td></tr>
+<tr><td colspan="3" class="num">Tax</td><td class="num">{{.Invoice.TaxTotal}}</td></tr>
+{{end}}<tr><td colspan="3" class="num">Total</td><td class="num">{{.Invoice.Total}}</td></tr>
+</tfoot>
+</table>
+</body>
+</html>
+`))
+
+func renderHTML(inv *models.Invoice) ([]byte, error) {
+	var b bytes.Buffer
+	err := htmlTemplate.Execute(&b, struct {
+		Title    string
+		Invoice  *models.Invoice
+		Subtotal money.Money
+	}{
+		Title:    Title(inv),
+		Invoice:  inv,
+		Subtotal: subtotal(inv),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+const (
+	pdfMargin     = 50.0
+	pdfLineHeight = 16.0
+	pdfBodySize   = 10.0
+
+	// Columns of the line item table, and how many characters of a
+	// description fit before the quantity column.
+	pdfQuantityX     = 330.0
+	pdfUnitPriceX    = 380.0
+	pdfAmountX       = 470.0
+	pdfDescriptionAt = 52
+)
+
+// pdfWriter lays text out top to bottom, starting a new page when the
+// current one is full.
+type pdfWriter struct {
+	pages [][]utils.PDFText
+	y     float64
+}
+
+func (w *pdfWriter) newPage() {
+	w.pages = append(w.pages, nil)
+	w.y = utils.PDFPageHeight - pdfMargin
+}
+
+// line writes one row of text. Rows with nothing in them take no space.
+func (w *pdfWriter) line(size float64, bold bool, columns ...pdfColumn) {
+	empty := true
+	for _, column := range columns {
+		if column.text != "" {
+			empty = false
+		}
+	}
+	if empty {
+		return
+	}
+
+	if len(w.pages) == 0 || w.y < pdfMargin+pdfLineHeight {
+		w.newPage()
+	}
+
+	page := len(w.pages) - 1
+	for _, column := range columns {
+		if column.text == "" {
+			continue
+		}
+		w.pages[page] = append(w.pages[page], utils.PDFText{
+			X:    column.x,
+			Y:    w.y,
+			Size: size,
+			Bold: bold,
+			Text: column.text,
+		})
+	}
+	w.y -= size + (pdfLineHeight - pdfBodySize)
+}
+
+func (w *pdfWriter) gap() {
+	w.y -= pdfLineHeight / 2
+}
+
+type pdfColumn struct {
+	x    float64
+	text string
+}
+
+func at(x float64, text string) pdfColumn {
+	return pdfColumn{x: x, text: text}
+}
+
+func renderPDF(inv *models.Invoice) []byte {
+	var w pdfWriter
+
+	w.line(20, true, at(pdfMargin, Title(inv)))
+	w.line(pdfBodySize, false, at(pdfMargin, fmt.Sprintf("%s - Issued %s", inv.Number, inv.IssuedAt.Format("02 Jan 2006"))))
+	w.gap()
+
+	w.line(pdfBodySize, true, at(pdfMargin, inv.SellerName))
+	for _, detail := range []string{inv.SellerAddress, taxIDLine(inv.SellerTaxID), inv.SellerEmail, inv.SellerPhone} {
+		for _, text := range strings.Split(detail, "\n") {
+			w.line(pdfBodySize, false, at(pdfMargin, strings.TrimSpace(text)))
+		}
+	}
+
+	if inv.BuyerName != "" {
+		w.gap()
+		w.line(pdfBodySize, true, at(pdfMargin, "Billed to"))
+		w.line(pdfBodySize, false, at(pdfMargin, inv.BuyerName))
+		w.line(pdfBodySize, false, at(pdfMargin, inv.BuyerEmail))
+	}
+
+	w.gap()
+	w.line(pdfBodySize, true,
+		at(pdfMargin, "Description"),
+		at(pdfQuantityX, "Qty"),
+		at(pdfUnitPriceX, "Unit price"),
+		at(pdfAmountX, "Amount"),
+	)
+
+	for _, line := range inv.Lines {
+		description := wrap(line.Description, pdfDescriptionAt)
+		quantity, unitPrice := fmt.Sprint(line.Quantity), line.UnitPrice.String()
+		if line.Kind == models.InvoiceLineTax {
+			quantity, unitPrice = "", ""
+		}
+
+		w.line(pdfBodySize, false,
+			at(pdfMargin, description[0]),
+			at(pdfQuantityX, quantity),
+			at(pdfUnitPriceX, unitPrice),
+			at(pdfAmountX, line.Amount.String()),
+		)
+		for _, rest := range description[1:] {
+			w.line(pdfBodySize, false, at(pdfMargin, rest))
+		}
+	}
+
+	w.gap()
+	if !inv.TaxTotal.IsZero() {
+		w.line(pdfBodySize, false, at(pdfUnitPriceX, "Subtotal"), at(pdfAmountX, subtotal(inv).String()))
+		w.line(pdfBodySize, false, at(pdfUnitPriceX, "Tax"), at(pdfAmountX, inv.TaxTotal.String()))
+	}
+	w.line(pdfBodySize, true, at(pdfUnitPriceX, "Total"), at(pdfAmountX, inv.Total.String()))
+
+	return utils.BuildPDF(w.pages)
+}
+
+func taxIDLine(taxID string) string {
+	if taxID == "" {
+		return ""
+	}
+	return "Tax ID: " + taxID
+}
+
+// wrap splits text into lines of at most width characters, breaking between
+// words where it can.
+func wrap(text string, width int) []string {
+	var lines []string
+	current := ""
+	for _, word := range strings.Fields(text) {
+		for len([]rune(word)) > width {
+			if current != "" {
+				lines = append(lines, current)
+				current = ""
+			}
+			runes := []rune(word)
+			lines = append(lines, string(runes[:width]))
+			word = string(runes[width:])
+		}
+
+		switch {
+		case current == "":
+			current = word
+		case len([]rune(current))+1+len([]rune(word)) <= width:
+			current += " " + word
+		default:
+			lines = append(lines, current)
+			current = word
+		}
+	}
+	if current != "" || len(lines) == 0 {
+		lines = append(lines, current)
+	}
+	return lines
+}
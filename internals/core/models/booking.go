@@ -0,0 +1,192 @@
+package models
+
+import (
+	"time"
+
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/money"
+	"github.com/google/uuid"
+)
+
+// BookingCompletion tracks a completed booking's payout. SettlementAttempts
+// and SettlementError record the settlement runs that had to leave the
+// booking out; each run tries it again until it is settled.
+type BookingCompletion struct {
+	ID                  uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	BookingID           uuid.UUID `json:"booking_id" gorm:"type:uuid;not null;uniqueIndex"`
+	VendorID            uuid.UUID `json:"vendor_id" gorm:"type:uuid;not null;index"`
+	CompletedAt         time.Time `json:"completed_at" gorm:"type:timestamptz;not null"`
+	DisputeWindowEndsAt time.Time `json:"dispute_window_ends_at" gorm:"type:timestamptz;not null;index"`
+	PayoutStatus        string    `json:"payout_status" gorm:"type:varchar(20);not null;default:'awaiting_release';index"`
+	SettlementAttempts  int       `json:"settlement_attempts" gorm:"not null;default:0"`
+	SettlementError     string    `json:"settlement_error" gorm:"type:text"`
+	CreatedAt           time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt           time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+type BookingDispute struct {
+	ID           uuid.UUID   `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	BookingID    uuid.UUID   `json:"booking_id" gorm:"type:uuid;not null;index"`
+	ClientID     uuid.UUID   `json:"client_id" gorm:"type:uuid;not null"`
+	Reason       string      `json:"reason" gorm:"type:text;not null"`
+	Status       string      `json:"status" gorm:"type:varchar(20);not null;default:'open'"`
+	Resolution   string      `json:"resolution" gorm:"type:varchar(20)"`
+	RefundAmount money.Money `json:"refund_amount" gorm:"type:bigint;default:0"`
+	ResolvedBy   *uuid.UUID  `json:"resolved_by" gorm:"type:uuid"`
+	ResolvedAt   *time.Time  `json:"resolved_at" gorm:"type:timestamptz"`
+	CreatedAt    time.Time   `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt    time.Time   `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+type BookingMessage struct {
+	ID          uuid.UUID           `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	BookingID   uuid.UUID           `json:"booking_id" gorm:"type:uuid;not null;index"`
+	SenderID    uuid.UUID           `json:"sender_id" gorm:"type:uuid;not null"`
+	SenderRole  string              `json:"sender_role" gorm:"type:varchar(10);not null"`
+	Body        string              `json:"body" gorm:"type:text"`
+	ReadAt      *time.Time          `json:"read_at" gorm:"type:timestamptz"`
+	CreatedAt   time.Time           `json:"created_at" gorm:"autoCreateTime;index"`
+	Attachments []MessageAttachment `json:"attachments" gorm:"foreignKey:MessageID;constraint:OnDelete:CASCADE"`
+}
+
+type MessageAttachment struct {
+	ID          uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	MessageID   uuid.UUID `json:"message_id" gorm:"type:uuid;not null;index"`
+	FileName    string    `json:"file_name" gorm:"type:varchar(255);not null"`
+	FileURL     string    `json:"file_url" gorm:"type:text;not null"`
+	ContentType string    `json:"content_type" gorm:"type:varchar(100)"`
+	SizeBytes   int64     `json:"size_bytes"`
+	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// BookingEvent is an append-only record of a change made to a booking.
+type BookingEvent struct {
+	ID         uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	BookingID  uuid.UUID `json:"booking_id" gorm:"type:uuid;not null;index"`
+	ActorID    uuid.UUID `json:"actor_id" gorm:"type:uuid"`
+	ActorRole  string    `json:"actor_role" gorm:"type:varchar(10);not null"`
+	Action     string    `json:"action" gorm:"type:varchar(50);not null"`
+	OldStatus  string    `json:"old_status" gorm:"type:varchar(20)"`
+	NewStatus  string    `json:"new_status" gorm:"type:varchar(20)"`
+	ReasonCode string    `json:"reason_code" gorm:"type:varchar(50)"`
+	Reason     string    `json:"reason" gorm:"type:text"`
+	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime;index"`
+}
+
+var RejectionReasons = map[string]string{
+	"date_unavailable":     "Date is no longer available",
+	"outside_service_area": "Event location is outside the service area",
+	"insufficient_details": "Not enough event details provided",
+	"service_mismatch":     "Request does not match the service offered",
+	"client_conduct":       "Concerns about client conduct",
+	"booking_window":       "Outside the service's notice or advance booking window",
+	"other":                "Other",
+}
+
+// BookingInstallment is a booking's share of one payment milestone. Status is
+// pending, paid or refunded; paid installments stay held until ReleasedAt.
+type BookingInstallment struct {
+	ID         uuid.UUID   `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	BookingID  uuid.UUID   `json:"booking_id" gorm:"type:uuid;not null;uniqueIndex:idx_booking_installments_sequence"`
+	Sequence   int         `json:"sequence" gorm:"not null;uniqueIndex:idx_booking_installments_sequence"`
+	Label      string      `json:"label" gorm:"type:varchar(100);not null"`
+	Amount     money.Money `json:"amount" gorm:"type:bigint;not null"`
+	DueAt      time.Time   `json:"due_at" gorm:"type:timestamptz;not null"`
+	ReleaseOn  string      `json:"release_on" gorm:"type:varchar(20);not null"`
+	Status     string      `json:"status" gorm:"type:varchar(20);not null;default:'pending'"`
+	PaidAt     *time.Time  `json:"paid_at" gorm:"type:timestamptz"`
+	ReleasedAt *time.Time  `json:"released_at" gorm:"type:timestamptz"`
+	CreatedAt  time.Time   `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt  time.Time   `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// OvertimeCharge bills a client for hours an event ran past the service
+// duration, at the service's AdditionalHourPrice. Status is pending,
+// declined, paid or refunded. A paid charge is held in escrow with the rest
+// of the booking's money until the booking is paid out, which sets
+// ReleasedAt. A booking has at most one pending charge.
+type OvertimeCharge struct {
+	ID         uuid.UUID   `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	BookingID  uuid.UUID   `json:"booking_id" gorm:"type:uuid;not null;index;uniqueIndex:idx_overtime_charges_pending,where:status = 'pending'"`
+	VendorID   uuid.UUID   `json:"vendor_id" gorm:"type:uuid;not null"`
+	ClientID   uuid.UUID   `json:"client_id" gorm:"type:uuid;not null"`
+	ExtraHours int         `json:"extra_hours" gorm:"not null"`
+	HourlyRate money.Money `json:"hourly_rate" gorm:"type:bigint;not null"`
+	Amount     money.Money `json:"amount" gorm:"type:bigint;not null"`
+	Note       string      `json:"note" gorm:"type:text"`
+	Status     string      `json:"status" gorm:"type:varchar(20);not null;default:'pending'"`
+	DecidedAt  *time.Time  `json:"decided_at" gorm:"type:timestamptz"`
+	ReleasedAt *time.Time  `json:"released_at" gorm:"type:timestamptz"`
+	CreatedAt  time.Time   `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt  time.Time   `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// WaitlistEntry queues a client for a fully booked service date. When a slot
+// frees up the oldest waiting entry for the vendor and day is offered it until
+// OfferExpiresAt. Status is waiting, offered, claimed, expired or left.
+type WaitlistEntry struct {
+	ID             uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	ServiceID      uuid.UUID  `json:"service_id" gorm:"type:uuid;not null;uniqueIndex:idx_waitlist_active_client,where:status IN ('waiting','offered')"`
+	VendorID       uuid.UUID  `json:"vendor_id" gorm:"type:uuid;not null;index:idx_waitlist_vendor_date"`
+	ClientID       uuid.UUID  `json:"client_id" gorm:"type:uuid;not null;uniqueIndex:idx_waitlist_active_client,where:status IN ('waiting','offered')"`
+	Date           time.Time  `json:"date" gorm:"type:timestamptz;not null;index:idx_waitlist_vendor_date;uniqueIndex:idx_waitlist_active_client,where:status IN ('waiting','offered')"`
+	Status         string     `json:"status" gorm:"type:varchar(20);not null;default:'waiting'"`
+	OfferedAt      *time.Time `json:"offered_at" gorm:"type:timestamptz"`
+	OfferExpiresAt *time.Time `json:"offer_expires_at" gorm:"type:timestamptz"`
+	CreatedAt      time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt      time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// BundleBooking groups bookings for several of one vendor's services on the
+// same date. Each item is a regular booking priced after the bundle discount,
+// and the vendor approves or rejects the bundle as a whole.
+type BundleBooking struct {
+	ID              uuid.UUID    `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	ClientID        uuid.UUID    `json:"client_id" gorm:"type:uuid;not null;index"`
+	VendorID        uuid.UUID    `json:"vendor_id" gorm:"type:uuid;not null;index"`
+	Date            time.Time    `json:"date" gorm:"type:timestamptz;not null"`
+	Status          string       `json:"status" gorm:"type:varchar(20);not null;default:'pending'"`
+	Subtotal        money.Money  `json:"subtotal" gorm:"type:bigint;not null"`
+	DiscountPercent int          `json:"discount_percent" gorm:"default:0"`
+	DiscountAmount  money.Money  `json:"discount_amount" gorm:"type:bigint;default:0"`
+	Total           money.Money  `json:"total" gorm:"type:bigint;not null"`
+	CreatedAt       time.Time    `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt       time.Time    `json:"updated_at" gorm:"autoUpdateTime"`
+	Items           []BundleItem `json:"items" gorm:"foreignKey:BundleID;constraint:OnDelete:CASCADE"`
+}
+
+type BundleItem struct {
+	ID           uuid.UUID   `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	BundleID     uuid.UUID   `json:"bundle_id" gorm:"type:uuid;not null;index"`
+	ServiceID    uuid.UUID   `json:"service_id" gorm:"type:uuid;not null"`
+	BookingID    uuid.UUID   `json:"booking_id" gorm:"type:uuid;not null;uniqueIndex"`
+	ServiceTitle string      `json:"service_title" gorm:"type:varchar(255);not null"`
+	ListPrice    money.Money `json:"list_price" gorm:"type:bigint;not null"`
+	Price        money.Money `json:"price" gorm:"type:bigint;not null"`
+}
+
+// BookingSnapshot is a copy of the service's terms as they were when the
+// booking was made, so later edits to the service do not change what the
+// client agreed to.
+type BookingSnapshot struct {
+	BookingID           uuid.UUID   `json:"booking_id" gorm:"type:uuid;primaryKey"`
+	ServiceID           uuid.UUID   `json:"service_id" gorm:"type:uuid;not null;index"`
+	ServiceTitle        string      `json:"service_title" gorm:"type:varchar(255);not null"`
+	YearOfExperience    int         `json:"year_of_experience" gorm:"not null"`
+	AvailableDate       time.Time   `json:"available_date" gorm:"type:timestamptz;not null"`
+	ServiceDescription  string      `json:"service_description" gorm:"type:text;not null"`
+	CancellationPolicy  string      `json:"cancellation_policy" gorm:"type:text"`
+	TermsAndConditions  string      `json:"terms_and_conditions" gorm:"type:text"`
+	ServiceDuration     int         `json:"service_duration" gorm:"not null"`
+	ServicePrice        money.Money `json:"service_price" gorm:"type:bigint;not null"`
+	AdditionalHourPrice money.Money `json:"additional_hour_price" gorm:"type:bigint;default:0"`
+	MinNoticeHours      int         `json:"min_notice_hours" gorm:"default:0"`
+	MaxAdvanceDays      int         `json:"max_advance_days" gorm:"default:0"`
+	CapturedAt          time.Time   `json:"captured_at" gorm:"type:timestamptz;not null"`
+}
+
+// BookingTransaction links a wallet transaction to the booking it paid for,
+// refunded or paid out. A settlement payout is linked to every booking in it.
+type BookingTransaction struct {
+	BookingID     uuid.UUID `json:"booking_id" gorm:"type:uuid;primaryKey"`
+	TransactionID uuid.UUID `json:"transaction_id" gorm:"type:uuid;primaryKey;index"`
+}
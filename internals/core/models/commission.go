@@ -0,0 +1,51 @@
+package models
+
+import (
+	"time"
+
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/money"
+	"github.com/google/uuid"
+)
+
+const (
+	CommissionScopeGlobal   = "global"
+	CommissionScopeCategory = "category"
+	CommissionScopeTier     = "tier"
+)
+
+// CommissionRule is the platform's cut of a vendor payout: RateBasisPoints
+// hundredths of a percent of the payout, plus FixedFee once per booking.
+// ScopeKey is the category ID or tier name the rule applies to, and empty
+// for the global rule. A category rule wins over a tier rule, which wins
+// over the global rule; between category rules the highest rate wins.
+type CommissionRule struct {
+	ID              uuid.UUID   `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	Scope           string      `json:"scope" gorm:"type:varchar(20);not null;uniqueIndex:idx_commission_rules_scope"`
+	ScopeKey        string      `json:"scope_key" gorm:"type:varchar(100);not null;default:'';uniqueIndex:idx_commission_rules_scope"`
+	RateBasisPoints int         `json:"rate_basis_points" gorm:"not null;default:0"`
+	FixedFee        money.Money `json:"fixed_fee" gorm:"type:bigint;not null;default:0"`
+	CreatedAt       time.Time   `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt       time.Time   `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// VendorTier places a vendor in a named commission tier.
+type VendorTier struct {
+	VendorID  uuid.UUID `json:"vendor_id" gorm:"type:uuid;primaryKey"`
+	Tier      string    `json:"tier" gorm:"type:varchar(50);not null"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// PayoutCommission records how one vendor payout was split between the
+// vendor and the platform. TransactionID is the vendor's transaction for the
+// net amount.
+type PayoutCommission struct {
+	ID            uuid.UUID   `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	VendorID      uuid.UUID   `json:"vendor_id" gorm:"type:uuid;not null;index"`
+	TransactionID uuid.UUID   `json:"transaction_id" gorm:"type:uuid;not null;uniqueIndex"`
+	Reference     string      `json:"reference" gorm:"type:varchar(100);index"`
+	RuleID        *uuid.UUID  `json:"rule_id" gorm:"type:uuid"`
+	Gross         money.Money `json:"gross" gorm:"type:bigint;not null"`
+	Fee           money.Money `json:"fee" gorm:"type:bigint;not null"`
+	Net           money.Money `json:"net" gorm:"type:bigint;not null"`
+	CreatedAt     time.Time   `json:"created_at" gorm:"autoCreateTime"`
+}
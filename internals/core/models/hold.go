@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/money"
+	"github.com/google/uuid"
+)
+
+const (
+	// FundHoldEscrow is a client payment held in escrow until the booking is
+	// paid out.
+	FundHoldEscrow = "escrow"
+	// FundHoldDispute is a booking's escrowed payment frozen while a dispute
+	// over it is open.
+	FundHoldDispute = "dispute"
+	// FundHoldWithdrawal is money taken from the wallet for a withdrawal that
+	// has not been paid out yet.
+	FundHoldWithdrawal = "withdrawal"
+)
+
+// FundHold is money owed to a vendor that they cannot spend yet. Each booking,
+// queued payout and withdrawal has at most one open hold; a booking's hold
+// changes kind from escrow to dispute while it is disputed. ReleasedAt is set
+// once the money is paid out, refunded or returned to the wallet, and never
+// cleared: a booking that holds money again later gets a new hold.
+type FundHold struct {
+	ID         uuid.UUID   `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	VendorID   uuid.UUID   `json:"vendor_id" gorm:"type:uuid;not null;index"`
+	Kind       string      `json:"kind" gorm:"type:varchar(20);not null"`
+	SourceType string      `json:"source_type" gorm:"type:varchar(20);not null;uniqueIndex:idx_fund_holds_source,where:released_at IS NULL"`
+	SourceID   string      `json:"source_id" gorm:"type:varchar(100);not null;uniqueIndex:idx_fund_holds_source,where:released_at IS NULL"`
+	Amount     money.Money `json:"amount" gorm:"type:bigint;not null"`
+	ReleasedAt *time.Time  `json:"released_at" gorm:"type:timestamptz;index"`
+	CreatedAt  time.Time   `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt  time.Time   `json:"updated_at" gorm:"autoUpdateTime"`
+}
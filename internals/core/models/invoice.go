@@ -0,0 +1,86 @@
+package models
+
+import (
+	"time"
+
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/money"
+	"github.com/google/uuid"
+)
+
+const (
+	InvoiceKindReceipt    = "receipt"
+	InvoiceKindPayout     = "payout"
+	InvoiceKindCreditNote = "credit_note"
+
+	InvoiceLineItem     = "item"
+	InvoiceLineDiscount = "discount"
+	InvoiceLineFee      = "fee"
+	InvoiceLineTax      = "tax"
+)
+
+// VendorBusinessProfile holds the details printed on a vendor's invoices.
+// TaxRateBasisPoints is the tax included in the vendor's prices, in
+// hundredths of a percent; zero means the vendor does not charge tax.
+type VendorBusinessProfile struct {
+	VendorID           uuid.UUID `json:"vendor_id" gorm:"type:uuid;primaryKey"`
+	BusinessName       string    `json:"business_name" gorm:"type:varchar(255)"`
+	BusinessAddress    string    `json:"business_address" gorm:"type:text"`
+	TaxID              string    `json:"tax_id" gorm:"type:varchar(50)"`
+	TaxName            string    `json:"tax_name" gorm:"type:varchar(50)"`
+	TaxRateBasisPoints int       `json:"tax_rate_basis_points" gorm:"not null;default:0"`
+	UpdatedAt          time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// ClientInvoiceKinds are the invoices a client is given: receipts for what
+// they paid and credit notes for what was refunded.
+var ClientInvoiceKinds = []string{InvoiceKindReceipt, InvoiceKindCreditNote}
+
+// InvoiceSequence hands out a vendor's invoice numbers in order.
+type InvoiceSequence struct {
+	VendorID   uuid.UUID `gorm:"type:uuid;primaryKey"`
+	LastNumber int       `gorm:"not null;default:0"`
+}
+
+// Invoice is a vendor's receipt for a client payment, a credit note for a
+// refund, or the vendor's record of a payout released to them. Seller and buyer details are copied in when
+// the invoice is issued so later profile changes leave it untouched. Source
+// identifies the payment or payout it was issued for, so each is invoiced
+// once. Total is what was paid or paid out, and negative on a credit note;
+// TaxTotal is the part of it that is tax.
+type Invoice struct {
+	ID            uuid.UUID     `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	VendorID      uuid.UUID     `json:"vendor_id" gorm:"type:uuid;not null;uniqueIndex:idx_invoices_vendor_sequence"`
+	Sequence      int           `json:"sequence" gorm:"not null;uniqueIndex:idx_invoices_vendor_sequence"`
+	Number        string        `json:"number" gorm:"type:varchar(30);not null"`
+	Kind          string        `json:"kind" gorm:"type:varchar(20);not null"`
+	SourceType    string        `json:"source_type" gorm:"type:varchar(30);not null;uniqueIndex:idx_invoices_source"`
+	SourceID      string        `json:"source_id" gorm:"type:varchar(100);not null;uniqueIndex:idx_invoices_source"`
+	BookingID     *uuid.UUID    `json:"booking_id" gorm:"type:uuid;index"`
+	ClientID      *uuid.UUID    `json:"client_id" gorm:"type:uuid;index"`
+	AccessToken   string        `json:"-" gorm:"type:varchar(64);not null;uniqueIndex"`
+	SellerName    string        `json:"seller_name" gorm:"type:varchar(255)"`
+	SellerAddress string        `json:"seller_address" gorm:"type:text"`
+	SellerTaxID   string        `json:"seller_tax_id" gorm:"type:varchar(50)"`
+	SellerEmail   string        `json:"seller_email" gorm:"type:varchar(255)"`
+	SellerPhone   string        `json:"seller_phone" gorm:"type:varchar(20)"`
+	BuyerName     string        `json:"buyer_name" gorm:"type:varchar(255)"`
+	BuyerEmail    string        `json:"buyer_email" gorm:"type:varchar(255)"`
+	TaxTotal      money.Money   `json:"tax_total" gorm:"type:bigint;not null;default:0"`
+	Total         money.Money   `json:"total" gorm:"type:bigint;not null"`
+	IssuedAt      time.Time     `json:"issued_at" gorm:"type:timestamptz;not null"`
+	Lines         []InvoiceLine `json:"lines" gorm:"foreignKey:InvoiceID;constraint:OnDelete:CASCADE"`
+}
+
+// InvoiceLine is one row of an invoice. Discount and fee lines carry
+// negative amounts. Tax lines show the tax included in the item lines and are
+// not added to the total.
+type InvoiceLine struct {
+	ID          uuid.UUID   `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	InvoiceID   uuid.UUID   `json:"invoice_id" gorm:"type:uuid;not null;index"`
+	Position    int         `json:"position" gorm:"not null"`
+	Kind        string      `json:"kind" gorm:"type:varchar(20);not null"`
+	Description string      `json:"description" gorm:"type:text;not null"`
+	Quantity    int         `json:"quantity" gorm:"not null;default:1"`
+	UnitPrice   money.Money `json:"unit_price" gorm:"type:bigint;not null"`
+	Amount      money.Money `json:"amount" gorm:"type:bigint;not null"`
+}
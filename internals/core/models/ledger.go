@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/money"
+	"github.com/google/uuid"
+)
+
+// LedgerAccount is one account in the double-entry ledger. Wallet accounts
+// belong to a client or vendor; platform accounts have a nil OwnerID.
+// NormalSide is the side ("debit" or "credit") that increases the balance.
+type LedgerAccount struct {
+	ID         uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	OwnerType  string    `json:"owner_type" gorm:"type:varchar(20);not null;uniqueIndex:idx_ledger_accounts_owner"`
+	OwnerID    uuid.UUID `json:"owner_id" gorm:"type:uuid;not null;uniqueIndex:idx_ledger_accounts_owner"`
+	Code       string    `json:"code" gorm:"type:varchar(50);not null;uniqueIndex:idx_ledger_accounts_owner"`
+	NormalSide string    `json:"normal_side" gorm:"type:varchar(10);not null"`
+	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// LedgerTransaction groups the entries of one money movement. Its debits and
+// credits always add up to the same amount.
+type LedgerTransaction struct {
+	ID          uuid.UUID     `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	Kind        string        `json:"kind" gorm:"type:varchar(50);not null;index"`
+	Reference   string        `json:"reference" gorm:"type:varchar(100);index"`
+	Description string        `json:"description" gorm:"type:text"`
+	CreatedAt   time.Time     `json:"created_at" gorm:"autoCreateTime"`
+	Entries     []LedgerEntry `json:"entries" gorm:"foreignKey:TransactionID"`
+}
+
+type LedgerEntry struct {
+	ID            uuid.UUID   `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	TransactionID uuid.UUID   `json:"transaction_id" gorm:"type:uuid;not null;index"`
+	AccountID     uuid.UUID   `json:"account_id" gorm:"type:uuid;not null;index"`
+	Direction     string      `json:"direction" gorm:"type:varchar(10);not null"`
+	Amount        money.Money `json:"amount" gorm:"type:bigint;not null;check:chk_ledger_entries_amount,amount > 0"`
+	CreatedAt     time.Time   `json:"created_at" gorm:"autoCreateTime"`
+}
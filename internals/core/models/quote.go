@@ -0,0 +1,55 @@
+package models
+
+import (
+	"time"
+
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/money"
+	"github.com/google/uuid"
+)
+
+// Quote is a client's request for a custom price on one of the vendor's
+// services and the vendor's reply to it. Status moves from requested to
+// quoted, then to accepted, declined or expired. A quoted quote past
+// ExpiresAt is expired even before its status is updated; use
+// EffectiveStatus. Accepting a quote creates the booking referenced by
+// BookingID.
+type Quote struct {
+	ID         uuid.UUID       `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	ClientID   uuid.UUID       `json:"client_id" gorm:"type:uuid;not null;index"`
+	VendorID   uuid.UUID       `json:"vendor_id" gorm:"type:uuid;not null;index"`
+	ServiceID  *uuid.UUID      `json:"service_id" gorm:"type:uuid"`
+	Title      string          `json:"title" gorm:"type:varchar(255);not null"`
+	EventDate  time.Time       `json:"event_date" gorm:"type:timestamptz;not null"`
+	Location   string          `json:"location" gorm:"type:varchar(255)"`
+	GuestCount int             `json:"guest_count" gorm:"default:0"`
+	Details    string          `json:"details" gorm:"type:text;not null"`
+	Status     string          `json:"status" gorm:"type:varchar(20);not null;default:'requested';index"`
+	TotalPrice money.Money     `json:"total_price" gorm:"type:bigint;default:0"`
+	VendorNote string          `json:"vendor_note" gorm:"type:text"`
+	ExpiresAt  *time.Time      `json:"expires_at" gorm:"type:timestamptz"`
+	QuotedAt   *time.Time      `json:"quoted_at" gorm:"type:timestamptz"`
+	BookingID  *uuid.UUID      `json:"booking_id" gorm:"type:uuid"`
+	DeclinedBy string          `json:"declined_by" gorm:"type:varchar(20)"`
+	Reason     string          `json:"reason" gorm:"type:text"`
+	CreatedAt  time.Time       `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt  time.Time       `json:"updated_at" gorm:"autoUpdateTime"`
+	LineItems  []QuoteLineItem `json:"line_items" gorm:"foreignKey:QuoteID;constraint:OnDelete:CASCADE"`
+}
+
+// EffectiveStatus is the quote's status as of now.
+func (q *Quote) EffectiveStatus(now time.Time) string {
+	if q.Status == "quoted" && q.ExpiresAt != nil && q.ExpiresAt.Before(now) {
+		return "expired"
+	}
+	return q.Status
+}
+
+type QuoteLineItem struct {
+	ID          uuid.UUID   `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	QuoteID     uuid.UUID   `json:"quote_id" gorm:"type:uuid;not null;index"`
+	Position    int         `json:"position" gorm:"not null"`
+	Description string      `json:"description" gorm:"type:varchar(255);not null"`
+	Quantity    int         `json:"quantity" gorm:"not null"`
+	UnitPrice   money.Money `json:"unit_price" gorm:"type:bigint;not null"`
+	Amount      money.Money `json:"amount" gorm:"type:bigint;not null"`
+}
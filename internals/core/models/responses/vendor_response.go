@@ -1,6 +1,11 @@
 package responses
 
-import "time"
+import (
+	"time"
+
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/money"
+	"github.com/google/uuid"
+)
 
 type VendorProfileResponse struct {
 	UserID        string `json:"vendor_id" gorm:"column:user_id"`
@@ -32,3 +37,96 @@ type ServiceStat struct {
 	Service   string
 	TotalBookings int32
 }
+
+type BookingDetail struct {
+	BookingID        string
+	ClientID         string
+	VendorID         string
+	ClientFirstName  string
+	ClientLastName   string
+	ClientEmail      string
+	ClientPhone      string
+	Service          string
+	Date             time.Time
+	Price            int
+	Status           string
+	IsVendorApproved bool
+	IsClientApproved bool
+	IsFundReleased   bool
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+}
+
+type TimelineEvent struct {
+	Event       string
+	Description string
+	Amount      money.Money
+	Status      string
+	OccurredAt  time.Time
+}
+
+type UnreadCount struct {
+	BookingID string
+	Unread    int64
+}
+
+type CalendarBooking struct {
+	BookingID       string
+	ClientName      string
+	Service         string
+	Date            time.Time
+	Status          string
+	ServiceDuration int
+	UpdatedAt       time.Time
+}
+
+type BlockedClient struct {
+	VendorID        string    `json:"vendor_id"`
+	ClientID        string    `json:"client_id"`
+	ClientFirstName string    `json:"client_first_name"`
+	ClientLastName  string    `json:"client_last_name"`
+	ClientEmail     string    `json:"client_email"`
+	Reason          string    `json:"reason"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+type LedgerStatementLine struct {
+	TransactionID string      `json:"transaction_id"`
+	Kind          string      `json:"kind"`
+	Reference     string      `json:"reference"`
+	Description   string      `json:"description"`
+	Direction     string      `json:"direction"`
+	Amount        money.Money `json:"amount"`
+	CreatedAt     time.Time   `json:"created_at"`
+}
+
+type WalletDiscrepancy struct {
+	WalletID      string      `json:"wallet_id"`
+	OwnerType     string      `json:"owner_type"`
+	OwnerID       string      `json:"owner_id"`
+	WalletBalance money.Money `json:"wallet_balance"`
+	LedgerBalance money.Money `json:"ledger_balance"`
+}
+
+// VendorTransactionLine is one of a vendor's transactions. AmountPaid is in
+// whole units, as stored; RunningBalance is the vendor's wallet balance, taken
+// from the ledger, once this transaction has been made.
+type VendorTransactionLine struct {
+	TransactionID  uuid.UUID   `json:"transaction_id"`
+	Purpose        string      `json:"purpose"`
+	AmountPaid     int64       `json:"amount_paid"`
+	PaymentStatus  string      `json:"payment_status"`
+	DateOfPayment  time.Time   `json:"date_of_payment"`
+	RunningBalance money.Money `json:"running_balance"`
+}
+
+// TreasuryBalance is one of the platform's treasury accounts: its admin
+// wallet and the balance of the ledger account that mirrors it.
+type TreasuryBalance struct {
+	Role             string      `json:"role"`
+	Email            string      `json:"email"`
+	WalletBalance    money.Money `json:"wallet_balance"`
+	TotalDeposits    money.Money `json:"total_deposits"`
+	TotalWithdrawals money.Money `json:"total_withdrawals"`
+	LedgerBalance    money.Money `json:"ledger_balance"`
+}
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"time"
+
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/money"
+	"github.com/google/uuid"
+)
+
+// Settlement is one payout to a vendor covering what became releasable for
+// them during a settlement period. A vendor normally has one per period; a
+// booking left out of it because it could not be read is paid in a further
+// settlement for the same period once it can. It is only stored once it has
+// been paid.
+type Settlement struct {
+	ID            uuid.UUID        `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	VendorID      uuid.UUID        `json:"vendor_id" gorm:"type:uuid;not null;index:idx_settlements_vendor_period"`
+	PeriodStart   time.Time        `json:"period_start" gorm:"type:timestamptz;not null;index:idx_settlements_vendor_period"`
+	PeriodEnd     time.Time        `json:"period_end" gorm:"type:timestamptz;not null"`
+	Gross         money.Money      `json:"gross" gorm:"type:bigint;not null"`
+	Fee           money.Money      `json:"fee" gorm:"type:bigint;not null"`
+	Net           money.Money      `json:"net" gorm:"type:bigint;not null"`
+	TransactionID *uuid.UUID       `json:"transaction_id" gorm:"type:uuid"`
+	PaidAt        time.Time        `json:"paid_at" gorm:"type:timestamptz;not null"`
+	CreatedAt     time.Time        `json:"created_at" gorm:"autoCreateTime"`
+	Lines         []SettlementLine `json:"lines" gorm:"foreignKey:SettlementID;constraint:OnDelete:CASCADE"`
+}
+
+// SettlementLine is one booking's share of a settlement. A booking can have
+// more than one: the installments released when it was approved, and the
+// rest once it is completed.
+type SettlementLine struct {
+	ID           uuid.UUID   `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	SettlementID uuid.UUID   `json:"settlement_id" gorm:"type:uuid;not null;index"`
+	BookingID    uuid.UUID   `json:"booking_id" gorm:"type:uuid;not null;index"`
+	Description  string      `json:"description" gorm:"type:text;not null"`
+	Gross        money.Money `json:"gross" gorm:"type:bigint;not null"`
+	Fee          money.Money `json:"fee" gorm:"type:bigint;not null"`
+	Net          money.Money `json:"net" gorm:"type:bigint;not null"`
+}
+
+const (
+	// QueuedPayoutApproval is a booking's installments released when the
+	// vendor approved it.
+	QueuedPayoutApproval = "approval"
+	// QueuedPayoutDispute is what is left for the vendor of a disputed
+	// booking once the dispute is resolved.
+	QueuedPayoutDispute = "dispute"
+)
+
+// QueuedPayout is money released to a vendor for a booking outside its
+// completion, waiting to be paid in the vendor's next settlement. The money
+// stays in escrow until then; SettlementID is set by the settlement that pays
+// it.
+type QueuedPayout struct {
+	ID           uuid.UUID   `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	VendorID     uuid.UUID   `json:"vendor_id" gorm:"type:uuid;not null;index"`
+	BookingID    uuid.UUID   `json:"booking_id" gorm:"type:uuid;not null;index"`
+	Kind         string      `json:"kind" gorm:"type:varchar(20);not null"`
+	Description  string      `json:"description" gorm:"type:text;not null"`
+	Amount       money.Money `json:"amount" gorm:"type:bigint;not null"`
+	SettlementID *uuid.UUID  `json:"settlement_id" gorm:"type:uuid;index"`
+	CreatedAt    time.Time   `json:"created_at" gorm:"autoCreateTime"`
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	adminModel "github.com/AthulKrishna2501/zyra-admin-service/internals/core/models"
+	clientModel "github.com/AthulKrishna2501/zyra-client-service/internals/core/models"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/money"
+)
+
+// The wallet, booking and transaction tables are shared with the other
+// services and keep amounts in whole major units as plain integers, and the
+// admin wallet keeps them as floats. Read them through these helpers rather
+// than converting at each call site.
+
+// Balance returns the wallet's balance.
+func (w *Wallet) Balance() (money.Money, error) {
+	return money.FromMajor(w.WalletBalance)
+}
+
+// BookingPrice returns the price the client agreed to for booking.
+func BookingPrice(booking *adminModel.Booking) (money.Money, error) {
+	return money.FromMajor(int64(booking.Price))
+}
+
+// TransactionAmount returns the amount a client transaction moved.
+func TransactionAmount(transaction *clientModel.Transaction) (money.Money, error) {
+	return money.FromMajor(int64(transaction.AmountPaid))
+}
+
+// AdminWalletBalance returns the balance of an admin wallet.
+func AdminWalletBalance(wallet *adminModel.AdminWallet) (money.Money, error) {
+	return money.FromMajorFloat(wallet.Balance)
+}
@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/AthulKrishna2501/zyra-auth-service/internals/core/models"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/money"
 	"github.com/google/uuid"
 )
 
@@ -25,22 +26,95 @@ type CategoryRequest struct {
 }
 
 type Service struct {
-	ID                  uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
-	VendorID            uuid.UUID `json:"vendor_id" gorm:"type:uuid;not null;index"`
-	ServiceTitle        string    `json:"service_title" gorm:"type:varchar(255);not null"`
-	YearOfExperience    int       `json:"year_of_experience" gorm:"not null"`
-	AvailableDate       time.Time `json:"available_date" gorm:"type:timestamptz;not null"`
-	ServiceDescription  string    `json:"service_description" gorm:"type:text;not null"`
-	CancellationPolicy  string    `json:"cancellation_policy" gorm:"type:text"`
-	TermsAndConditions  string    `json:"terms_and_conditions" gorm:"type:text"`
-	ServiceDuration     int       `json:"service_duration" gorm:"not null"`
-	ServicePrice        int       `json:"service_price" gorm:"not null"`
-	AdditionalHourPrice int       `json:"additional_hour_price" gorm:""`
+	ID                  uuid.UUID   `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	VendorID            uuid.UUID   `json:"vendor_id" gorm:"type:uuid;not null;index"`
+	ServiceTitle        string      `json:"service_title" gorm:"type:varchar(255);not null"`
+	YearOfExperience    int         `json:"year_of_experience" gorm:"not null"`
+	AvailableDate       time.Time   `json:"available_date" gorm:"type:timestamptz;not null"`
+	ServiceDescription  string      `json:"service_description" gorm:"type:text;not null"`
+	CancellationPolicy  string      `json:"cancellation_policy" gorm:"type:text"`
+	TermsAndConditions  string      `json:"terms_and_conditions" gorm:"type:text"`
+	ServiceDuration     int         `json:"service_duration" gorm:"not null"`
+	ServicePrice        money.Money `json:"service_price" gorm:"column:service_price_minor;type:bigint;not null;default:0"`
+	AdditionalHourPrice money.Money `json:"additional_hour_price" gorm:"column:additional_hour_price_minor;type:bigint;not null;default:0"`
+	MinNoticeHours      int         `json:"min_notice_hours" gorm:"default:0"`
+	MaxAdvanceDays      int         `json:"max_advance_days" gorm:"default:0"`
+
+	// The client service reads service_price and additional_hour_price in
+	// whole units; SetSharedPrices keeps them in step with the prices above.
+	SharedServicePrice        int `json:"-" gorm:"column:service_price;not null"`
+	SharedAdditionalHourPrice int `json:"-" gorm:"column:additional_hour_price;default:0"`
 
 	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
 
-	Vendor models.User `gorm:"foreignKey:VendorID;references:UserID;constraint:OnDelete:CASCADE"`
+	Vendor              models.User          `gorm:"foreignKey:VendorID;references:UserID;constraint:OnDelete:CASCADE"`
+	InstantBookingRules []InstantBookingRule `json:"instant_booking_rules" gorm:"foreignKey:ServiceID;constraint:OnDelete:CASCADE"`
+	PaymentMilestones   []PaymentMilestone   `json:"payment_milestones" gorm:"foreignKey:ServiceID;constraint:OnDelete:CASCADE"`
+}
+
+// SetSharedPrices copies ServicePrice and AdditionalHourPrice into the
+// whole-unit columns shared with the client service. Service prices are
+// whole amounts, so this fails only on corrupt input.
+func (s *Service) SetSharedPrices() error {
+	servicePrice, err := s.ServicePrice.Major()
+	if err != nil {
+		return err
+	}
+	additionalHourPrice, err := s.AdditionalHourPrice.Major()
+	if err != nil {
+		return err
+	}
+	s.SharedServicePrice = int(servicePrice)
+	s.SharedAdditionalHourPrice = int(additionalHourPrice)
+	return nil
+}
+
+// PaymentMilestone is one installment of a service's payment schedule.
+// DueDaysBefore is counted back from the event date; a negative value means
+// the installment is collected when the booking is made. ReleaseOn is either
+// "approval" or "completion".
+type PaymentMilestone struct {
+	ID            uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	ServiceID     uuid.UUID `json:"service_id" gorm:"type:uuid;not null;index"`
+	Sequence      int       `json:"sequence" gorm:"not null"`
+	Label         string    `json:"label" gorm:"type:varchar(100);not null"`
+	Percent       int       `json:"percent" gorm:"not null"`
+	DueDaysBefore int       `json:"due_days_before" gorm:"not null;default:-1"`
+	ReleaseOn     string    `json:"release_on" gorm:"type:varchar(20);not null;default:'completion'"`
+	CreatedAt     time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// InstantBookingRule approves a new booking for its service without vendor
+// action when every configured condition holds.
+type InstantBookingRule struct {
+	ID              uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	ServiceID       uuid.UUID `json:"service_id" gorm:"type:uuid;not null;index"`
+	Enabled         bool      `json:"enabled" gorm:"default:true"`
+	MinDaysAhead    int       `json:"min_days_ahead" gorm:"default:0"`
+	MaxDaysAhead    int       `json:"max_days_ahead" gorm:"default:0"`
+	RequireOpenDate bool      `json:"require_open_date" gorm:"default:false"`
+	CreatedAt       time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt       time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// BundleDiscount takes Percent off a bundle booking of at least MinServices
+// of the vendor's services.
+type BundleDiscount struct {
+	ID          uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	VendorID    uuid.UUID `json:"vendor_id" gorm:"type:uuid;not null;index"`
+	MinServices int       `json:"min_services" gorm:"not null"`
+	Percent     int       `json:"percent" gorm:"not null"`
+	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// ClientBlock stops a client from booking or requesting quotes from a vendor.
+type ClientBlock struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	VendorID  uuid.UUID `json:"vendor_id" gorm:"type:uuid;not null;uniqueIndex:idx_client_blocks_pair"`
+	ClientID  uuid.UUID `json:"client_id" gorm:"type:uuid;not null;uniqueIndex:idx_client_blocks_pair"`
+	Reason    string    `json:"reason" gorm:"type:text;not null"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
 }
 
 type VendorCategory struct {
@@ -51,13 +125,55 @@ type VendorCategory struct {
 	UpdatedAt  time.Time `json:"updated_at" gorm:"autoUpdateTime"`
 }
 
+const (
+	WalletOwnerClient = "client"
+	WalletOwnerVendor = "vendor"
+)
+
+// Wallet belongs to exactly one client or vendor, identified by OwnerType and
+// OwnerID. VendorID and ClientID are kept filled in for services that still
+// read them but are no longer unique or used for lookups here. The client
+// service writes this table too, so balances stay in whole major units;
+// convert them with money.FromMajor.
 type Wallet struct {
 	ID               uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
-	VendorID         uuid.UUID `gorm:"type:uuid;uniqueIndex"`
-	ClientID         uuid.UUID `gorn:"type:uuid;uniqueIndex"`
+	OwnerType        string    `gorm:"type:varchar(10);uniqueIndex:idx_wallets_owner"`
+	OwnerID          uuid.UUID `gorm:"type:uuid;uniqueIndex:idx_wallets_owner"`
+	VendorID         uuid.UUID `gorm:"type:uuid"`
+	ClientID         uuid.UUID `gorm:"type:uuid"`
 	WalletBalance    int64     `gorm:"default:0"`
 	TotalDeposits    int64     `gorm:"default:0"`
 	TotalWithdrawals int64     `gorm:"default:0"`
 	CreatedAt        time.Time
 	UpdatedAt        time.Time
 }
+
+type CalendarFeed struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	VendorID  uuid.UUID `json:"vendor_id" gorm:"type:uuid;not null;uniqueIndex"`
+	Token     string    `json:"-" gorm:"type:varchar(64);not null;uniqueIndex"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+type ExternalCalendar struct {
+	ID            uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	VendorID      uuid.UUID  `json:"vendor_id" gorm:"type:uuid;not null;index"`
+	Name          string     `json:"name" gorm:"type:varchar(255);not null"`
+	SourceURL     string     `json:"source_url" gorm:"type:text"`
+	LastSyncedAt  *time.Time `json:"last_synced_at" gorm:"type:timestamptz"`
+	LastSyncError string     `json:"last_sync_error" gorm:"type:text"`
+	CreatedAt     time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt     time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+type BlockedTime struct {
+	ID          uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	VendorID    uuid.UUID `json:"vendor_id" gorm:"type:uuid;not null;index:idx_blocked_times_vendor_range"`
+	CalendarID  uuid.UUID `json:"calendar_id" gorm:"type:uuid;not null;uniqueIndex:idx_blocked_times_event"`
+	ExternalUID string    `json:"external_uid" gorm:"type:varchar(255);uniqueIndex:idx_blocked_times_event"`
+	StartsAt    time.Time `json:"starts_at" gorm:"type:timestamptz;not null;uniqueIndex:idx_blocked_times_event;index:idx_blocked_times_vendor_range"`
+	EndsAt      time.Time `json:"ends_at" gorm:"type:timestamptz;not null;index:idx_blocked_times_vendor_range"`
+	Summary     string    `json:"summary" gorm:"type:text"`
+	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
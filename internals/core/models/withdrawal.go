@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/money"
+	"github.com/google/uuid"
+)
+
+// Withdrawal is a vendor's request to move money out of their wallet. The
+// amount is held from the wallet when the request is made. Status moves from
+// pending to processing when an admin approves it with DecideWithdrawal, then
+// to paid or failed once the payout provider settles it, either straight away
+// or when ReconcileWithdrawals retries it. An admin can instead reject a
+// pending withdrawal. Rejected and failed withdrawals return the hold to the
+// wallet.
+type Withdrawal struct {
+	ID                uuid.UUID   `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	VendorID          uuid.UUID   `json:"vendor_id" gorm:"type:uuid;not null;index"`
+	Amount            money.Money `json:"amount" gorm:"type:bigint;not null;check:amount > 0"`
+	Status            string      `json:"status" gorm:"type:varchar(20);not null;default:'pending';index"`
+	DecidedBy         *uuid.UUID  `json:"decided_by" gorm:"type:uuid"`
+	Reason            string      `json:"reason" gorm:"type:text"`
+	ProviderReference string      `json:"provider_reference" gorm:"type:varchar(255)"`
+	FailureReason     string      `json:"failure_reason" gorm:"type:text"`
+	DecidedAt         *time.Time  `json:"decided_at" gorm:"type:timestamptz"`
+	PaidAt            *time.Time  `json:"paid_at" gorm:"type:timestamptz"`
+	CreatedAt         time.Time   `json:"created_at" gorm:"autoCreateTime;index"`
+	UpdatedAt         time.Time   `json:"updated_at" gorm:"autoUpdateTime"`
+}
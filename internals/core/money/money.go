@@ -0,0 +1,223 @@
+// Package money represents amounts as an integer count of minor units (paise
+// for INR) together with their currency, so prices, balances and payouts
+// never pass through floating point.
+package money
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// DefaultCurrency is the currency every amount stored by this service is in.
+// Money columns hold only the minor-unit count; each table's currency column
+// records the currency and is constrained to this one, so amounts read from
+// the database come back in it.
+const DefaultCurrency = "INR"
+
+// MinorPerMajor is the number of minor units in one major unit of
+// DefaultCurrency.
+const MinorPerMajor = 100
+
+var (
+	ErrCurrencyMismatch = errors.New("money: currency mismatch")
+	ErrOverflow         = errors.New("money: amount out of range")
+	ErrFractional       = errors.New("money: amount is not a whole number of major units")
+)
+
+type Money struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+// New returns amount minor units of DefaultCurrency.
+func New(amount int64) Money {
+	return Money{Amount: amount, Currency: DefaultCurrency}
+}
+
+// Zero returns no money in DefaultCurrency.
+func Zero() Money {
+	return New(0)
+}
+
+// FromMajor converts whole major units, as stored by the wallet, booking and
+// transaction tables shared with other services, into Money.
+func FromMajor(major int64) (Money, error) {
+	amount, ok := mul64(major, MinorPerMajor)
+	if !ok {
+		return Money{}, ErrOverflow
+	}
+	return New(amount), nil
+}
+
+// FromMajorFloat converts a major-unit float, as stored by the admin wallet,
+// rounding to the nearest minor unit.
+func FromMajorFloat(major float64) (Money, error) {
+	minor := math.Round(major * MinorPerMajor)
+	if math.IsNaN(minor) || minor > math.MaxInt64 || minor < math.MinInt64 {
+		return Money{}, ErrOverflow
+	}
+	return New(int64(minor)), nil
+}
+
+// Major returns m as whole major units for the tables that store them. It
+// fails rather than drop a fraction.
+func (m Money) Major() (int64, error) {
+	if m.Amount%MinorPerMajor != 0 {
+		return 0, fmt.Errorf("%w: %s", ErrFractional, m)
+	}
+	return m.Amount / MinorPerMajor, nil
+}
+
+// TruncateMajor drops any fraction of a major unit. Amounts split into
+// shares that end up in whole-unit columns are truncated this way, with the
+// remainder going to the last share.
+func (m Money) TruncateMajor() Money {
+	return Money{Amount: m.Amount - m.Amount%MinorPerMajor, Currency: m.currency()}
+}
+
+func (m Money) currency() string {
+	if m.Currency == "" {
+		return DefaultCurrency
+	}
+	return m.Currency
+}
+
+func (m Money) sameCurrency(other Money) error {
+	if m.currency() != other.currency() {
+		return fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.currency(), other.currency())
+	}
+	return nil
+}
+
+func (m Money) Add(other Money) (Money, error) {
+	if err := m.sameCurrency(other); err != nil {
+		return Money{}, err
+	}
+	sum := m.Amount + other.Amount
+	if (other.Amount > 0 && sum < m.Amount) || (other.Amount < 0 && sum > m.Amount) {
+		return Money{}, ErrOverflow
+	}
+	return Money{Amount: sum, Currency: m.currency()}, nil
+}
+
+func (m Money) Sub(other Money) (Money, error) {
+	if err := m.sameCurrency(other); err != nil {
+		return Money{}, err
+	}
+	if other.Amount == math.MinInt64 {
+		return Money{}, ErrOverflow
+	}
+	return m.Add(Money{Amount: -other.Amount, Currency: other.Currency})
+}
+
+// Mul multiplies m by a whole quantity, such as hours or line item units.
+func (m Money) Mul(n int64) (Money, error) {
+	product, ok := mul64(m.Amount, n)
+	if !ok {
+		return Money{}, ErrOverflow
+	}
+	return Money{Amount: product, Currency: m.currency()}, nil
+}
+
+// MulDiv returns m * num / den, truncated toward zero. Callers splitting an
+// amount into shares give the remainder to the last share.
+func (m Money) MulDiv(num, den int64) (Money, error) {
+	if den == 0 {
+		return Money{}, errors.New("money: division by zero")
+	}
+	product, ok := mul64(m.Amount, num)
+	if !ok {
+		return Money{}, ErrOverflow
+	}
+	return Money{Amount: product / den, Currency: m.currency()}, nil
+}
+
+// Percent returns percent% of m, truncated toward zero.
+func (m Money) Percent(percent int64) (Money, error) {
+	return m.MulDiv(percent, 100)
+}
+
+func (m Money) IsZero() bool     { return m.Amount == 0 }
+func (m Money) IsPositive() bool { return m.Amount > 0 }
+func (m Money) IsNegative() bool { return m.Amount < 0 }
+
+// LessThan reports whether m is smaller than other. Amounts in different
+// currencies are never comparable.
+func (m Money) LessThan(other Money) (bool, error) {
+	if err := m.sameCurrency(other); err != nil {
+		return false, err
+	}
+	return m.Amount < other.Amount, nil
+}
+
+// String formats m as "INR 1234.50".
+func (m Money) String() string {
+	return fmt.Sprintf("%s %s", m.currency(), m.Decimal())
+}
+
+// Decimal formats the amount of m in major units, as "1234.50".
+func (m Money) Decimal() string {
+	sign := ""
+	amount := m.Amount
+	if amount < 0 {
+		sign = "-"
+	}
+	whole := amount / MinorPerMajor
+	frac := amount % MinorPerMajor
+	if whole < 0 {
+		whole = -whole
+	}
+	if frac < 0 {
+		frac = -frac
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, whole, frac)
+}
+
+// Value stores the minor-unit count. Only DefaultCurrency can be stored.
+func (m Money) Value() (driver.Value, error) {
+	if m.currency() != DefaultCurrency {
+		return nil, fmt.Errorf("%w: cannot store %s", ErrCurrencyMismatch, m.currency())
+	}
+	return m.Amount, nil
+}
+
+func (m *Money) Scan(src interface{}) error {
+	var amount int64
+	switch v := src.(type) {
+	case nil:
+		amount = 0
+	case int64:
+		amount = v
+	case []byte:
+		parsed, err := strconv.ParseInt(string(v), 10, 64)
+		if err != nil {
+			return fmt.Errorf("money: cannot scan %q: %w", v, err)
+		}
+		amount = parsed
+	case string:
+		parsed, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return fmt.Errorf("money: cannot scan %q: %w", v, err)
+		}
+		amount = parsed
+	default:
+		return fmt.Errorf("money: cannot scan %T", src)
+	}
+
+	*m = New(amount)
+	return nil
+}
+
+func mul64(a, b int64) (int64, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+	product := a * b
+	if product/b != a || (a == -1 && b == math.MinInt64) || (b == -1 && a == math.MinInt64) {
+		return 0, false
+	}
+	return product, true
+}
@@ -0,0 +1,201 @@
+package money
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestFromMajor(t *testing.T) {
+	tests := []struct {
+		major   int64
+		want    Money
+		wantErr error
+	}{
+		{major: 0, want: New(0)},
+		{major: 12, want: New(1200)},
+		{major: -5, want: New(-500)},
+		{major: math.MaxInt64 / 10, wantErr: ErrOverflow},
+	}
+
+	for _, tt := range tests {
+		got, err := FromMajor(tt.major)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("FromMajor(%d) error = %v, want %v", tt.major, err, tt.wantErr)
+			continue
+		}
+		if err == nil && got != tt.want {
+			t.Errorf("FromMajor(%d) = %v, want %v", tt.major, got, tt.want)
+		}
+	}
+}
+
+func TestFromMajorFloat(t *testing.T) {
+	tests := []struct {
+		major   float64
+		want    int64
+		wantErr error
+	}{
+		{major: 12.5, want: 1250},
+		{major: 0.1 + 0.2, want: 30},
+		{major: 19.999, want: 2000},
+		{major: -3.005, want: -301},
+		{major: math.NaN(), wantErr: ErrOverflow},
+		{major: math.Inf(1), wantErr: ErrOverflow},
+	}
+
+	for _, tt := range tests {
+		got, err := FromMajorFloat(tt.major)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("FromMajorFloat(%v) error = %v, want %v", tt.major, err, tt.wantErr)
+			continue
+		}
+		if err == nil && got != New(tt.want) {
+			t.Errorf("FromMajorFloat(%v) = %v, want %d minor units", tt.major, got, tt.want)
+		}
+	}
+}
+
+func TestMajor(t *testing.T) {
+	tests := []struct {
+		amount    int64
+		want      int64
+		wantErr   error
+		truncated int64
+	}{
+		{amount: 1200, want: 12, truncated: 1200},
+		{amount: -1200, want: -12, truncated: -1200},
+		{amount: 1250, wantErr: ErrFractional, truncated: 1200},
+		{amount: -1250, wantErr: ErrFractional, truncated: -1200},
+	}
+
+	for _, tt := range tests {
+		m := New(tt.amount)
+		got, err := m.Major()
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("%v.Major() error = %v, want %v", m, err, tt.wantErr)
+		} else if err == nil && got != tt.want {
+			t.Errorf("%v.Major() = %d, want %d", m, got, tt.want)
+		}
+		if truncated := m.TruncateMajor(); truncated != New(tt.truncated) {
+			t.Errorf("%v.TruncateMajor() = %v, want %d minor units", m, truncated, tt.truncated)
+		}
+	}
+}
+
+func TestArithmetic(t *testing.T) {
+	usd := Money{Amount: 100, Currency: "USD"}
+
+	tests := []struct {
+		name    string
+		op      func() (Money, error)
+		want    Money
+		wantErr error
+	}{
+		{name: "add", op: func() (Money, error) { return New(150).Add(New(250)) }, want: New(400)},
+		{name: "add to unset currency", op: func() (Money, error) { return Money{Amount: 1}.Add(New(2)) }, want: New(3)},
+		{name: "add overflow", op: func() (Money, error) { return New(math.MaxInt64).Add(New(1)) }, wantErr: ErrOverflow},
+		{name: "add underflow", op: func() (Money, error) { return New(math.MinInt64).Add(New(-1)) }, wantErr: ErrOverflow},
+		{name: "add currency mismatch", op: func() (Money, error) { return New(100).Add(usd) }, wantErr: ErrCurrencyMismatch},
+		{name: "sub", op: func() (Money, error) { return New(150).Sub(New(250)) }, want: New(-100)},
+		{name: "sub min int", op: func() (Money, error) { return New(0).Sub(New(math.MinInt64)) }, wantErr: ErrOverflow},
+		{name: "sub currency mismatch", op: func() (Money, error) { return usd.Sub(New(1)) }, wantErr: ErrCurrencyMismatch},
+		{name: "mul", op: func() (Money, error) { return New(1250).Mul(3) }, want: New(3750)},
+		{name: "mul overflow", op: func() (Money, error) { return New(math.MaxInt64 / 2).Mul(3) }, wantErr: ErrOverflow},
+		{name: "mul div truncates", op: func() (Money, error) { return New(1000).MulDiv(1, 3) }, want: New(333)},
+		{name: "mul div negative truncates toward zero", op: func() (Money, error) { return New(-1000).MulDiv(1, 3) }, want: New(-333)},
+		{name: "mul div overflow", op: func() (Money, error) { return New(math.MaxInt64).MulDiv(2, 4) }, wantErr: ErrOverflow},
+		{name: "percent", op: func() (Money, error) { return New(1999).Percent(15) }, want: New(299)},
+		{name: "percent keeps currency", op: func() (Money, error) { return usd.Percent(50) }, want: Money{Amount: 50, Currency: "USD"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.op()
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := New(1).MulDiv(1, 0); err == nil {
+		t.Error("MulDiv by zero succeeded")
+	}
+}
+
+func TestLessThan(t *testing.T) {
+	if less, err := New(100).LessThan(New(200)); err != nil || !less {
+		t.Errorf("100 < 200 = %v, %v", less, err)
+	}
+	if less, err := New(200).LessThan(New(200)); err != nil || less {
+		t.Errorf("200 < 200 = %v, %v", less, err)
+	}
+	if _, err := New(100).LessThan(Money{Amount: 200, Currency: "USD"}); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("comparing currencies error = %v, want %v", err, ErrCurrencyMismatch)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		money   Money
+		decimal string
+		str     string
+	}{
+		{money: New(0), decimal: "0.00", str: "INR 0.00"},
+		{money: New(5), decimal: "0.05", str: "INR 0.05"},
+		{money: New(123450), decimal: "1234.50", str: "INR 1234.50"},
+		{money: New(-5), decimal: "-0.05", str: "INR -0.05"},
+		{money: New(-123450), decimal: "-1234.50", str: "INR -1234.50"},
+		{money: Money{Amount: 100}, decimal: "1.00", str: "INR 1.00"},
+		{money: Money{Amount: 100, Currency: "USD"}, decimal: "1.00", str: "USD 1.00"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.money.Decimal(); got != tt.decimal {
+			t.Errorf("Decimal(%d) = %q, want %q", tt.money.Amount, got, tt.decimal)
+		}
+		if got := tt.money.String(); got != tt.str {
+			t.Errorf("String(%d) = %q, want %q", tt.money.Amount, got, tt.str)
+		}
+	}
+}
+
+func TestValue(t *testing.T) {
+	got, err := New(1250).Value()
+	if err != nil || got != int64(1250) {
+		t.Errorf("Value() = %v, %v; want 1250", got, err)
+	}
+	if _, err := (Money{Amount: 1250, Currency: "USD"}).Value(); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("storing USD error = %v, want %v", err, ErrCurrencyMismatch)
+	}
+}
+
+func TestScan(t *testing.T) {
+	tests := []struct {
+		src     interface{}
+		want    int64
+		wantErr bool
+	}{
+		{src: nil, want: 0},
+		{src: int64(1250), want: 1250},
+		{src: []byte("-300"), want: -300},
+		{src: "42", want: 42},
+		{src: "12.50", wantErr: true},
+		{src: 1.5, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		var m Money
+		err := m.Scan(tt.src)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Scan(%#v) error = %v, wantErr %v", tt.src, err, tt.wantErr)
+			continue
+		}
+		if err == nil && m != New(tt.want) {
+			t.Errorf("Scan(%#v) = %v, want %d minor units", tt.src, m, tt.want)
+		}
+	}
+}
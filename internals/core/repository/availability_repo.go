@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/models"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/models/responses"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CreateExternalCalendar saves a new calendar together with the blocks
+// imported from it.
+func (r *VendorStorage) CreateExternalCalendar(ctx context.Context, calendar *models.ExternalCalendar, blocks []models.BlockedTime) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		calendar.LastSyncedAt = &now
+		if err := tx.Create(calendar).Error; err != nil {
+			return err
+		}
+
+		if len(blocks) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(blocks, 200).Error
+	})
+}
+
+func (r *VendorStorage) GetExternalCalendar(ctx context.Context, vendorID, calendarID string) (*models.ExternalCalendar, error) {
+	var calendar models.ExternalCalendar
+	err := r.DB.WithContext(ctx).Where("id = ? AND vendor_id = ?", calendarID, vendorID).First(&calendar).Error
+	if err != nil {
+		return nil, err
+	}
+	return &calendar, nil
+}
+
+func (r *VendorStorage) ListExternalCalendars(ctx context.Context, vendorID string) ([]models.ExternalCalendar, error) {
+	var calendars []models.ExternalCalendar
+	err := r.DB.WithContext(ctx).Where("vendor_id = ?", vendorID).Order("created_at ASC").Find(&calendars).Error
+	if err != nil {
+		return nil, err
+	}
+	return calendars, nil
+}
+
+func (r *VendorStorage) ListSubscribedCalendars(ctx context.Context) ([]models.ExternalCalendar, error) {
+	var calendars []models.ExternalCalendar
+	err := r.DB.WithContext(ctx).Where("source_url <> ''").Find(&calendars).Error
+	if err != nil {
+		return nil, err
+	}
+	return calendars, nil
+}
+
+func (r *VendorStorage) DeleteExternalCalendar(ctx context.Context, vendorID, calendarID string) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ? AND vendor_id = ?", calendarID, vendorID).Delete(&models.ExternalCalendar{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return tx.Where("calendar_id = ?", calendarID).Delete(&models.BlockedTime{}).Error
+	})
+}
+
+// ReplaceBlockedTimes swaps every block imported from the calendar for the
+// freshly parsed set, so a re-sync never leaves stale or duplicate blocks.
+func (r *VendorStorage) ReplaceBlockedTimes(ctx context.Context, calendar *models.ExternalCalendar, blocks []models.BlockedTime) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("calendar_id = ?", calendar.ID).Delete(&models.BlockedTime{}).Error; err != nil {
+			return err
+		}
+
+		if len(blocks) > 0 {
+			if err := tx.CreateInBatches(blocks, 200).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(calendar).Updates(map[string]interface{}{
+			"last_synced_at":  time.Now(),
+			"last_sync_error": "",
+		}).Error
+	})
+}
+
+func (r *VendorStorage) RecordCalendarSyncError(ctx context.Context, calendarID uuid.UUID, syncErr string) error {
+	return r.DB.WithContext(ctx).
+		Model(&models.ExternalCalendar{}).
+		Where("id = ?", calendarID).
+		Update("last_sync_error", syncErr).Error
+}
+
+func (r *VendorStorage) GetBlockedTimes(ctx context.Context, vendorID string, from, to time.Time) ([]models.BlockedTime, error) {
+	var blocks []models.BlockedTime
+	err := r.DB.WithContext(ctx).
+		Where("vendor_id = ? AND starts_at < ? AND ends_at > ?", vendorID, to, from).
+		Order("starts_at ASC").
+		Find(&blocks).Error
+	if err != nil {
+		return nil, err
+	}
+	return blocks, nil
+}
+
+func (r *VendorStorage) GetVendorBusyBookings(ctx context.Context, vendorID string, from, to time.Time) ([]responses.CalendarBooking, error) {
+	var bookings []responses.CalendarBooking
+
+	err := r.DB.WithContext(ctx).
+		Table("bookings b").
+		Select(`
+			b.booking_id,
+			b.service,
+			b.date,
+			b.status,
+			COALESCE((
+				SELECT s.service_duration FROM services s
+				WHERE s.vendor_id = b.vendor_id AND s.service_title = b.service
+				ORDER BY s.created_at DESC LIMIT 1
+			), 1) AS service_duration,
+			b.updated_at
+		`).
+		Where("b.vendor_id = ? AND b.status IN ? AND b.date < ? AND b.date >= ?",
+			vendorID, []string{"approved", "completed", "disputed"}, to, from.Add(-24*time.Hour)).
+		Order("b.date ASC").
+		Scan(&bookings).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return bookings, nil
+}
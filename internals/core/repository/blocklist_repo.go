@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/models"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/models/responses"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrClientNotBlocked = errors.New("client is not blocked")
+
+// BlockClient adds the client to the vendor's blocklist, updating the reason
+// if they are already on it, and takes them off the vendor's waitlists. It
+// returns the waitlist offers that were withdrawn so their slots can be passed
+// on.
+func (r *VendorStorage) BlockClient(ctx context.Context, block *models.ClientBlock) ([]models.WaitlistEntry, error) {
+	var withdrawn []models.WaitlistEntry
+
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "vendor_id"}, {Name: "client_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"reason"}),
+		}).Create(block).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("vendor_id = ? AND client_id = ? AND status = ?", block.VendorID, block.ClientID, "offered").
+			Find(&withdrawn).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&models.WaitlistEntry{}).
+			Where("vendor_id = ? AND client_id = ? AND status IN ?", block.VendorID, block.ClientID, []string{"waiting", "offered"}).
+			Update("status", "left").Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return withdrawn, nil
+}
+
+func (r *VendorStorage) UnblockClient(ctx context.Context, vendorID, clientID uuid.UUID) error {
+	result := r.DB.WithContext(ctx).
+		Where("vendor_id = ? AND client_id = ?", vendorID, clientID).
+		Delete(&models.ClientBlock{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrClientNotBlocked
+	}
+	return nil
+}
+
+func (r *VendorStorage) IsClientBlocked(ctx context.Context, vendorID, clientID uuid.UUID) (bool, error) {
+	var count int64
+	err := r.DB.WithContext(ctx).
+		Model(&models.ClientBlock{}).
+		Where("vendor_id = ? AND client_id = ?", vendorID, clientID).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// ListBlockedClients returns the blocklist with client contact details. A nil
+// vendorID lists every vendor's blocks for admin review.
+func (r *VendorStorage) ListBlockedClients(ctx context.Context, vendorID uuid.UUID) ([]responses.BlockedClient, error) {
+	var blocked []responses.BlockedClient
+
+	query := r.DB.WithContext(ctx).
+		Table("client_blocks cb").
+		Select(`
+			cb.vendor_id,
+			cb.client_id,
+			ud.first_name AS client_first_name,
+			ud.last_name AS client_last_name,
+			u.email AS client_email,
+			cb.reason,
+			cb.created_at
+		`).
+		Joins("LEFT JOIN users u ON u.user_id = cb.client_id").
+		Joins("LEFT JOIN user_details ud ON ud.user_id = cb.client_id")
+
+	if vendorID != uuid.Nil {
+		query = query.Where("cb.vendor_id = ?", vendorID)
+	}
+
+	err := query.Order("cb.created_at DESC").Scan(&blocked).Error
+	if err != nil {
+		return nil, err
+	}
+	return blocked, nil
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	adminModel "github.com/AthulKrishna2501/zyra-admin-service/internals/core/models"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrInvalidTransition = errors.New("invalid booking status transition")
+
+func (r *VendorStorage) CreateBookingEvent(ctx context.Context, event *models.BookingEvent) error {
+	return r.DB.WithContext(ctx).Create(event).Error
+}
+
+func (r *VendorStorage) GetBookingEvents(ctx context.Context, bookingID string) ([]models.BookingEvent, error) {
+	var events []models.BookingEvent
+	err := r.DB.WithContext(ctx).Where("booking_id = ?", bookingID).Order("created_at ASC").Find(&events).Error
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+func (r *VendorStorage) TransitionBookingStatus(ctx context.Context, bookingID string, fromStatuses []string, newStatus string, event *models.BookingEvent) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := transitionBookingStatus(tx, bookingID, fromStatuses, newStatus, event); err != nil {
+			return err
+		}
+		return syncBookingHolds(tx, event.BookingID)
+	})
+}
+
+// transitionBookingStatus locks the booking row, moves it to newStatus and
+// appends the event with the status it replaced. When fromStatuses is set the
+// booking must currently be in one of them.
+func transitionBookingStatus(tx *gorm.DB, bookingID string, fromStatuses []string, newStatus string, event *models.BookingEvent) error {
+	var booking adminModel.Booking
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("booking_id = ?", bookingID).
+		First(&booking).Error; err != nil {
+		return fmt.Errorf("failed to find booking: %w", err)
+	}
+
+	if len(fromStatuses) > 0 && !slices.Contains(fromStatuses, booking.Status) {
+		return fmt.Errorf("%w: booking is %s", ErrInvalidTransition, booking.Status)
+	}
+
+	if err := tx.Model(&adminModel.Booking{}).
+		Where("booking_id = ?", bookingID).
+		Update("status", newStatus).Error; err != nil {
+		return fmt.Errorf("failed to update booking status: %w", err)
+	}
+
+	event.BookingID = booking.BookingID
+	event.OldStatus = booking.Status
+	event.NewStatus = newStatus
+	if err := tx.Create(event).Error; err != nil {
+		return fmt.Errorf("failed to record booking event: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	adminModel "github.com/AthulKrishna2501/zyra-admin-service/internals/core/models"
+	clientModel "github.com/AthulKrishna2501/zyra-client-service/internals/core/models"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/models"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/money"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RecordNewBooking stores what this service needs to know about a booking the
+// client service has just created: the service's terms at booking time, the
+// transaction the client paid with and the booking's installments. Any of
+// them may be absent. The amount the transaction collected went into escrow
+// in the client service, so it is posted to the ledger here, and the client's
+// receipt is issued. Recording a booking twice keeps the first snapshot,
+// schedule and receipt and posts the payment once.
+func (r *VendorStorage) RecordNewBooking(ctx context.Context, booking *adminModel.Booking, service *models.Service, transaction *clientModel.Transaction, installments []models.BookingInstallment) error {
+	bookingID := booking.BookingID
+
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if service != nil {
+			if err := saveBookingSnapshot(tx, bookingID, service, time.Now()); err != nil {
+				return err
+			}
+		}
+
+		if len(installments) > 0 {
+			var existing int64
+			if err := tx.Model(&models.BookingInstallment{}).Where("booking_id = ?", bookingID).Count(&existing).Error; err != nil {
+				return fmt.Errorf("failed to check installments: %w", err)
+			}
+			if existing == 0 {
+				if err := tx.Create(&installments).Error; err != nil {
+					return fmt.Errorf("failed to save installments: %w", err)
+				}
+			}
+		}
+
+		if transaction != nil {
+			if err := linkBookingTransaction(tx, transaction.TransactionID, bookingID); err != nil {
+				return err
+			}
+			if err := postBookingPayment(tx, transaction); err != nil {
+				return err
+			}
+		}
+
+		if err := issueBookingReceipt(tx, booking); err != nil {
+			return err
+		}
+		return syncFundHolds(tx, booking.VendorID)
+	})
+}
+
+// postBookingPayment records a payment the client service collected into
+// escrow. Wallet payments come out of the client's wallet; anything else came
+// from outside the platform.
+func postBookingPayment(tx *gorm.DB, transaction *clientModel.Transaction) error {
+	if transaction.AmountPaid <= 0 {
+		return nil
+	}
+
+	var posted int64
+	if err := tx.Model(&models.LedgerTransaction{}).
+		Where("kind = ? AND reference = ?", "booking_payment", transaction.TransactionID.String()).
+		Count(&posted).Error; err != nil {
+		return fmt.Errorf("failed to check booking payment: %w", err)
+	}
+	if posted > 0 {
+		return nil
+	}
+
+	amount, err := models.TransactionAmount(transaction)
+	if err != nil {
+		return err
+	}
+	from := platformAccount(ledgerCodeExternal)
+	if transaction.PaymentMethod == "wallet" {
+		from = clientWalletAccount(transaction.UserID)
+	}
+
+	leg := ledgerLeg{From: from, To: treasuryLedgerAccount(TreasuryEscrow), Amount: amount}
+	return postLedger(tx, "booking_payment", transaction.TransactionID.String(), transaction.Purpose, leg)
+}
+
+func (r *VendorStorage) GetTransactionByID(ctx context.Context, transactionID string) (*clientModel.Transaction, error) {
+	var transaction clientModel.Transaction
+	err := r.DB.WithContext(ctx).Where("transaction_id = ?", transactionID).First(&transaction).Error
+	if err != nil {
+		return nil, err
+	}
+	return &transaction, nil
+}
+
+func (r *VendorStorage) GetBookingSnapshot(ctx context.Context, bookingID string) (*models.BookingSnapshot, error) {
+	var snapshot models.BookingSnapshot
+	err := r.DB.WithContext(ctx).Where("booking_id = ?", bookingID).First(&snapshot).Error
+	if err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// GetBookingTransactions returns the transactions linked to the booking,
+// oldest first.
+func (r *VendorStorage) GetBookingTransactions(ctx context.Context, bookingID string) ([]clientModel.Transaction, error) {
+	var transactions []clientModel.Transaction
+
+	err := r.DB.WithContext(ctx).
+		Joins("JOIN booking_transactions bt ON bt.transaction_id = transactions.transaction_id").
+		Where("bt.booking_id = ?", bookingID).
+		Order("transactions.date_of_payment ASC").
+		Find(&transactions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return transactions, nil
+}
+
+// saveBookingSnapshot copies the service's current terms for the booking,
+// leaving an existing snapshot untouched.
+func saveBookingSnapshot(tx *gorm.DB, bookingID uuid.UUID, service *models.Service, at time.Time) error {
+	snapshot := models.BookingSnapshot{
+		BookingID:           bookingID,
+		ServiceID:           service.ID,
+		ServiceTitle:        service.ServiceTitle,
+		YearOfExperience:    service.YearOfExperience,
+		AvailableDate:       service.AvailableDate,
+		ServiceDescription:  service.ServiceDescription,
+		CancellationPolicy:  service.CancellationPolicy,
+		TermsAndConditions:  service.TermsAndConditions,
+		ServiceDuration:     service.ServiceDuration,
+		ServicePrice:        service.ServicePrice,
+		AdditionalHourPrice: service.AdditionalHourPrice,
+		MinNoticeHours:      service.MinNoticeHours,
+		MaxAdvanceDays:      service.MaxAdvanceDays,
+		CapturedAt:          at,
+	}
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&snapshot).Error; err != nil {
+		return fmt.Errorf("failed to save booking snapshot: %w", err)
+	}
+	return nil
+}
+
+// linkBookingTransaction ties a transaction to each of the given bookings.
+func linkBookingTransaction(tx *gorm.DB, transactionID uuid.UUID, bookingIDs ...uuid.UUID) error {
+	if len(bookingIDs) == 0 {
+		return nil
+	}
+
+	links := make([]models.BookingTransaction, 0, len(bookingIDs))
+	for _, bookingID := range bookingIDs {
+		links = append(links, models.BookingTransaction{BookingID: bookingID, TransactionID: transactionID})
+	}
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&links).Error; err != nil {
+		return fmt.Errorf("failed to link booking transaction: %w", err)
+	}
+	return nil
+}
+
+// recordBookingTransaction is recordUserTransaction for money that belongs to
+// a booking: the transaction is linked to the booking so it shows on the
+// booking's timeline.
+func recordBookingTransaction(tx *gorm.DB, bookingID, userID uuid.UUID, purpose, paymentStatus string, amount money.Money, at time.Time) error {
+	transactionID, err := createUserTransaction(tx, userID, purpose, paymentStatus, amount, at)
+	if err != nil {
+		return err
+	}
+	return linkBookingTransaction(tx, transactionID, bookingID)
+}
@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	adminModel "github.com/AthulKrishna2501/zyra-admin-service/internals/core/models"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/models"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/money"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func (r *VendorStorage) GetBundleDiscounts(ctx context.Context, vendorID uuid.UUID) ([]models.BundleDiscount, error) {
+	var discounts []models.BundleDiscount
+	err := r.DB.WithContext(ctx).Where("vendor_id = ?", vendorID).Order("min_services ASC").Find(&discounts).Error
+	if err != nil {
+		return nil, err
+	}
+	return discounts, nil
+}
+
+func (r *VendorStorage) ReplaceBundleDiscounts(ctx context.Context, vendorID uuid.UUID, discounts []models.BundleDiscount) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("vendor_id = ?", vendorID).Delete(&models.BundleDiscount{}).Error; err != nil {
+			return err
+		}
+
+		if len(discounts) == 0 {
+			return nil
+		}
+		return tx.Create(&discounts).Error
+	})
+}
+
+// CreateBundleBooking charges the bundle total to the client's wallet and
+// creates a pending booking for every item, all in one transaction. Items
+// keep the BookingID the caller gave them so that installments can be built
+// for the bookings beforehand; installments are saved with the bookings.
+func (r *VendorStorage) CreateBundleBooking(ctx context.Context, bundle *models.BundleBooking, installments []models.BookingInstallment) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		wallet, err := lockWalletForDebit(tx, clientWalletOwner(bundle.ClientID), bundle.Total)
+		if err != nil {
+			return err
+		}
+		if err := debitWallet(tx, wallet, bundle.Total); err != nil {
+			return err
+		}
+
+		if err := r.creditTreasury(tx, TreasuryEscrow, bundle.Total); err != nil {
+			return err
+		}
+
+		for i := range bundle.Items {
+			price, err := wholeUnits(bundle.Items[i].Price)
+			if err != nil {
+				return err
+			}
+
+			booking := adminModel.Booking{
+				BookingID: bundle.Items[i].BookingID,
+				ClientID:  bundle.ClientID,
+				VendorID:  bundle.VendorID,
+				Service:   bundle.Items[i].ServiceTitle,
+				Date:      bundle.Date,
+				Price:     price,
+				Status:    "pending",
+			}
+			if err := tx.Create(&booking).Error; err != nil {
+				return fmt.Errorf("failed to create booking: %w", err)
+			}
+
+			var service models.Service
+			if err := tx.Where("id = ?", bundle.Items[i].ServiceID).First(&service).Error; err != nil {
+				return fmt.Errorf("failed to fetch service: %w", err)
+			}
+			if err := saveBookingSnapshot(tx, booking.BookingID, &service, booking.CreatedAt); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Create(bundle).Error; err != nil {
+			return fmt.Errorf("failed to create bundle: %w", err)
+		}
+
+		if len(installments) > 0 {
+			if err := tx.Create(&installments).Error; err != nil {
+				return fmt.Errorf("failed to save installments: %w", err)
+			}
+		}
+
+		now := time.Now()
+		transactionID, err := createUserTransaction(tx, bundle.ClientID, "Vendor Bundle Booking", "paid", bundle.Total, now)
+		if err != nil {
+			return err
+		}
+		bookingIDs := make([]uuid.UUID, 0, len(bundle.Items))
+		for _, item := range bundle.Items {
+			bookingIDs = append(bookingIDs, item.BookingID)
+		}
+		if err := linkBookingTransaction(tx, transactionID, bookingIDs...); err != nil {
+			return err
+		}
+
+		if err := recordAdminTransaction(tx, "Vendor Booking", "paid", bundle.Total, now); err != nil {
+			return err
+		}
+
+		leg := ledgerLeg{From: clientWalletAccount(bundle.ClientID), To: treasuryLedgerAccount(TreasuryEscrow), Amount: bundle.Total}
+		if err := postLedger(tx, "bundle_payment", bundle.ID.String(), "Vendor Bundle Booking", leg); err != nil {
+			return err
+		}
+
+		lines := make([]models.InvoiceLine, 0, len(bundle.Items)+1)
+		for _, item := range bundle.Items {
+			lines = append(lines, singleItemLine(item.ServiceTitle, item.ListPrice))
+		}
+		if bundle.DiscountAmount.IsPositive() {
+			discount, err := money.Zero().Sub(bundle.DiscountAmount)
+			if err != nil {
+				return err
+			}
+			lines = append(lines, models.InvoiceLine{
+				Kind:        models.InvoiceLineDiscount,
+				Description: fmt.Sprintf("Bundle discount (%d%%)", bundle.DiscountPercent),
+				Quantity:    1,
+				UnitPrice:   discount,
+				Amount:      discount,
+			})
+		}
+		_, err = issueInvoice(tx, invoiceSource{
+			kind:       models.InvoiceKindReceipt,
+			sourceType: "bundle",
+			sourceID:   bundle.ID.String(),
+			vendorID:   bundle.VendorID,
+			clientID:   bundle.ClientID,
+		}, lines)
+		return err
+	})
+}
+
+func (r *VendorStorage) GetBundleBooking(ctx context.Context, bundleID string) (*models.BundleBooking, error) {
+	var bundle models.BundleBooking
+	err := r.DB.WithContext(ctx).Preload("Items").Where("id = ?", bundleID).First(&bundle).Error
+	if err != nil {
+		return nil, err
+	}
+	return &bundle, nil
+}
+
+func (r *VendorStorage) GetBundleItemByBookingID(ctx context.Context, bookingID uuid.UUID) (*models.BundleItem, error) {
+	var item models.BundleItem
+	err := r.DB.WithContext(ctx).Where("booking_id = ?", bookingID).First(&item).Error
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// DecideBundleBooking applies the vendor's decision to the bundle and every
+// booking in it in one transaction, so a failure leaves the whole bundle
+// pending. Each booking is refunded or released on its own, exactly as a
+// single booking would be; event is copied for every booking.
+func (r *VendorStorage) DecideBundleBooking(ctx context.Context, bundle *models.BundleBooking, bookings []*adminModel.Booking, decision string, event *models.BookingEvent) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.BundleBooking{}).
+			Where("id = ? AND status = ?", bundle.ID, "pending").
+			Update("status", decision)
+		if result.Error != nil {
+			return fmt.Errorf("failed to update bundle status: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("%w: bundle is no longer pending", ErrInvalidTransition)
+		}
+
+		for _, booking := range bookings {
+			itemEvent := *event
+			if _, err := r.decideBooking(tx, booking, decision, &itemEvent); err != nil {
+				return err
+			}
+		}
+
+		return syncFundHolds(tx, bundle.VendorID)
+	})
+}
@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/models"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/models/responses"
+)
+
+func (r *VendorStorage) GetCalendarFeed(ctx context.Context, vendorID string) (*models.CalendarFeed, error) {
+	var feed models.CalendarFeed
+	err := r.DB.WithContext(ctx).Where("vendor_id = ?", vendorID).First(&feed).Error
+	if err != nil {
+		return nil, err
+	}
+	return &feed, nil
+}
+
+func (r *VendorStorage) GetCalendarFeedByToken(ctx context.Context, token string) (*models.CalendarFeed, error) {
+	var feed models.CalendarFeed
+	err := r.DB.WithContext(ctx).Where("token = ?", token).First(&feed).Error
+	if err != nil {
+		return nil, err
+	}
+	return &feed, nil
+}
+
+func (r *VendorStorage) SaveCalendarFeed(ctx context.Context, feed *models.CalendarFeed) error {
+	return r.DB.WithContext(ctx).Save(feed).Error
+}
+
+func (r *VendorStorage) GetVendorCalendarBookings(ctx context.Context, vendorID string) ([]responses.CalendarBooking, error) {
+	var bookings []responses.CalendarBooking
+
+	err := r.DB.WithContext(ctx).
+		Table("bookings b").
+		Select(`
+			b.booking_id,
+			CONCAT_WS(' ', ud.first_name, ud.last_name) AS client_name,
+			b.service,
+			b.date,
+			b.status,
+			COALESCE((
+				SELECT s.service_duration FROM services s
+				WHERE s.vendor_id = b.vendor_id AND s.service_title = b.service
+				ORDER BY s.created_at DESC LIMIT 1
+			), 1) AS service_duration,
+			b.updated_at
+		`).
+		Joins("LEFT JOIN user_details ud ON b.client_id = ud.user_id").
+		Where("b.vendor_id = ? AND b.status IN ?", vendorID, []string{"approved", "pending"}).
+		Order("b.date ASC").
+		Scan(&bookings).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return bookings, nil
+}
@@ -0,0 +1,198 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/models"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/money"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// basisPointsPerWhole is the number of basis points in 100%, for commission
+// and tax rates.
+const basisPointsPerWhole = 10000
+
+var ErrCommissionRuleNotFound = errors.New("commission rule not found")
+
+// SaveCommissionRule creates the rule for its scope, or replaces the rate and
+// fee of the one already there.
+func (r *VendorStorage) SaveCommissionRule(ctx context.Context, rule *models.CommissionRule) error {
+	return r.DB.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "scope"}, {Name: "scope_key"}},
+			DoUpdates: clause.AssignmentColumns([]string{"rate_basis_points", "fixed_fee", "updated_at"}),
+		}).
+		Create(rule).Error
+}
+
+func (r *VendorStorage) ListCommissionRules(ctx context.Context) ([]models.CommissionRule, error) {
+	var rules []models.CommissionRule
+	err := r.DB.WithContext(ctx).Order("scope ASC, scope_key ASC").Find(&rules).Error
+	if err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+func (r *VendorStorage) DeleteCommissionRule(ctx context.Context, ruleID string) error {
+	result := r.DB.WithContext(ctx).Where("id = ?", ruleID).Delete(&models.CommissionRule{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrCommissionRuleNotFound
+	}
+	return nil
+}
+
+// SetVendorTier moves the vendor into tier. An empty tier takes the vendor
+// out of any tier.
+func (r *VendorStorage) SetVendorTier(ctx context.Context, vendorID uuid.UUID, tier string) error {
+	if tier == "" {
+		return r.DB.WithContext(ctx).Where("vendor_id = ?", vendorID).Delete(&models.VendorTier{}).Error
+	}
+
+	return r.DB.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "vendor_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"tier", "updated_at"}),
+		}).
+		Create(&models.VendorTier{VendorID: vendorID, Tier: tier}).Error
+}
+
+// GetPayoutCommissions returns the commission split behind each of the given
+// vendor transactions that was a payout.
+func (r *VendorStorage) GetPayoutCommissions(ctx context.Context, transactionIDs []uuid.UUID) ([]models.PayoutCommission, error) {
+	var commissions []models.PayoutCommission
+	if len(transactionIDs) == 0 {
+		return commissions, nil
+	}
+
+	err := r.DB.WithContext(ctx).Where("transaction_id IN ?", transactionIDs).Find(&commissions).Error
+	if err != nil {
+		return nil, err
+	}
+	return commissions, nil
+}
+
+// commissionRuleForVendor returns the rule that applies to the vendor, as
+// chosen by pickCommissionRule, or nil when no rule applies.
+func commissionRuleForVendor(tx *gorm.DB, vendorID uuid.UUID) (*models.CommissionRule, error) {
+	var rules []models.CommissionRule
+	err := tx.Raw(`
+		SELECT cr.* FROM commission_rules cr
+		WHERE cr.scope = ?
+			OR (cr.scope = ? AND cr.scope_key IN (SELECT category_id::text FROM vendor_categories WHERE vendor_id = ?))
+			OR (cr.scope = ? AND cr.scope_key IN (SELECT tier FROM vendor_tiers WHERE vendor_id = ?))
+	`, models.CommissionScopeGlobal,
+		models.CommissionScopeCategory, vendorID,
+		models.CommissionScopeTier, vendorID).
+		Scan(&rules).Error
+	if err != nil {
+		return nil, err
+	}
+	return pickCommissionRule(rules), nil
+}
+
+// commissionScopeRank orders the scopes from the most specific.
+var commissionScopeRank = map[string]int{
+	models.CommissionScopeCategory: 0,
+	models.CommissionScopeTier:     1,
+	models.CommissionScopeGlobal:   2,
+}
+
+// pickCommissionRule chooses among the rules that apply to a vendor. A
+// category rule wins over a tier rule, which wins over the global rule. A
+// vendor in several categories with rules pays the highest rate among them,
+// then the highest fixed fee, so adding a category never lowers the
+// commission and the choice does not depend on the order rules are read in.
+func pickCommissionRule(rules []models.CommissionRule) *models.CommissionRule {
+	var best *models.CommissionRule
+	for i := range rules {
+		rule := &rules[i]
+		rank, ok := commissionScopeRank[rule.Scope]
+		if !ok {
+			continue
+		}
+		if best == nil {
+			best = rule
+			continue
+		}
+
+		bestRank := commissionScopeRank[best.Scope]
+		switch {
+		case rank != bestRank:
+			if rank < bestRank {
+				best = rule
+			}
+		case rule.RateBasisPoints != best.RateBasisPoints:
+			if rule.RateBasisPoints > best.RateBasisPoints {
+				best = rule
+			}
+		case rule.FixedFee.Amount != best.FixedFee.Amount:
+			if rule.FixedFee.Amount > best.FixedFee.Amount {
+				best = rule
+			}
+		case rule.ScopeKey < best.ScopeKey:
+			best = rule
+		}
+	}
+	return best
+}
+
+// isFirstPayout reports whether nothing has been paid out for the booking
+// yet. A booking released in parts pays the rule's fixed fee only with its
+// first payout.
+func isFirstPayout(tx *gorm.DB, bookingID uuid.UUID) (bool, error) {
+	var paid int64
+	if err := tx.Model(&models.PayoutCommission{}).
+		Where("reference = ?", bookingID.String()).
+		Count(&paid).Error; err != nil {
+		return false, fmt.Errorf("failed to check earlier payouts: %w", err)
+	}
+	if paid > 0 {
+		return false, nil
+	}
+
+	if err := tx.Model(&models.SettlementLine{}).
+		Where("booking_id = ?", bookingID).
+		Count(&paid).Error; err != nil {
+		return false, fmt.Errorf("failed to check earlier settlements: %w", err)
+	}
+	return paid == 0, nil
+}
+
+// splitCommission works out the platform's fee on a payout of gross. The
+// rule's fixed fee is added only when withFixedFee is set, for the first
+// payout of a booking. The fee is truncated to whole units, because the
+// vendor's share lands in the wallet, and never exceeds the payout.
+func splitCommission(gross money.Money, rule *models.CommissionRule, withFixedFee bool) (fee, net money.Money, err error) {
+	fee = money.Zero()
+	if rule != nil {
+		if fee, err = gross.MulDiv(int64(rule.RateBasisPoints), basisPointsPerWhole); err != nil {
+			return money.Money{}, money.Money{}, err
+		}
+		if withFixedFee {
+			if fee, err = fee.Add(rule.FixedFee); err != nil {
+				return money.Money{}, money.Money{}, err
+			}
+		}
+		fee = fee.TruncateMajor()
+	}
+
+	if over, err := gross.LessThan(fee); err != nil {
+		return money.Money{}, money.Money{}, err
+	} else if over {
+		fee = gross
+	}
+
+	net, err = gross.Sub(fee)
+	if err != nil {
+		return money.Money{}, money.Money{}, err
+	}
+	return fee, net, nil
+}
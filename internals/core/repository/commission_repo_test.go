@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/models"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/money"
+)
+
+func TestSplitCommission(t *testing.T) {
+	rule := func(basisPoints int, fixedFee int64) *models.CommissionRule {
+		return &models.CommissionRule{RateBasisPoints: basisPoints, FixedFee: money.New(fixedFee)}
+	}
+
+	tests := []struct {
+		name         string
+		gross        int64
+		rule         *models.CommissionRule
+		withFixedFee bool
+		wantFee      int64
+	}{
+		{name: "no rule", gross: 100000, wantFee: 0},
+		{name: "rate only", gross: 100000, rule: rule(1000, 0), withFixedFee: true, wantFee: 10000},
+		{name: "rate and fixed fee", gross: 100000, rule: rule(1000, 5000), withFixedFee: true, wantFee: 15000},
+		{name: "fixed fee already charged", gross: 100000, rule: rule(1000, 5000), withFixedFee: false, wantFee: 10000},
+		{name: "truncated to whole units", gross: 99900, rule: rule(250, 0), withFixedFee: true, wantFee: 2400},
+		{name: "fractional fixed fee truncated", gross: 100000, rule: rule(0, 1050), withFixedFee: true, wantFee: 1000},
+		{name: "fee capped at the payout", gross: 3000, rule: rule(500, 5000), withFixedFee: true, wantFee: 3000},
+		{name: "nothing to pay", gross: 0, rule: rule(1000, 5000), withFixedFee: true, wantFee: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gross := money.New(tt.gross)
+			fee, net, err := splitCommission(gross, tt.rule, tt.withFixedFee)
+			if err != nil {
+				t.Fatalf("splitCommission: %v", err)
+			}
+			if fee != money.New(tt.wantFee) {
+				t.Errorf("fee = %s, want %s", fee, money.New(tt.wantFee))
+			}
+			if sum, _ := fee.Add(net); sum != gross {
+				t.Errorf("fee %s and net %s add up to %s, want %s", fee, net, sum, gross)
+			}
+		})
+	}
+}
+
+func TestPickCommissionRule(t *testing.T) {
+	global := models.CommissionRule{Scope: models.CommissionScopeGlobal, RateBasisPoints: 1500}
+	tier := models.CommissionRule{Scope: models.CommissionScopeTier, ScopeKey: "gold", RateBasisPoints: 800}
+	photography := models.CommissionRule{Scope: models.CommissionScopeCategory, ScopeKey: "a", RateBasisPoints: 1000}
+	catering := models.CommissionRule{Scope: models.CommissionScopeCategory, ScopeKey: "b", RateBasisPoints: 1200}
+	decor := models.CommissionRule{Scope: models.CommissionScopeCategory, ScopeKey: "c", RateBasisPoints: 1200, FixedFee: money.New(5000)}
+	music := models.CommissionRule{Scope: models.CommissionScopeCategory, ScopeKey: "d", RateBasisPoints: 1200, FixedFee: money.New(5000)}
+	unknown := models.CommissionRule{Scope: "region", ScopeKey: "south", RateBasisPoints: 9000}
+
+	tests := []struct {
+		name  string
+		rules []models.CommissionRule
+		want  string
+	}{
+		{name: "no rules"},
+		{name: "global only", rules: []models.CommissionRule{global}, want: "global/"},
+		{name: "tier beats global", rules: []models.CommissionRule{global, tier}, want: "tier/gold"},
+		{name: "category beats tier", rules: []models.CommissionRule{tier, photography, global}, want: "category/a"},
+		{name: "highest category rate", rules: []models.CommissionRule{photography, catering}, want: "category/b"},
+		{name: "highest category rate in any order", rules: []models.CommissionRule{catering, photography}, want: "category/b"},
+		{name: "then highest fixed fee", rules: []models.CommissionRule{catering, decor}, want: "category/c"},
+		{name: "then scope key", rules: []models.CommissionRule{music, decor}, want: "category/c"},
+		{name: "unknown scope ignored", rules: []models.CommissionRule{unknown, global}, want: "global/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			if rule := pickCommissionRule(tt.rules); rule != nil {
+				got = rule.Scope + "/" + rule.ScopeKey
+			}
+			if got != tt.want {
+				t.Errorf("picked %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,202 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	adminModel "github.com/AthulKrishna2501/zyra-admin-service/internals/core/models"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/models"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/money"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrDisputeWindowClosed = errors.New("dispute window is closed")
+	ErrDisputeNotOpen      = errors.New("dispute is already resolved")
+	ErrRefundExceedsHeld   = errors.New("refund must be less than the amount held")
+)
+
+// MarkBookingCompleted records the completion and moves the booking to
+// completed. A booking completed before fails with gorm.ErrDuplicatedKey.
+func (r *VendorStorage) MarkBookingCompleted(ctx context.Context, completion *models.BookingCompletion, event *models.BookingEvent) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(completion).Error; err != nil {
+			return fmt.Errorf("failed to create booking completion: %w", err)
+		}
+
+		return transitionBookingStatus(tx, completion.BookingID.String(), []string{"approved"}, "completed", event)
+	})
+}
+
+func (r *VendorStorage) GetBookingCompletion(ctx context.Context, bookingID string) (*models.BookingCompletion, error) {
+	var completion models.BookingCompletion
+	err := r.DB.WithContext(ctx).Where("booking_id = ?", bookingID).First(&completion).Error
+	if err != nil {
+		return nil, err
+	}
+	return &completion, nil
+}
+
+func (r *VendorStorage) OpenDispute(ctx context.Context, dispute *models.BookingDispute, event *models.BookingEvent) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.BookingCompletion{}).
+			Where("booking_id = ? AND payout_status = ? AND dispute_window_ends_at > ?", dispute.BookingID, "awaiting_release", time.Now()).
+			Update("payout_status", "disputed")
+		if result.Error != nil {
+			return fmt.Errorf("failed to freeze payout: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrDisputeWindowClosed
+		}
+
+		if err := tx.Create(dispute).Error; err != nil {
+			return fmt.Errorf("failed to create dispute: %w", err)
+		}
+
+		if err := transitionBookingStatus(tx, dispute.BookingID.String(), []string{"completed"}, "disputed", event); err != nil {
+			return err
+		}
+
+		return syncBookingHolds(tx, dispute.BookingID)
+	})
+}
+
+func (r *VendorStorage) GetDisputeByID(ctx context.Context, disputeID string) (*models.BookingDispute, error) {
+	var dispute models.BookingDispute
+	err := r.DB.WithContext(ctx).Where("id = ?", disputeID).First(&dispute).Error
+	if err != nil {
+		return nil, err
+	}
+	return &dispute, nil
+}
+
+// ResolveDispute settles an open dispute in one transaction: the client is
+// refunded according to the resolution, the rest of the held amount,
+// including paid overtime, is queued for the vendor's next settlement, and the
+// booking leaves the disputed status. For a partial refund, refundAmount must
+// be less than the amount held; it is ignored otherwise. The event is
+// recorded with the booking's status change.
+func (r *VendorStorage) ResolveDispute(ctx context.Context, disputeID string, resolution string, refundAmount money.Money, resolvedBy uuid.UUID, event *models.BookingEvent) (*models.BookingDispute, error) {
+	var dispute models.BookingDispute
+
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", disputeID).
+			First(&dispute).Error; err != nil {
+			return err
+		}
+		if dispute.Status != "open" {
+			return ErrDisputeNotOpen
+		}
+
+		var booking adminModel.Booking
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("booking_id = ?", dispute.BookingID).
+			First(&booking).Error; err != nil {
+			return fmt.Errorf("failed to find booking: %w", err)
+		}
+
+		// Overtime is collected under the completion's lock, so holding it
+		// means no charge can join the booking while it is paid out.
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("booking_id = ?", booking.BookingID).
+			First(&models.BookingCompletion{}).Error; err != nil {
+			return fmt.Errorf("failed to find booking completion: %w", err)
+		}
+
+		heldAmount, held, err := heldBookingFunds(tx, &booking)
+		if err != nil {
+			return err
+		}
+		overtimeAmount, overtime, err := heldOvertimeCharges(tx, booking.BookingID)
+		if err != nil {
+			return err
+		}
+		if heldAmount, err = heldAmount.Add(overtimeAmount); err != nil {
+			return err
+		}
+
+		switch resolution {
+		case "full_refund":
+			refundAmount = heldAmount
+		case "partial_refund":
+			if below, err := refundAmount.LessThan(heldAmount); err != nil || !below {
+				return fmt.Errorf("%w: %s held", ErrRefundExceedsHeld, heldAmount)
+			}
+		default:
+			refundAmount = money.Zero()
+		}
+
+		payout, err := heldAmount.Sub(refundAmount)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if refundAmount.IsPositive() {
+			if err := r.refundFromEscrow(tx, booking.ClientID, booking.BookingID, refundAmount, now); err != nil {
+				return err
+			}
+		}
+
+		payoutStatus := "refunded"
+		if payout.IsPositive() {
+			description := fmt.Sprintf("%s on %s, after dispute", booking.Service, booking.Date.Format("02 Jan 2006"))
+			if err := queuePayout(tx, booking.VendorID, booking.BookingID, models.QueuedPayoutDispute, description, payout); err != nil {
+				return err
+			}
+			if err := releaseInstallments(tx, installmentIDs(held), now); err != nil {
+				return fmt.Errorf("failed to release installments: %w", err)
+			}
+			if err := releaseOvertimeCharges(tx, overtimeChargeIDs(overtime), now); err != nil {
+				return fmt.Errorf("failed to release overtime charges: %w", err)
+			}
+			if err := tx.Model(&booking).Updates(map[string]interface{}{
+				"is_vendor_approved": true,
+				"is_client_approved": true,
+				"updated_at":         now,
+			}).Error; err != nil {
+				return fmt.Errorf("failed to update booking: %w", err)
+			}
+			payoutStatus = "released"
+		} else {
+			if err := refundInstallments(tx, installmentIDs(held)); err != nil {
+				return fmt.Errorf("failed to refund installments: %w", err)
+			}
+			if err := refundOvertimeCharges(tx, overtimeChargeIDs(overtime)); err != nil {
+				return fmt.Errorf("failed to refund overtime charges: %w", err)
+			}
+		}
+
+		dispute.Status = "resolved"
+		dispute.Resolution = resolution
+		dispute.RefundAmount = refundAmount
+		dispute.ResolvedBy = &resolvedBy
+		dispute.ResolvedAt = &now
+		if err := tx.Save(&dispute).Error; err != nil {
+			return fmt.Errorf("failed to update dispute: %w", err)
+		}
+
+		if err := tx.Model(&models.BookingCompletion{}).
+			Where("booking_id = ?", dispute.BookingID).
+			Update("payout_status", payoutStatus).Error; err != nil {
+			return fmt.Errorf("failed to update payout status: %w", err)
+		}
+
+		event.Reason = fmt.Sprintf("Refunded %s of %s", refundAmount, heldAmount)
+		if err := transitionBookingStatus(tx, dispute.BookingID.String(), []string{"disputed"}, "completed", event); err != nil {
+			return err
+		}
+
+		return syncFundHolds(tx, booking.VendorID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &dispute, nil
+}
@@ -0,0 +1,248 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	adminModel "github.com/AthulKrishna2501/zyra-admin-service/internals/core/models"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/models"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/money"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// unheldBookingStatuses are the booking statuses whose payment has gone back
+// to the client, so nothing is held for the vendor.
+var unheldBookingStatuses = []string{"rejected", "cancelled", "expired"}
+
+// inFlightWithdrawalStatuses are the withdrawal statuses whose amount has
+// left the wallet but not yet reached the vendor.
+var inFlightWithdrawalStatuses = []string{"pending", "processing"}
+
+// ListFundHolds returns the vendor's holds still held, oldest first. Holds
+// are only changed when money moves, so reading them changes nothing.
+func (r *VendorStorage) ListFundHolds(ctx context.Context, vendorID uuid.UUID) ([]models.FundHold, error) {
+	var holds []models.FundHold
+	err := r.DB.WithContext(ctx).
+		Where("vendor_id = ? AND released_at IS NULL", vendorID).
+		Order("created_at ASC").
+		Find(&holds).Error
+	if err != nil {
+		return nil, err
+	}
+	return holds, nil
+}
+
+// syncFundHolds works out what is held for the vendor from their bookings,
+// queued payouts and withdrawals and brings their open holds in line with it.
+// Every transaction that moves the vendor's money calls it before committing.
+// Released holds are never reopened; a source that holds money again gets a
+// fresh hold.
+func syncFundHolds(tx *gorm.DB, vendorID uuid.UUID) error {
+	holds, err := bookingHolds(tx, vendorID)
+	if err != nil {
+		return err
+	}
+
+	var queued []models.QueuedPayout
+	if err := tx.Where("vendor_id = ? AND settlement_id IS NULL", vendorID).
+		Find(&queued).Error; err != nil {
+		return fmt.Errorf("failed to fetch queued payouts: %w", err)
+	}
+	for _, payout := range queued {
+		holds = append(holds, models.FundHold{
+			VendorID:   vendorID,
+			Kind:       models.FundHoldEscrow,
+			SourceType: "queued_payout",
+			SourceID:   payout.ID.String(),
+			Amount:     payout.Amount,
+		})
+	}
+
+	var withdrawals []models.Withdrawal
+	if err := tx.Where("vendor_id = ? AND status IN ?", vendorID, inFlightWithdrawalStatuses).
+		Find(&withdrawals).Error; err != nil {
+		return fmt.Errorf("failed to fetch withdrawals: %w", err)
+	}
+	for _, withdrawal := range withdrawals {
+		holds = append(holds, models.FundHold{
+			VendorID:   vendorID,
+			Kind:       models.FundHoldWithdrawal,
+			SourceType: "withdrawal",
+			SourceID:   withdrawal.ID.String(),
+			Amount:     withdrawal.Amount,
+		})
+	}
+
+	var open []models.FundHold
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("vendor_id = ? AND released_at IS NULL", vendorID).
+		Find(&open).Error; err != nil {
+		return fmt.Errorf("failed to fetch fund holds: %w", err)
+	}
+
+	create, update, release := planFundHolds(open, holds)
+	for i := range create {
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&create[i]).Error; err != nil {
+			return fmt.Errorf("failed to save fund hold: %w", err)
+		}
+	}
+	for _, hold := range update {
+		if err := tx.Model(&models.FundHold{}).
+			Where("id = ? AND released_at IS NULL", hold.ID).
+			Updates(map[string]interface{}{"kind": hold.Kind, "amount": hold.Amount}).Error; err != nil {
+			return fmt.Errorf("failed to update fund hold: %w", err)
+		}
+	}
+	if len(release) > 0 {
+		if err := tx.Model(&models.FundHold{}).
+			Where("id IN ? AND released_at IS NULL", release).
+			Update("released_at", time.Now()).Error; err != nil {
+			return fmt.Errorf("failed to release fund holds: %w", err)
+		}
+	}
+	return nil
+}
+
+// syncBookingHolds is syncFundHolds for the vendor of a booking.
+func syncBookingHolds(tx *gorm.DB, bookingID uuid.UUID) error {
+	var booking adminModel.Booking
+	if err := tx.Select("vendor_id").Where("booking_id = ?", bookingID).First(&booking).Error; err != nil {
+		return fmt.Errorf("failed to find booking: %w", err)
+	}
+	return syncFundHolds(tx, booking.VendorID)
+}
+
+// planFundHolds compares a vendor's open holds with the holds their money
+// calls for, matching them by source. It returns the holds to open, the open
+// holds whose kind or amount changed, with the new values, and the IDs of open
+// holds nothing backs any more.
+func planFundHolds(open, want []models.FundHold) (create, update []models.FundHold, release []uuid.UUID) {
+	source := func(hold models.FundHold) string {
+		return hold.SourceType + ":" + hold.SourceID
+	}
+
+	current := make(map[string]models.FundHold, len(open))
+	for _, hold := range open {
+		current[source(hold)] = hold
+	}
+
+	wanted := make(map[string]bool, len(want))
+	for _, hold := range want {
+		wanted[source(hold)] = true
+
+		existing, ok := current[source(hold)]
+		switch {
+		case !ok:
+			create = append(create, hold)
+		case existing.Kind != hold.Kind || existing.Amount != hold.Amount:
+			existing.Kind = hold.Kind
+			existing.Amount = hold.Amount
+			update = append(update, existing)
+		}
+	}
+
+	for _, hold := range open {
+		if !wanted[source(hold)] {
+			release = append(release, hold.ID)
+		}
+	}
+	return create, update, release
+}
+
+// bookingHolds returns a hold for each of the vendor's bookings with money in
+// escrow: the paid installments not yet released, or the full price when the
+// booking has no payment schedule, plus any paid overtime. A disputed
+// booking's hold is a dispute freeze until the dispute is resolved and the
+// money paid out or refunded.
+func bookingHolds(tx *gorm.DB, vendorID uuid.UUID) ([]models.FundHold, error) {
+	var bookings []struct {
+		BookingID    uuid.UUID
+		Price        int
+		PayoutStatus string
+	}
+	err := tx.Model(&adminModel.Booking{}).
+		Select("bookings.booking_id, bookings.price, COALESCE(c.payout_status, '') AS payout_status").
+		Joins("LEFT JOIN booking_completions c ON c.booking_id = bookings.booking_id").
+		Where("bookings.vendor_id = ? AND bookings.is_fund_released = ? AND bookings.status NOT IN ?", vendorID, false, unheldBookingStatuses).
+		Where("c.payout_status IS NULL OR c.payout_status NOT IN ?", []string{"released", "refunded"}).
+		Scan(&bookings).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch held bookings: %w", err)
+	}
+	if len(bookings) == 0 {
+		return nil, nil
+	}
+
+	bookingIDs := make([]uuid.UUID, 0, len(bookings))
+	for _, booking := range bookings {
+		bookingIDs = append(bookingIDs, booking.BookingID)
+	}
+
+	var schedules []struct {
+		BookingID uuid.UUID
+		Held      money.Money
+	}
+	err = tx.Model(&models.BookingInstallment{}).
+		Select("booking_id, COALESCE(SUM(amount) FILTER (WHERE status = 'paid' AND released_at IS NULL), 0) AS held").
+		Where("booking_id IN ?", bookingIDs).
+		Group("booking_id").
+		Scan(&schedules).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum held installments: %w", err)
+	}
+	scheduled := make(map[uuid.UUID]money.Money, len(schedules))
+	for _, schedule := range schedules {
+		scheduled[schedule.BookingID] = schedule.Held
+	}
+
+	var overtimes []struct {
+		BookingID uuid.UUID
+		Held      money.Money
+	}
+	err = tx.Model(&models.OvertimeCharge{}).
+		Select("booking_id, SUM(amount) AS held").
+		Where("booking_id IN ? AND status = ? AND released_at IS NULL", bookingIDs, "paid").
+		Group("booking_id").
+		Scan(&overtimes).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum held overtime: %w", err)
+	}
+	overtime := make(map[uuid.UUID]money.Money, len(overtimes))
+	for _, charge := range overtimes {
+		overtime[charge.BookingID] = charge.Held
+	}
+
+	var holds []models.FundHold
+	for _, booking := range bookings {
+		amount, ok := scheduled[booking.BookingID]
+		if !ok {
+			if amount, err = money.FromMajor(int64(booking.Price)); err != nil {
+				return nil, fmt.Errorf("invalid price for booking %s: %w", booking.BookingID, err)
+			}
+		}
+		if extra, ok := overtime[booking.BookingID]; ok {
+			if amount, err = amount.Add(extra); err != nil {
+				return nil, err
+			}
+		}
+		if !amount.IsPositive() {
+			continue
+		}
+
+		kind := models.FundHoldEscrow
+		if booking.PayoutStatus == "disputed" {
+			kind = models.FundHoldDispute
+		}
+		holds = append(holds, models.FundHold{
+			VendorID:   vendorID,
+			Kind:       kind,
+			SourceType: "booking",
+			SourceID:   booking.BookingID.String(),
+			Amount:     amount,
+		})
+	}
+	return holds, nil
+}
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/models"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/money"
+	"github.com/google/uuid"
+)
+
+func TestPlanFundHolds(t *testing.T) {
+	bookingHold := models.FundHold{ID: uuid.New(), Kind: models.FundHoldEscrow, SourceType: "booking", SourceID: "b1", Amount: money.New(100000)}
+	withdrawalHold := models.FundHold{ID: uuid.New(), Kind: models.FundHoldWithdrawal, SourceType: "withdrawal", SourceID: "w1", Amount: money.New(20000)}
+	want := func(hold models.FundHold, edit func(*models.FundHold)) models.FundHold {
+		hold.ID = uuid.Nil
+		if edit != nil {
+			edit(&hold)
+		}
+		return hold
+	}
+
+	tests := []struct {
+		name        string
+		open        []models.FundHold
+		want        []models.FundHold
+		wantCreate  []string
+		wantUpdate  []string
+		wantRelease []uuid.UUID
+	}{
+		{name: "nothing held"},
+		{
+			name:       "new holds",
+			want:       []models.FundHold{want(bookingHold, nil), want(withdrawalHold, nil)},
+			wantCreate: []string{"b1", "w1"},
+		},
+		{
+			name: "unchanged holds are left alone",
+			open: []models.FundHold{bookingHold, withdrawalHold},
+			want: []models.FundHold{want(bookingHold, nil), want(withdrawalHold, nil)},
+		},
+		{
+			name:       "amount changed",
+			open:       []models.FundHold{bookingHold},
+			want:       []models.FundHold{want(bookingHold, func(h *models.FundHold) { h.Amount = money.New(60000) })},
+			wantUpdate: []string{"b1"},
+		},
+		{
+			name:       "disputed booking",
+			open:       []models.FundHold{bookingHold},
+			want:       []models.FundHold{want(bookingHold, func(h *models.FundHold) { h.Kind = models.FundHoldDispute })},
+			wantUpdate: []string{"b1"},
+		},
+		{
+			name:        "money that left is released",
+			open:        []models.FundHold{bookingHold, withdrawalHold},
+			want:        []models.FundHold{want(withdrawalHold, nil)},
+			wantRelease: []uuid.UUID{bookingHold.ID},
+		},
+		{
+			name: "same source ID under another type is a different hold",
+			open: []models.FundHold{bookingHold},
+			want: []models.FundHold{want(bookingHold, func(h *models.FundHold) {
+				h.SourceType = "queued_payout"
+			})},
+			wantCreate:  []string{"b1"},
+			wantRelease: []uuid.UUID{bookingHold.ID},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			create, update, release := planFundHolds(tt.open, tt.want)
+
+			if got := holdSources(create); !equalStrings(got, tt.wantCreate) {
+				t.Errorf("create = %v, want %v", got, tt.wantCreate)
+			}
+			if got := holdSources(update); !equalStrings(got, tt.wantUpdate) {
+				t.Errorf("update = %v, want %v", got, tt.wantUpdate)
+			}
+			for _, hold := range update {
+				if hold.ID == uuid.Nil {
+					t.Errorf("update of %s has no hold ID", hold.SourceID)
+				}
+				for _, wanted := range tt.want {
+					if wanted.SourceType == hold.SourceType && wanted.SourceID == hold.SourceID && (wanted.Kind != hold.Kind || wanted.Amount != hold.Amount) {
+						t.Errorf("update of %s = %s %s, want %s %s", hold.SourceID, hold.Kind, hold.Amount, wanted.Kind, wanted.Amount)
+					}
+				}
+			}
+			if len(release) != len(tt.wantRelease) {
+				t.Fatalf("release = %v, want %v", release, tt.wantRelease)
+			}
+			for i := range release {
+				if release[i] != tt.wantRelease[i] {
+					t.Errorf("release = %v, want %v", release, tt.wantRelease)
+				}
+			}
+		})
+	}
+}
+
+func holdSources(holds []models.FundHold) []string {
+	var sources []string
+	for _, hold := range holds {
+		sources = append(sources, hold.SourceID)
+	}
+	return sources
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	adminModel "github.com/AthulKrishna2501/zyra-admin-service/internals/core/models"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func (r *VendorStorage) GetServiceByID(ctx context.Context, serviceID string) (*models.Service, error) {
+	var service models.Service
+	err := r.DB.WithContext(ctx).Where("id = ?", serviceID).First(&service).Error
+	if err != nil {
+		return nil, err
+	}
+	return &service, nil
+}
+
+func (r *VendorStorage) GetInstantBookingRules(ctx context.Context, serviceID uuid.UUID) ([]models.InstantBookingRule, error) {
+	var rules []models.InstantBookingRule
+	err := r.DB.WithContext(ctx).Where("service_id = ?", serviceID).Order("created_at ASC").Find(&rules).Error
+	if err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+func (r *VendorStorage) ReplaceInstantBookingRules(ctx context.Context, serviceID uuid.UUID, rules []models.InstantBookingRule) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("service_id = ?", serviceID).Delete(&models.InstantBookingRule{}).Error; err != nil {
+			return err
+		}
+
+		if len(rules) == 0 {
+			return nil
+		}
+		return tx.Create(&rules).Error
+	})
+}
+
+// CountBookingsOnDate counts the bookings holding the vendor's day. Pending
+// bookings hold it too, so a date awaiting the vendor's decision is not open.
+func (r *VendorStorage) CountBookingsOnDate(ctx context.Context, vendorID uuid.UUID, date time.Time, excludeBookingID uuid.UUID) (int64, error) {
+	var count int64
+
+	dayStart := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+	err := r.DB.WithContext(ctx).
+		Model(&adminModel.Booking{}).
+		Where("vendor_id = ? AND booking_id <> ? AND status IN ? AND date >= ? AND date < ?",
+			vendorID, excludeBookingID, []string{"pending", "approved", "completed", "disputed"}, dayStart, dayStart.AddDate(0, 0, 1)).
+		Count(&count).Error
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
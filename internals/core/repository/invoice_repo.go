@@ -0,0 +1,363 @@
+package repository
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	adminModel "github.com/AthulKrishna2501/zyra-admin-service/internals/core/models"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/models"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/money"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// InvoiceFilter narrows the invoices a vendor or client can see. Exactly one
+// of VendorID and ClientID is set; clients only see receipts.
+type InvoiceFilter struct {
+	VendorID  uuid.UUID
+	ClientID  uuid.UUID
+	BookingID uuid.UUID
+}
+
+// invoiceSource names the payment or payout an invoice is issued for.
+type invoiceSource struct {
+	kind       string
+	sourceType string
+	sourceID   string
+	vendorID   uuid.UUID
+	clientID   uuid.UUID
+	bookingID  uuid.UUID
+}
+
+type invoiceParty struct {
+	FirstName string
+	LastName  string
+	Email     string
+	Phone     string
+}
+
+func (p invoiceParty) name() string {
+	return strings.TrimSpace(p.FirstName + " " + p.LastName)
+}
+
+func newInvoiceToken() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// issueInvoice numbers and stores the invoice for source inside tx, returning
+// the existing one if source was already invoiced. lines are the item,
+// discount and fee lines; tax lines are worked out from the vendor's
+// business profile.
+func issueInvoice(tx *gorm.DB, source invoiceSource, lines []models.InvoiceLine) (*models.Invoice, error) {
+	// Locking the vendor's sequence row serialises numbering and the
+	// already-issued check below.
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.InvoiceSequence{VendorID: source.vendorID}).Error; err != nil {
+		return nil, fmt.Errorf("failed to open invoice sequence: %w", err)
+	}
+	var sequence models.InvoiceSequence
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("vendor_id = ?", source.vendorID).
+		First(&sequence).Error; err != nil {
+		return nil, fmt.Errorf("failed to lock invoice sequence: %w", err)
+	}
+
+	var existing models.Invoice
+	err := tx.Where("source_type = ? AND source_id = ?", source.sourceType, source.sourceID).First(&existing).Error
+	if err == nil {
+		return &existing, nil
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	var profile models.VendorBusinessProfile
+	if err := tx.Where("vendor_id = ?", source.vendorID).Take(&profile).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	seller, err := findInvoiceParty(tx, source.vendorID)
+	if err != nil {
+		return nil, err
+	}
+
+	total := money.Zero()
+	taxable := money.Zero()
+	for i := range lines {
+		if total, err = total.Add(lines[i].Amount); err != nil {
+			return nil, err
+		}
+		if lines[i].Kind == models.InvoiceLineItem || lines[i].Kind == models.InvoiceLineDiscount {
+			if taxable, err = taxable.Add(lines[i].Amount); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// Prices include tax, so the tax is the rate's share of the taxable
+	// amount rather than an addition to it. Payouts are not a sale: the tax
+	// on what the client paid is on the receipt already.
+	taxTotal := money.Zero()
+	if profile.TaxRateBasisPoints > 0 && !taxable.IsZero() && source.kind != models.InvoiceKindPayout {
+		rate := int64(profile.TaxRateBasisPoints)
+		if taxTotal, err = taxable.MulDiv(rate, basisPointsPerWhole+rate); err != nil {
+			return nil, err
+		}
+
+		taxName := profile.TaxName
+		if taxName == "" {
+			taxName = "Tax"
+		}
+		lines = append(lines, models.InvoiceLine{
+			Kind:        models.InvoiceLineTax,
+			Description: fmt.Sprintf("%s at %s%% (included)", taxName, formatBasisPoints(profile.TaxRateBasisPoints)),
+			Quantity:    1,
+			UnitPrice:   taxTotal,
+			Amount:      taxTotal,
+		})
+	}
+
+	token, err := newInvoiceToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate invoice token: %w", err)
+	}
+
+	sellerName := profile.BusinessName
+	if sellerName == "" {
+		sellerName = seller.name()
+	}
+
+	sequence.LastNumber++
+	invoice := models.Invoice{
+		VendorID:      source.vendorID,
+		Sequence:      sequence.LastNumber,
+		Number:        fmt.Sprintf("%s-%06d", invoiceNumberPrefix(source.kind), sequence.LastNumber),
+		Kind:          source.kind,
+		SourceType:    source.sourceType,
+		SourceID:      source.sourceID,
+		AccessToken:   token,
+		SellerName:    sellerName,
+		SellerAddress: profile.BusinessAddress,
+		SellerTaxID:   profile.TaxID,
+		SellerEmail:   seller.Email,
+		SellerPhone:   seller.Phone,
+		TaxTotal:      taxTotal,
+		Total:         total,
+		IssuedAt:      time.Now(),
+	}
+	if source.bookingID != uuid.Nil {
+		invoice.BookingID = &source.bookingID
+	}
+	if source.clientID != uuid.Nil {
+		invoice.ClientID = &source.clientID
+
+		buyer, err := findInvoiceParty(tx, source.clientID)
+		if err != nil {
+			return nil, err
+		}
+		invoice.BuyerName = buyer.name()
+		invoice.BuyerEmail = buyer.Email
+	}
+
+	for i := range lines {
+		lines[i].Position = i + 1
+		if lines[i].Quantity == 0 {
+			lines[i].Quantity = 1
+		}
+	}
+	invoice.Lines = lines
+
+	if err := tx.Model(&sequence).Update("last_number", sequence.LastNumber).Error; err != nil {
+		return nil, fmt.Errorf("failed to advance invoice sequence: %w", err)
+	}
+	if err := tx.Create(&invoice).Error; err != nil {
+		return nil, fmt.Errorf("failed to create invoice: %w", err)
+	}
+	return &invoice, nil
+}
+
+func findInvoiceParty(tx *gorm.DB, userID uuid.UUID) (invoiceParty, error) {
+	var party invoiceParty
+	err := tx.Table("users").
+		Select("user_details.first_name, user_details.last_name, users.email, user_details.phone").
+		Joins("LEFT JOIN user_details ON user_details.user_id = users.user_id").
+		Where("users.user_id = ?", userID).
+		Scan(&party).Error
+	if err != nil {
+		return invoiceParty{}, fmt.Errorf("failed to fetch invoice party: %w", err)
+	}
+	return party, nil
+}
+
+// invoiceNumberPrefix keeps credit notes apart from invoices while numbering
+// both from the vendor's one sequence.
+func invoiceNumberPrefix(kind string) string {
+	if kind == models.InvoiceKindCreditNote {
+		return "CN"
+	}
+	return "INV"
+}
+
+// formatBasisPoints renders 1800 as "18" and 1250 as "12.5".
+func formatBasisPoints(basisPoints int) string {
+	return strconv.FormatFloat(float64(basisPoints)/100, 'f', -1, 64)
+}
+
+// singleItemLine is the line for a payment of one thing.
+func singleItemLine(description string, amount money.Money) models.InvoiceLine {
+	return models.InvoiceLine{
+		Kind:        models.InvoiceLineItem,
+		Description: description,
+		Quantity:    1,
+		UnitPrice:   amount,
+		Amount:      amount,
+	}
+}
+
+// issueBookingReceipt issues the receipt for what the client paid when the
+// booking was made through the client service: the installments paid up
+// front if the booking has a payment schedule, otherwise its full price.
+// Bookings paid here, through a quote or a bundle, already have their
+// receipt and get no other.
+func issueBookingReceipt(tx *gorm.DB, booking *adminModel.Booking) error {
+	var existing int64
+	if err := tx.Model(&models.Invoice{}).
+		Where("booking_id = ? AND kind = ?", booking.BookingID, models.InvoiceKindReceipt).
+		Count(&existing).Error; err != nil {
+		return fmt.Errorf("failed to check receipts: %w", err)
+	}
+	if err := tx.Model(&models.BundleItem{}).
+		Where("booking_id = ?", booking.BookingID).
+		Count(&existing).Error; err != nil {
+		return fmt.Errorf("failed to check bundle: %w", err)
+	}
+	if existing > 0 {
+		return nil
+	}
+
+	var installments []models.BookingInstallment
+	if err := tx.Where("booking_id = ? AND status IN ?", booking.BookingID, []string{"paid", "refunded"}).
+		Order("sequence ASC").
+		Find(&installments).Error; err != nil {
+		return err
+	}
+
+	var lines []models.InvoiceLine
+	for _, installment := range installments {
+		lines = append(lines, singleItemLine(fmt.Sprintf("%s, %s", booking.Service, installment.Label), installment.Amount))
+	}
+	if len(lines) == 0 {
+		price, err := models.BookingPrice(booking)
+		if err != nil {
+			return err
+		}
+		lines = append(lines, singleItemLine(booking.Service, price))
+	}
+
+	_, err := issueInvoice(tx, invoiceSource{
+		kind:       models.InvoiceKindReceipt,
+		sourceType: "booking",
+		sourceID:   booking.BookingID.String(),
+		vendorID:   booking.VendorID,
+		clientID:   booking.ClientID,
+		bookingID:  booking.BookingID,
+	}, lines)
+	return err
+}
+
+// issueRefundCreditNote issues the credit note for a refund to the client,
+// recorded as transactionID, reversing that much of the booking's receipts.
+func issueRefundCreditNote(tx *gorm.DB, booking *adminModel.Booking, transactionID uuid.UUID, amount money.Money) error {
+	credit, err := money.Zero().Sub(amount)
+	if err != nil {
+		return err
+	}
+
+	_, err = issueInvoice(tx, invoiceSource{
+		kind:       models.InvoiceKindCreditNote,
+		sourceType: "refund",
+		sourceID:   transactionID.String(),
+		vendorID:   booking.VendorID,
+		clientID:   booking.ClientID,
+		bookingID:  booking.BookingID,
+	}, []models.InvoiceLine{
+		singleItemLine(fmt.Sprintf("Refund, %s", booking.Service), credit),
+	})
+	return err
+}
+
+func (r *VendorStorage) GetInvoice(ctx context.Context, invoiceID string) (*models.Invoice, error) {
+	var invoice models.Invoice
+	err := r.DB.WithContext(ctx).
+		Preload("Lines", func(db *gorm.DB) *gorm.DB { return db.Order("position ASC") }).
+		Where("id = ?", invoiceID).
+		First(&invoice).Error
+	if err != nil {
+		return nil, err
+	}
+	return &invoice, nil
+}
+
+func (r *VendorStorage) GetInvoiceByToken(ctx context.Context, token string) (*models.Invoice, error) {
+	var invoice models.Invoice
+	err := r.DB.WithContext(ctx).
+		Preload("Lines", func(db *gorm.DB) *gorm.DB { return db.Order("position ASC") }).
+		Where("access_token = ?", token).
+		First(&invoice).Error
+	if err != nil {
+		return nil, err
+	}
+	return &invoice, nil
+}
+
+func (r *VendorStorage) ListInvoices(ctx context.Context, filter InvoiceFilter) ([]models.Invoice, error) {
+	query := r.DB.WithContext(ctx).Model(&models.Invoice{})
+
+	if filter.VendorID != uuid.Nil {
+		query = query.Where("vendor_id = ?", filter.VendorID)
+	}
+	if filter.ClientID != uuid.Nil {
+		query = query.Where("client_id = ? AND kind IN ?", filter.ClientID, models.ClientInvoiceKinds)
+	}
+	if filter.BookingID != uuid.Nil {
+		query = query.Where("booking_id = ?", filter.BookingID)
+	}
+
+	var invoices []models.Invoice
+	err := query.
+		Preload("Lines", func(db *gorm.DB) *gorm.DB { return db.Order("position ASC") }).
+		Order("issued_at DESC").
+		Find(&invoices).Error
+	if err != nil {
+		return nil, err
+	}
+	return invoices, nil
+}
+
+func (r *VendorStorage) GetVendorBusinessProfile(ctx context.Context, vendorID uuid.UUID) (*models.VendorBusinessProfile, error) {
+	profile := models.VendorBusinessProfile{VendorID: vendorID}
+	err := r.DB.WithContext(ctx).Where("vendor_id = ?", vendorID).Take(&profile).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+func (r *VendorStorage) SaveVendorBusinessProfile(ctx context.Context, profile *models.VendorBusinessProfile) error {
+	return r.DB.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "vendor_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"business_name", "business_address", "tax_id", "tax_name", "tax_rate_basis_points", "updated_at"}),
+		}).
+		Create(profile).Error
+}
@@ -0,0 +1,410 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/models"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/models/responses"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/money"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	LedgerOwnerClient   = models.WalletOwnerClient
+	LedgerOwnerVendor   = models.WalletOwnerVendor
+	LedgerOwnerPlatform = "platform"
+
+	ledgerCodeWallet         = "wallet"
+	ledgerCodeWithdrawalHold = "withdrawal_hold"
+	ledgerCodeEscrow         = "escrow"
+	ledgerCodeExternal       = "external"
+	ledgerCodeOpeningBalance = "opening_balance"
+	ledgerCodeRevenue        = "revenue"
+	ledgerCodeRefunds        = "refunds"
+)
+
+var ErrUnbalancedLedgerTransaction = errors.New("ledger transaction does not balance")
+
+type ledgerAccountRef struct {
+	OwnerType string
+	OwnerID   uuid.UUID
+	Code      string
+}
+
+func clientWalletAccount(clientID uuid.UUID) ledgerAccountRef {
+	return ledgerAccountRef{OwnerType: LedgerOwnerClient, OwnerID: clientID, Code: ledgerCodeWallet}
+}
+
+func vendorWalletAccount(vendorID uuid.UUID) ledgerAccountRef {
+	return ledgerAccountRef{OwnerType: LedgerOwnerVendor, OwnerID: vendorID, Code: ledgerCodeWallet}
+}
+
+func vendorWithdrawalHoldAccount(vendorID uuid.UUID) ledgerAccountRef {
+	return ledgerAccountRef{OwnerType: LedgerOwnerVendor, OwnerID: vendorID, Code: ledgerCodeWithdrawalHold}
+}
+
+func platformAccount(code string) ledgerAccountRef {
+	return ledgerAccountRef{OwnerType: LedgerOwnerPlatform, OwnerID: uuid.Nil, Code: code}
+}
+
+// normalSide is credit for balances the platform owes (wallets, holds,
+// escrow and refunds in flight) and for its revenue, and debit for the outside
+// world and opening balances.
+func (ref ledgerAccountRef) normalSide() string {
+	switch ref.Code {
+	case ledgerCodeWallet, ledgerCodeWithdrawalHold, ledgerCodeEscrow, ledgerCodeRevenue, ledgerCodeRefunds:
+		return "credit"
+	default:
+		return "debit"
+	}
+}
+
+// ledgerLeg moves Amount from one account to another: the From account is
+// debited and the To account credited.
+type ledgerLeg struct {
+	From   ledgerAccountRef
+	To     ledgerAccountRef
+	Amount money.Money
+}
+
+// ledgerAccount returns the account for ref, opening it on first use. The
+// second value reports whether this call created it.
+func ledgerAccount(tx *gorm.DB, ref ledgerAccountRef) (*models.LedgerAccount, bool, error) {
+	account := models.LedgerAccount{
+		OwnerType:  ref.OwnerType,
+		OwnerID:    ref.OwnerID,
+		Code:       ref.Code,
+		NormalSide: ref.normalSide(),
+	}
+
+	result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&account)
+	if result.Error != nil {
+		return nil, false, result.Error
+	}
+	if result.RowsAffected == 1 {
+		return &account, true, nil
+	}
+
+	err := tx.Where("owner_type = ? AND owner_id = ? AND code = ?", ref.OwnerType, ref.OwnerID, ref.Code).
+		First(&account).Error
+	if err != nil {
+		return nil, false, err
+	}
+	return &account, false, nil
+}
+
+// postLedger records one balanced ledger transaction inside tx. It must run
+// in the same database transaction as the wallet updates it describes.
+func postLedger(tx *gorm.DB, kind, reference, description string, legs ...ledgerLeg) error {
+	return postLedgerAt(tx, time.Now(), kind, reference, description, legs...)
+}
+
+// postLedgerAt is postLedger for a movement that is also recorded elsewhere
+// at a known time, such as a payout in the vendor's transactions. The ledger
+// entries carry the same time so the two can be lined up.
+func postLedgerAt(tx *gorm.DB, at time.Time, kind, reference, description string, legs ...ledgerLeg) error {
+	ledgerTxn := models.LedgerTransaction{
+		Kind:        kind,
+		Reference:   reference,
+		Description: description,
+		CreatedAt:   at,
+	}
+
+	for _, leg := range legs {
+		if !leg.Amount.IsPositive() {
+			return fmt.Errorf("%w: leg amount must be positive, got %s", ErrUnbalancedLedgerTransaction, leg.Amount)
+		}
+
+		from, _, err := ledgerAccount(tx, leg.From)
+		if err != nil {
+			return fmt.Errorf("failed to open ledger account: %w", err)
+		}
+		to, _, err := ledgerAccount(tx, leg.To)
+		if err != nil {
+			return fmt.Errorf("failed to open ledger account: %w", err)
+		}
+
+		ledgerTxn.Entries = append(ledgerTxn.Entries,
+			models.LedgerEntry{AccountID: from.ID, Direction: "debit", Amount: leg.Amount, CreatedAt: at},
+			models.LedgerEntry{AccountID: to.ID, Direction: "credit", Amount: leg.Amount, CreatedAt: at},
+		)
+	}
+
+	if len(ledgerTxn.Entries) == 0 {
+		return fmt.Errorf("%w: no entries", ErrUnbalancedLedgerTransaction)
+	}
+
+	if err := tx.Create(&ledgerTxn).Error; err != nil {
+		return fmt.Errorf("failed to record ledger transaction: %w", err)
+	}
+	return nil
+}
+
+// ledgerBalanceSQL computes an account's balance on its normal side.
+const ledgerBalanceSQL = `COALESCE(SUM(CASE WHEN e.direction = a.normal_side THEN e.amount ELSE -e.amount END), 0)`
+
+func (r *VendorStorage) GetLedgerStatement(ctx context.Context, ownerType string, ownerID uuid.UUID, limit int) (money.Money, []responses.LedgerStatementLine, error) {
+	db := r.DB.WithContext(ctx)
+
+	var balance money.Money
+	err := db.Table("ledger_accounts a").
+		Select(ledgerBalanceSQL).
+		Joins("LEFT JOIN ledger_entries e ON e.account_id = a.id").
+		Where("a.owner_type = ? AND a.owner_id = ? AND a.code = ?", ownerType, ownerID, ledgerCodeWallet).
+		Scan(&balance).Error
+	if err != nil {
+		return money.Money{}, nil, err
+	}
+
+	var lines []responses.LedgerStatementLine
+	err = db.Table("ledger_entries e").
+		Select(`
+			t.id AS transaction_id,
+			t.kind,
+			t.reference,
+			t.description,
+			e.direction,
+			e.amount,
+			e.created_at
+		`).
+		Joins("JOIN ledger_accounts a ON a.id = e.account_id").
+		Joins("JOIN ledger_transactions t ON t.id = e.transaction_id").
+		Where("a.owner_type = ? AND a.owner_id = ? AND a.code = ?", ownerType, ownerID, ledgerCodeWallet).
+		Order("e.created_at DESC").
+		Limit(limit).
+		Scan(&lines).Error
+	if err != nil {
+		return money.Money{}, nil, err
+	}
+
+	return balance, lines, nil
+}
+
+// ReconcileWallets lists wallets whose stored balance differs from the
+// balance derived from their ledger account. Wallets hold major units and the
+// ledger minor units, so wallet balances are scaled before comparing.
+func (r *VendorStorage) ReconcileWallets(ctx context.Context) ([]responses.WalletDiscrepancy, error) {
+	var discrepancies []responses.WalletDiscrepancy
+
+	err := r.DB.WithContext(ctx).Raw(`
+		WITH ledger AS (
+			SELECT a.owner_type, a.owner_id, `+ledgerBalanceSQL+` AS balance
+			FROM ledger_accounts a
+			LEFT JOIN ledger_entries e ON e.account_id = a.id
+			WHERE a.code = ?
+			GROUP BY a.owner_type, a.owner_id
+		)
+		SELECT w.id AS wallet_id, w.owner_type, w.owner_id,
+			w.wallet_balance * ? AS wallet_balance, COALESCE(l.balance, 0) AS ledger_balance
+		FROM wallets w
+		LEFT JOIN ledger l ON l.owner_type = w.owner_type AND l.owner_id = w.owner_id
+		WHERE w.owner_type IS NOT NULL AND w.wallet_balance * ? <> COALESCE(l.balance, 0)
+		ORDER BY w.owner_type, w.owner_id`, ledgerCodeWallet, money.MinorPerMajor, money.MinorPerMajor).
+		Scan(&discrepancies).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return discrepancies, nil
+}
+
+// MergeDuplicateClientWallets folds the client wallets repairWallets left
+// without an owner into the client's owned wallet and deletes them, so the
+// client service only ever finds one wallet per client. The merged balance is
+// posted to the ledger as carried over, keeping the owned wallet reconciled.
+// It returns how many wallets were merged.
+func (r *VendorStorage) MergeDuplicateClientWallets(ctx context.Context) (int, error) {
+	merged := 0
+
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var duplicates []models.Wallet
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where(`owner_id IS NULL AND client_id IS NOT NULL AND client_id <> ? AND EXISTS (
+				SELECT 1 FROM wallets o WHERE o.owner_type = ? AND o.owner_id = wallets.client_id
+			)`, uuid.Nil, models.WalletOwnerClient).
+			Order("created_at ASC").
+			Find(&duplicates).Error; err != nil {
+			return err
+		}
+
+		for _, duplicate := range duplicates {
+			owner := clientWalletOwner(duplicate.ClientID)
+			if _, err := lockWallet(tx, owner); err != nil {
+				return fmt.Errorf("failed to lock client wallet: %w", err)
+			}
+
+			if err := owner.query(tx).Updates(map[string]interface{}{
+				"wallet_balance":    gorm.Expr("wallet_balance + ?", duplicate.WalletBalance),
+				"total_deposits":    gorm.Expr("total_deposits + ?", duplicate.TotalDeposits),
+				"total_withdrawals": gorm.Expr("total_withdrawals + ?", duplicate.TotalWithdrawals),
+			}).Error; err != nil {
+				return fmt.Errorf("failed to merge client wallet: %w", err)
+			}
+
+			if duplicate.WalletBalance != 0 {
+				balance, err := duplicate.Balance()
+				if err != nil {
+					return err
+				}
+				ref := clientWalletAccount(duplicate.ClientID)
+				leg := ledgerLeg{From: platformAccount(ledgerCodeOpeningBalance), To: ref, Amount: balance}
+				if balance.IsNegative() {
+					leg = ledgerLeg{From: ref, To: platformAccount(ledgerCodeOpeningBalance), Amount: money.New(-balance.Amount)}
+				}
+				if err := postLedger(tx, "wallet_merge", duplicate.ID.String(), "Balance merged from duplicate wallet", leg); err != nil {
+					return err
+				}
+			}
+
+			if err := tx.Delete(&models.Wallet{}, "id = ?", duplicate.ID).Error; err != nil {
+				return fmt.Errorf("failed to delete merged wallet: %w", err)
+			}
+			merged++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return merged, nil
+}
+
+// ledgerBackfillLock is the advisory lock key that keeps replicas starting
+// together from backfilling opening balances at the same time.
+const ledgerBackfillLock = 0x6c65646765720001
+
+// BackfillLedgerOpeningBalances gives every wallet without an opening balance
+// entry one for whatever its stored balance holds that its ledger account does
+// not, that is the balance it had before the ledger existed. A wallet whose
+// ledger already matches gets an empty opening transaction so it is not
+// considered again. Treasury wallets are seeded the same way against their
+// ledger accounts, so the balance the client service collected into the escrow
+// wallet before the treasury existed shows up in the ledger. The backfill holds
+// an advisory lock, and each wallet is locked and seeded in its own
+// transaction. It returns how many wallets got an opening balance.
+func (r *VendorStorage) BackfillLedgerOpeningBalances(ctx context.Context) (int, error) {
+	seeded := 0
+
+	err := r.DB.WithContext(ctx).Connection(func(conn *gorm.DB) error {
+		if err := conn.Exec(`SELECT pg_advisory_lock(?)`, ledgerBackfillLock).Error; err != nil {
+			return fmt.Errorf("failed to take backfill lock: %w", err)
+		}
+		defer conn.Exec(`SELECT pg_advisory_unlock(?)`, ledgerBackfillLock)
+
+		var walletIDs []uuid.UUID
+		if err := conn.Model(&models.Wallet{}).Where(`owner_type IS NOT NULL AND NOT EXISTS (
+			SELECT 1 FROM ledger_transactions t
+			WHERE t.kind = ? AND t.reference = wallets.id::text
+		)`, "opening_balance").Pluck("id", &walletIDs).Error; err != nil {
+			return err
+		}
+
+		for _, walletID := range walletIDs {
+			var posted bool
+			err := conn.Transaction(func(tx *gorm.DB) error {
+				var err error
+				posted, err = seedOpeningBalance(tx, walletID)
+				return err
+			})
+			if err != nil {
+				return err
+			}
+			if posted {
+				seeded++
+			}
+		}
+
+		for _, role := range TreasuryRoles {
+			var count int64
+			if err := conn.Model(&models.LedgerTransaction{}).
+				Where("kind = ? AND reference = ?", "opening_balance", treasuryOpeningReference(role)).
+				Count(&count).Error; err != nil {
+				return err
+			}
+			if count > 0 {
+				continue
+			}
+
+			var posted bool
+			err := conn.Transaction(func(tx *gorm.DB) error {
+				var err error
+				posted, err = r.seedTreasuryOpeningBalance(tx, role)
+				return err
+			})
+			if err != nil {
+				return err
+			}
+			if posted {
+				seeded++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return seeded, err
+	}
+
+	return seeded, nil
+}
+
+// seedOpeningBalance posts the opening balance for one wallet, reporting
+// whether there was a balance to post.
+func seedOpeningBalance(tx *gorm.DB, walletID uuid.UUID) (bool, error) {
+	var wallet models.Wallet
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", walletID).
+		First(&wallet).Error; err != nil {
+		return false, fmt.Errorf("failed to lock wallet: %w", err)
+	}
+
+	ref := ledgerAccountRef{OwnerType: wallet.OwnerType, OwnerID: wallet.OwnerID, Code: ledgerCodeWallet}
+	account, _, err := ledgerAccount(tx, ref)
+	if err != nil {
+		return false, fmt.Errorf("failed to open ledger account: %w", err)
+	}
+
+	var ledgerBalance money.Money
+	if err := tx.Table("ledger_accounts a").
+		Select(ledgerBalanceSQL).
+		Joins("LEFT JOIN ledger_entries e ON e.account_id = a.id").
+		Where("a.id = ?", account.ID).
+		Scan(&ledgerBalance).Error; err != nil {
+		return false, err
+	}
+
+	walletBalance, err := wallet.Balance()
+	if err != nil {
+		return false, err
+	}
+	return postOpeningBalance(tx, wallet.ID.String(), ref, walletBalance, ledgerBalance)
+}
+
+// postOpeningBalance records the difference between an account's stored
+// balance and its ledger balance against the opening balance account, under
+// reference. With no difference it records an empty opening transaction as a
+// marker. It reports whether there was a balance to post.
+func postOpeningBalance(tx *gorm.DB, reference string, ref ledgerAccountRef, stored, ledgerBalance money.Money) (bool, error) {
+	opening, err := stored.Sub(ledgerBalance)
+	if err != nil {
+		return false, err
+	}
+
+	const description = "Balance carried over from wallet"
+	switch {
+	case opening.IsZero():
+		marker := models.LedgerTransaction{Kind: "opening_balance", Reference: reference, Description: description}
+		return false, tx.Create(&marker).Error
+	case opening.IsNegative():
+		leg := ledgerLeg{From: ref, To: platformAccount(ledgerCodeOpeningBalance), Amount: money.New(-opening.Amount)}
+		return true, postLedger(tx, "opening_balance", reference, description, leg)
+	default:
+		leg := ledgerLeg{From: platformAccount(ledgerCodeOpeningBalance), To: ref, Amount: opening}
+		return true, postLedger(tx, "opening_balance", reference, description, leg)
+	}
+}
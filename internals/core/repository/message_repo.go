@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/models"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/models/responses"
+	"github.com/google/uuid"
+)
+
+func (r *VendorStorage) CreateBookingMessage(ctx context.Context, message *models.BookingMessage) error {
+	return r.DB.WithContext(ctx).Create(message).Error
+}
+
+func (r *VendorStorage) GetBookingMessages(ctx context.Context, bookingID string, before time.Time, limit int) ([]models.BookingMessage, error) {
+	var messages []models.BookingMessage
+
+	err := r.DB.WithContext(ctx).
+		Preload("Attachments").
+		Where("booking_id = ? AND created_at < ?", bookingID, before).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&messages).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return messages, nil
+}
+
+// GetBookingMessagesAfter returns up to limit messages that come after the
+// message sent at the given time with afterID, oldest first. Messages are
+// ordered by time and then ID so pages can be walked without losing messages
+// sent at the same instant; uuid.Max as afterID starts after everything sent
+// at that time.
+func (r *VendorStorage) GetBookingMessagesAfter(ctx context.Context, bookingID string, after time.Time, afterID uuid.UUID, limit int) ([]models.BookingMessage, error) {
+	var messages []models.BookingMessage
+
+	err := r.DB.WithContext(ctx).
+		Preload("Attachments").
+		Where("booking_id = ? AND (created_at, id) > (?, ?)", bookingID, after, afterID).
+		Order("created_at ASC, id ASC").
+		Limit(limit).
+		Find(&messages).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return messages, nil
+}
+
+func (r *VendorStorage) MarkMessagesRead(ctx context.Context, bookingID string, readerID uuid.UUID) (int64, error) {
+	result := r.DB.WithContext(ctx).
+		Model(&models.BookingMessage{}).
+		Where("booking_id = ? AND sender_id <> ? AND read_at IS NULL", bookingID, readerID).
+		Update("read_at", time.Now())
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
+
+func (r *VendorStorage) GetUnreadMessageCounts(ctx context.Context, vendorID string) ([]responses.UnreadCount, error) {
+	var counts []responses.UnreadCount
+
+	err := r.DB.WithContext(ctx).
+		Table("booking_messages m").
+		Select("m.booking_id, COUNT(*) AS unread").
+		Joins("JOIN bookings b ON b.booking_id = m.booking_id").
+		Where("b.vendor_id = ? AND m.sender_id <> ? AND m.read_at IS NULL", vendorID, vendorID).
+		Group("m.booking_id").
+		Scan(&counts).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return counts, nil
+}
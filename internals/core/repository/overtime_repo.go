@@ -0,0 +1,197 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/models"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/money"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrOvertimeNotPending = errors.New("overtime charge is not awaiting approval")
+	ErrBookingPaidOut     = errors.New("booking has already been paid out")
+)
+
+func (r *VendorStorage) CreateOvertimeCharge(ctx context.Context, charge *models.OvertimeCharge) error {
+	return r.DB.WithContext(ctx).Create(charge).Error
+}
+
+func (r *VendorStorage) GetOvertimeCharge(ctx context.Context, chargeID string) (*models.OvertimeCharge, error) {
+	var charge models.OvertimeCharge
+	err := r.DB.WithContext(ctx).Where("id = ?", chargeID).First(&charge).Error
+	if err != nil {
+		return nil, err
+	}
+	return &charge, nil
+}
+
+func (r *VendorStorage) ListOvertimeCharges(ctx context.Context, bookingID string) ([]models.OvertimeCharge, error) {
+	var charges []models.OvertimeCharge
+	err := r.DB.WithContext(ctx).Where("booking_id = ?", bookingID).Order("created_at ASC").Find(&charges).Error
+	if err != nil {
+		return nil, err
+	}
+	return charges, nil
+}
+
+func (r *VendorStorage) DeclineOvertimeCharge(ctx context.Context, chargeID string) error {
+	result := r.DB.WithContext(ctx).
+		Model(&models.OvertimeCharge{}).
+		Where("id = ? AND status = ?", chargeID, "pending").
+		Updates(map[string]interface{}{
+			"status":     "declined",
+			"decided_at": time.Now(),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrOvertimeNotPending
+	}
+	return nil
+}
+
+// CollectOvertimeCharge moves an approved charge from the client's wallet into
+// escrow, where it is held with the rest of the booking's money and paid out,
+// less commission, with the booking. A booking that has already been paid out
+// or refunded takes no more charges.
+func (r *VendorStorage) CollectOvertimeCharge(ctx context.Context, chargeID string) (*models.OvertimeCharge, error) {
+	var charge models.OvertimeCharge
+
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", chargeID).
+			First(&charge).Error; err != nil {
+			return err
+		}
+
+		if charge.Status != "pending" {
+			return ErrOvertimeNotPending
+		}
+
+		// Locking the completion keeps a settlement or dispute resolution
+		// from paying the booking out while the charge joins it.
+		var completion models.BookingCompletion
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("booking_id = ?", charge.BookingID).
+			First(&completion).Error; err != nil {
+			return fmt.Errorf("failed to find booking completion: %w", err)
+		}
+		if completion.PayoutStatus != "awaiting_release" && completion.PayoutStatus != "disputed" {
+			return ErrBookingPaidOut
+		}
+
+		clientWallet, err := lockWalletForDebit(tx, clientWalletOwner(charge.ClientID), charge.Amount)
+		if err != nil {
+			return err
+		}
+		if err := debitWallet(tx, clientWallet, charge.Amount); err != nil {
+			return err
+		}
+
+		if err := r.creditTreasury(tx, TreasuryEscrow, charge.Amount); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if err := recordBookingTransaction(tx, charge.BookingID, charge.ClientID, "Overtime Charge", "paid", charge.Amount, now); err != nil {
+			return err
+		}
+
+		if err := recordAdminTransaction(tx, "Overtime Charge", "succeded", charge.Amount, now); err != nil {
+			return err
+		}
+
+		if err := postLedger(tx, "overtime_charge", charge.BookingID.String(), "Overtime Charge",
+			ledgerLeg{From: clientWalletAccount(charge.ClientID), To: treasuryLedgerAccount(TreasuryEscrow), Amount: charge.Amount},
+		); err != nil {
+			return err
+		}
+
+		if _, err := issueInvoice(tx, invoiceSource{
+			kind:       models.InvoiceKindReceipt,
+			sourceType: "overtime",
+			sourceID:   charge.ID.String(),
+			vendorID:   charge.VendorID,
+			clientID:   charge.ClientID,
+			bookingID:  charge.BookingID,
+		}, []models.InvoiceLine{{
+			Kind:        models.InvoiceLineItem,
+			Description: fmt.Sprintf("Overtime, %d extra hours", charge.ExtraHours),
+			Quantity:    charge.ExtraHours,
+			UnitPrice:   charge.HourlyRate,
+			Amount:      charge.Amount,
+		}}); err != nil {
+			return err
+		}
+
+		charge.Status = "paid"
+		charge.DecidedAt = &now
+		if err := tx.Model(&charge).Updates(map[string]interface{}{
+			"status":     charge.Status,
+			"decided_at": now,
+		}).Error; err != nil {
+			return err
+		}
+
+		return syncFundHolds(tx, charge.VendorID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &charge, nil
+}
+
+// heldOvertimeCharges locks the booking's paid overtime charges that are still
+// in escrow and returns them with their total.
+func heldOvertimeCharges(tx *gorm.DB, bookingID uuid.UUID) (money.Money, []models.OvertimeCharge, error) {
+	var charges []models.OvertimeCharge
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("booking_id = ? AND status = ? AND released_at IS NULL", bookingID, "paid").
+		Order("created_at ASC").
+		Find(&charges).Error; err != nil {
+		return money.Money{}, nil, fmt.Errorf("failed to fetch overtime charges: %w", err)
+	}
+
+	amount := money.Zero()
+	for _, charge := range charges {
+		var err error
+		if amount, err = amount.Add(charge.Amount); err != nil {
+			return money.Money{}, nil, err
+		}
+	}
+	return amount, charges, nil
+}
+
+func releaseOvertimeCharges(tx *gorm.DB, chargeIDs []uuid.UUID, at time.Time) error {
+	if len(chargeIDs) == 0 {
+		return nil
+	}
+	return tx.Model(&models.OvertimeCharge{}).
+		Where("id IN ? AND status = ? AND released_at IS NULL", chargeIDs, "paid").
+		Update("released_at", at).Error
+}
+
+func refundOvertimeCharges(tx *gorm.DB, chargeIDs []uuid.UUID) error {
+	if len(chargeIDs) == 0 {
+		return nil
+	}
+	return tx.Model(&models.OvertimeCharge{}).
+		Where("id IN ? AND status = ? AND released_at IS NULL", chargeIDs, "paid").
+		Update("status", "refunded").Error
+}
+
+func overtimeChargeIDs(charges []models.OvertimeCharge) []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(charges))
+	for _, charge := range charges {
+		ids = append(ids, charge.ID)
+	}
+	return ids
+}
@@ -0,0 +1,244 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	adminModel "github.com/AthulKrishna2501/zyra-admin-service/internals/core/models"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/models"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/money"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrInstallmentNotPayable = errors.New("installment is not awaiting payment")
+	ErrInsufficientBalance   = errors.New("wallet has insufficient balance")
+)
+
+func (r *VendorStorage) GetPaymentMilestones(ctx context.Context, serviceID uuid.UUID) ([]models.PaymentMilestone, error) {
+	var milestones []models.PaymentMilestone
+	err := r.DB.WithContext(ctx).Where("service_id = ?", serviceID).Order("sequence ASC").Find(&milestones).Error
+	if err != nil {
+		return nil, err
+	}
+	return milestones, nil
+}
+
+func (r *VendorStorage) ReplacePaymentMilestones(ctx context.Context, serviceID uuid.UUID, milestones []models.PaymentMilestone) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("service_id = ?", serviceID).Delete(&models.PaymentMilestone{}).Error; err != nil {
+			return err
+		}
+
+		if len(milestones) == 0 {
+			return nil
+		}
+		return tx.Create(&milestones).Error
+	})
+}
+
+func (r *VendorStorage) GetBookingInstallments(ctx context.Context, bookingID string) ([]models.BookingInstallment, error) {
+	var installments []models.BookingInstallment
+	err := r.DB.WithContext(ctx).Where("booking_id = ?", bookingID).Order("sequence ASC").Find(&installments).Error
+	if err != nil {
+		return nil, err
+	}
+	return installments, nil
+}
+
+// PayInstallment collects a pending installment from the client's wallet into
+// escrow, where it is held until released to the vendor. An installment
+// released on approval that is paid after the booking was approved is queued
+// for the vendor's next settlement in the same transaction.
+func (r *VendorStorage) PayInstallment(ctx context.Context, installmentID string, clientID uuid.UUID) (*models.BookingInstallment, error) {
+	var installment models.BookingInstallment
+
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", installmentID).
+			First(&installment).Error; err != nil {
+			return err
+		}
+
+		if installment.Status != "pending" {
+			return ErrInstallmentNotPayable
+		}
+
+		wallet, err := lockWalletForDebit(tx, clientWalletOwner(clientID), installment.Amount)
+		if err != nil {
+			return err
+		}
+		if err := debitWallet(tx, wallet, installment.Amount); err != nil {
+			return err
+		}
+
+		if err := r.creditTreasury(tx, TreasuryEscrow, installment.Amount); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if err := recordBookingTransaction(tx, installment.BookingID, clientID, "Booking "+installment.Label, "paid", installment.Amount, now); err != nil {
+			return err
+		}
+
+		if err := recordAdminTransaction(tx, "Vendor Booking", "paid", installment.Amount, now); err != nil {
+			return err
+		}
+
+		leg := ledgerLeg{From: clientWalletAccount(clientID), To: treasuryLedgerAccount(TreasuryEscrow), Amount: installment.Amount}
+		if err := postLedger(tx, "installment_payment", installment.BookingID.String(), "Booking "+installment.Label, leg); err != nil {
+			return err
+		}
+
+		var booking adminModel.Booking
+		if err := tx.Where("booking_id = ?", installment.BookingID).First(&booking).Error; err != nil {
+			return fmt.Errorf("failed to find booking: %w", err)
+		}
+		if _, err := issueInvoice(tx, invoiceSource{
+			kind:       models.InvoiceKindReceipt,
+			sourceType: "installment",
+			sourceID:   installment.ID.String(),
+			vendorID:   booking.VendorID,
+			clientID:   clientID,
+			bookingID:  booking.BookingID,
+		}, []models.InvoiceLine{
+			singleItemLine(fmt.Sprintf("%s, %s", booking.Service, installment.Label), installment.Amount),
+		}); err != nil {
+			return err
+		}
+
+		installment.Status = "paid"
+		installment.PaidAt = &now
+		if err := tx.Model(&installment).Updates(map[string]interface{}{
+			"status":  installment.Status,
+			"paid_at": now,
+		}).Error; err != nil {
+			return err
+		}
+
+		if installment.ReleaseOn == "approval" && booking.Status == "approved" {
+			if _, err := r.releaseApprovalInstallments(tx, &booking, booking.Status, now); err != nil {
+				return err
+			}
+		}
+
+		return syncFundHolds(tx, booking.VendorID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &installment, nil
+}
+
+// heldBookingFunds locks the booking's installments and returns what the
+// platform is holding for it: the paid installments not yet released, or the
+// full price when the booking has no payment schedule.
+func heldBookingFunds(tx *gorm.DB, booking *adminModel.Booking) (money.Money, []models.BookingInstallment, error) {
+	var installments []models.BookingInstallment
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("booking_id = ?", booking.BookingID).
+		Order("sequence ASC").
+		Find(&installments).Error; err != nil {
+		return money.Money{}, nil, fmt.Errorf("failed to fetch installments: %w", err)
+	}
+
+	if len(installments) == 0 {
+		price, err := models.BookingPrice(booking)
+		return price, nil, err
+	}
+
+	held := []models.BookingInstallment{}
+	amount := money.Zero()
+	for _, installment := range installments {
+		if installment.Status == "paid" && installment.ReleasedAt == nil {
+			held = append(held, installment)
+
+			var err error
+			if amount, err = amount.Add(installment.Amount); err != nil {
+				return money.Money{}, nil, err
+			}
+		}
+	}
+
+	return amount, held, nil
+}
+
+// releaseApprovalInstallments releases the booking's paid installments whose
+// milestone is its approval, leaving the rest held until completion. The
+// released amount is queued for the vendor's next settlement rather than paid
+// on its own, and the release is recorded on the booking's timeline.
+func (r *VendorStorage) releaseApprovalInstallments(tx *gorm.DB, booking *adminModel.Booking, bookingStatus string, at time.Time) (money.Money, error) {
+	_, held, err := heldBookingFunds(tx, booking)
+	if err != nil {
+		return money.Money{}, err
+	}
+
+	var ids []uuid.UUID
+	amount := money.Zero()
+	for _, installment := range held {
+		if installment.ReleaseOn != "approval" {
+			continue
+		}
+		ids = append(ids, installment.ID)
+		if amount, err = amount.Add(installment.Amount); err != nil {
+			return money.Money{}, err
+		}
+	}
+
+	if amount.IsZero() {
+		return amount, nil
+	}
+
+	description := fmt.Sprintf("%s on %s, released on approval", booking.Service, booking.Date.Format("02 Jan 2006"))
+	if err := queuePayout(tx, booking.VendorID, booking.BookingID, models.QueuedPayoutApproval, description, amount); err != nil {
+		return money.Money{}, err
+	}
+	if err := releaseInstallments(tx, ids, at); err != nil {
+		return money.Money{}, err
+	}
+
+	event := models.BookingEvent{
+		BookingID: booking.BookingID,
+		ActorRole: "system",
+		Action:    "payout_queued",
+		OldStatus: bookingStatus,
+		NewStatus: bookingStatus,
+		Reason:    fmt.Sprintf("Queued %s for settlement on approval", amount),
+	}
+	if err := tx.Create(&event).Error; err != nil {
+		return money.Money{}, fmt.Errorf("failed to record booking event: %w", err)
+	}
+
+	return amount, nil
+}
+
+func releaseInstallments(tx *gorm.DB, installmentIDs []uuid.UUID, at time.Time) error {
+	if len(installmentIDs) == 0 {
+		return nil
+	}
+	return tx.Model(&models.BookingInstallment{}).
+		Where("id IN ? AND status = ? AND released_at IS NULL", installmentIDs, "paid").
+		Update("released_at", at).Error
+}
+
+func refundInstallments(tx *gorm.DB, installmentIDs []uuid.UUID) error {
+	if len(installmentIDs) == 0 {
+		return nil
+	}
+	return tx.Model(&models.BookingInstallment{}).
+		Where("id IN ? AND status = ? AND released_at IS NULL", installmentIDs, "paid").
+		Update("status", "refunded").Error
+}
+
+func installmentIDs(installments []models.BookingInstallment) []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(installments))
+	for _, installment := range installments {
+		ids = append(ids, installment.ID)
+	}
+	return ids
+}
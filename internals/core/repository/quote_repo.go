@@ -0,0 +1,263 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	adminModel "github.com/AthulKrishna2501/zyra-admin-service/internals/core/models"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/models"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/money"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrQuoteNotOpen = errors.New("quote is no longer open")
+
+// QuoteFilter narrows a vendor's quotes. Zero values leave a field unfiltered.
+type QuoteFilter struct {
+	VendorID uuid.UUID
+	Statuses []string
+	From     *time.Time
+	To       *time.Time
+	Search   string
+	Limit    int
+	Offset   int
+}
+
+func (r *VendorStorage) CreateQuote(ctx context.Context, quote *models.Quote) error {
+	return r.DB.WithContext(ctx).Create(quote).Error
+}
+
+func (r *VendorStorage) GetQuote(ctx context.Context, quoteID string) (*models.Quote, error) {
+	var quote models.Quote
+	err := r.DB.WithContext(ctx).
+		Preload("LineItems", func(db *gorm.DB) *gorm.DB { return db.Order("position ASC") }).
+		Where("id = ?", quoteID).
+		First(&quote).Error
+	if err != nil {
+		return nil, err
+	}
+	return &quote, nil
+}
+
+// SubmitQuote prices a requested quote, replacing any line items from an
+// earlier revision while the client has not yet answered.
+func (r *VendorStorage) SubmitQuote(ctx context.Context, quoteID string, items []models.QuoteLineItem, note string, expiresAt time.Time) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var quote models.Quote
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", quoteID).
+			First(&quote).Error; err != nil {
+			return err
+		}
+
+		if status := quote.EffectiveStatus(time.Now()); status != "requested" && status != "quoted" {
+			return ErrQuoteNotOpen
+		}
+
+		if err := tx.Where("quote_id = ?", quote.ID).Delete(&models.QuoteLineItem{}).Error; err != nil {
+			return err
+		}
+
+		total := money.Zero()
+		for i := range items {
+			items[i].QuoteID = quote.ID
+
+			var err error
+			if total, err = total.Add(items[i].Amount); err != nil {
+				return err
+			}
+		}
+		if err := tx.Create(&items).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		return tx.Model(&quote).Updates(map[string]interface{}{
+			"status":      "quoted",
+			"total_price": total,
+			"vendor_note": note,
+			"expires_at":  expiresAt,
+			"quoted_at":   now,
+		}).Error
+	})
+}
+
+func (r *VendorStorage) DeclineQuote(ctx context.Context, quoteID string, declinedBy, reason string) error {
+	result := r.DB.WithContext(ctx).
+		Model(&models.Quote{}).
+		Where("id = ? AND (status = ? OR (status = ? AND expires_at >= ?))", quoteID, "requested", "quoted", time.Now()).
+		Updates(map[string]interface{}{
+			"status":      "declined",
+			"declined_by": declinedBy,
+			"reason":      reason,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrQuoteNotOpen
+	}
+	return nil
+}
+
+// AcceptQuote turns a priced quote into an approved booking of the quoted
+// service at the quoted price, collecting the amount from the client's wallet
+// into escrow in the same transaction. The service's terms are kept with the
+// booking as for any other booking.
+func (r *VendorStorage) AcceptQuote(ctx context.Context, quoteID string, service *models.Service, event *models.BookingEvent) (*adminModel.Booking, error) {
+	var booking adminModel.Booking
+
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var quote models.Quote
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", quoteID).
+			First(&quote).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if quote.EffectiveStatus(now) != "quoted" {
+			return ErrQuoteNotOpen
+		}
+		if quote.ServiceID == nil || *quote.ServiceID != service.ID {
+			return fmt.Errorf("quote is not for service %s", service.ID)
+		}
+
+		wallet, err := lockWalletForDebit(tx, clientWalletOwner(quote.ClientID), quote.TotalPrice)
+		if err != nil {
+			return err
+		}
+		if err := debitWallet(tx, wallet, quote.TotalPrice); err != nil {
+			return err
+		}
+
+		if err := r.creditTreasury(tx, TreasuryEscrow, quote.TotalPrice); err != nil {
+			return err
+		}
+
+		price, err := wholeUnits(quote.TotalPrice)
+		if err != nil {
+			return err
+		}
+
+		booking = adminModel.Booking{
+			BookingID:        uuid.New(),
+			ClientID:         quote.ClientID,
+			VendorID:         quote.VendorID,
+			Service:          service.ServiceTitle,
+			Date:             quote.EventDate,
+			Price:            price,
+			Status:           "approved",
+			IsVendorApproved: true,
+			IsClientApproved: true,
+		}
+		if err := tx.Create(&booking).Error; err != nil {
+			return fmt.Errorf("failed to create booking: %w", err)
+		}
+		if err := saveBookingSnapshot(tx, booking.BookingID, service, now); err != nil {
+			return err
+		}
+
+		if err := recordBookingTransaction(tx, booking.BookingID, quote.ClientID, "Vendor Booking", "paid", quote.TotalPrice, now); err != nil {
+			return err
+		}
+
+		if err := recordAdminTransaction(tx, "Vendor Booking", "paid", quote.TotalPrice, now); err != nil {
+			return err
+		}
+
+		leg := ledgerLeg{From: clientWalletAccount(quote.ClientID), To: treasuryLedgerAccount(TreasuryEscrow), Amount: quote.TotalPrice}
+		if err := postLedger(tx, "booking_payment", booking.BookingID.String(), "Vendor Booking", leg); err != nil {
+			return err
+		}
+
+		var items []models.QuoteLineItem
+		if err := tx.Where("quote_id = ?", quote.ID).Order("position ASC").Find(&items).Error; err != nil {
+			return err
+		}
+		lines := make([]models.InvoiceLine, 0, len(items))
+		for _, item := range items {
+			lines = append(lines, models.InvoiceLine{
+				Kind:        models.InvoiceLineItem,
+				Description: item.Description,
+				Quantity:    item.Quantity,
+				UnitPrice:   item.UnitPrice,
+				Amount:      item.Amount,
+			})
+		}
+		if _, err := issueInvoice(tx, invoiceSource{
+			kind:       models.InvoiceKindReceipt,
+			sourceType: "booking",
+			sourceID:   booking.BookingID.String(),
+			vendorID:   booking.VendorID,
+			clientID:   booking.ClientID,
+			bookingID:  booking.BookingID,
+		}, lines); err != nil {
+			return err
+		}
+
+		event.BookingID = booking.BookingID
+		event.NewStatus = booking.Status
+		if err := tx.Create(event).Error; err != nil {
+			return fmt.Errorf("failed to record booking event: %w", err)
+		}
+
+		return tx.Model(&quote).Updates(map[string]interface{}{
+			"status":     "accepted",
+			"booking_id": booking.BookingID,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &booking, nil
+}
+
+func (r *VendorStorage) ListVendorQuotes(ctx context.Context, filter QuoteFilter) ([]models.Quote, int64, error) {
+	query := r.DB.WithContext(ctx).Model(&models.Quote{}).Where("vendor_id = ?", filter.VendorID)
+
+	// Filter on the effective status: quoted quotes past their expiry
+	// count as expired.
+	if len(filter.Statuses) > 0 {
+		now := time.Now()
+		statuses := r.DB.Where("status IN ? AND NOT (status = ? AND expires_at < ?)", filter.Statuses, "quoted", now)
+		if slices.Contains(filter.Statuses, "expired") {
+			statuses = statuses.Or("status = ? AND expires_at < ?", "quoted", now)
+		}
+		query = query.Where(statuses)
+	}
+	if filter.From != nil {
+		query = query.Where("event_date >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("event_date < ?", *filter.To)
+	}
+	if filter.Search != "" {
+		pattern := "%" + filter.Search + "%"
+		query = query.Where("title ILIKE ? OR details ILIKE ?", pattern, pattern)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var quotes []models.Quote
+	err := query.
+		Preload("LineItems", func(db *gorm.DB) *gorm.DB { return db.Order("position ASC") }).
+		Order("event_date ASC").
+		Limit(filter.Limit).
+		Offset(filter.Offset).
+		Find(&quotes).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return quotes, total, nil
+}
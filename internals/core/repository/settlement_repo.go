@@ -0,0 +1,371 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	adminModel "github.com/AthulKrishna2501/zyra-admin-service/internals/core/models"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/models"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/money"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ErrSettlementConflict means a booking or queued payout in the settlement
+// was paid out, disputed or settled by someone else after it was gathered.
+var ErrSettlementConflict = errors.New("settlement bookings changed while settling")
+
+// SettlementItem is something to pay in a settlement: either a completed
+// booking, with the amount held for it and the installments and overtime
+// charges, if any, that make up that amount, or a queued payout.
+type SettlementItem struct {
+	BookingID         uuid.UUID
+	Description       string
+	Amount            money.Money
+	InstallmentIDs    []uuid.UUID
+	OvertimeChargeIDs []uuid.UUID
+	QueuedPayoutID    *uuid.UUID
+}
+
+// ListSettleablePayouts returns the completions, oldest first, whose dispute
+// window closed before the given time without a dispute and which have not
+// been paid out yet.
+func (r *VendorStorage) ListSettleablePayouts(ctx context.Context, before time.Time) ([]models.BookingCompletion, error) {
+	var completions []models.BookingCompletion
+	err := r.DB.WithContext(ctx).
+		Where("payout_status = ? AND dispute_window_ends_at < ?", "awaiting_release", before).
+		Order("vendor_id ASC, dispute_window_ends_at ASC").
+		Find(&completions).Error
+	if err != nil {
+		return nil, err
+	}
+	return completions, nil
+}
+
+// ListQueuedPayouts returns the payouts, oldest first, queued before the
+// given time and not settled yet.
+func (r *VendorStorage) ListQueuedPayouts(ctx context.Context, before time.Time) ([]models.QueuedPayout, error) {
+	var payouts []models.QueuedPayout
+	err := r.DB.WithContext(ctx).
+		Where("settlement_id IS NULL AND created_at < ?", before).
+		Order("vendor_id ASC, created_at ASC").
+		Find(&payouts).Error
+	if err != nil {
+		return nil, err
+	}
+	return payouts, nil
+}
+
+// RecordSettlementFailure notes on a completed booking that a settlement run
+// had to leave it out and why. The booking stays awaiting release, so the
+// next run tries it again.
+func (r *VendorStorage) RecordSettlementFailure(ctx context.Context, bookingID uuid.UUID, reason string) error {
+	return r.DB.WithContext(ctx).Model(&models.BookingCompletion{}).
+		Where("booking_id = ? AND payout_status = ?", bookingID, "awaiting_release").
+		Updates(map[string]interface{}{
+			"settlement_attempts": gorm.Expr("settlement_attempts + 1"),
+			"settlement_error":    reason,
+		}).Error
+}
+
+// queuePayout sets amount aside for the vendor's next settlement within the
+// caller's transaction. The money stays in escrow until it is settled.
+func queuePayout(tx *gorm.DB, vendorID, bookingID uuid.UUID, kind, description string, amount money.Money) error {
+	payout := models.QueuedPayout{
+		VendorID:    vendorID,
+		BookingID:   bookingID,
+		Kind:        kind,
+		Description: description,
+		Amount:      amount,
+	}
+	if err := tx.Create(&payout).Error; err != nil {
+		return fmt.Errorf("failed to queue payout: %w", err)
+	}
+	return nil
+}
+
+// SettleVendor pays the vendor for items in a single payout for the period
+// starting at periodStart. Everything happens in one database transaction:
+// items are claimed only if they are still unpaid, so if any was released,
+// disputed or settled since it was gathered, nothing is paid and
+// ErrSettlementConflict is returned. Settling the same items again therefore
+// pays nothing.
+func (r *VendorStorage) SettleVendor(ctx context.Context, vendorID uuid.UUID, periodStart, periodEnd time.Time, items []SettlementItem) (*models.Settlement, error) {
+	if len(items) == 0 {
+		return nil, errors.New("settlement has no bookings")
+	}
+
+	settlement := models.Settlement{
+		VendorID:    vendorID,
+		PeriodStart: periodStart,
+		PeriodEnd:   periodEnd,
+		Gross:       money.Zero(),
+		Fee:         money.Zero(),
+		Net:         money.Zero(),
+		PaidAt:      time.Now(),
+	}
+
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&settlement).Error; err != nil {
+			return fmt.Errorf("failed to create settlement: %w", err)
+		}
+
+		var completedIDs, queuedIDs, installmentIDs, overtimeChargeIDs []uuid.UUID
+		for _, item := range items {
+			if item.QueuedPayoutID != nil {
+				queuedIDs = append(queuedIDs, *item.QueuedPayoutID)
+			} else {
+				completedIDs = append(completedIDs, item.BookingID)
+			}
+			installmentIDs = append(installmentIDs, item.InstallmentIDs...)
+			overtimeChargeIDs = append(overtimeChargeIDs, item.OvertimeChargeIDs...)
+		}
+
+		// Claiming the completions, queued payouts and installments only if
+		// they are still unpaid is what stops two replicas, or a retry,
+		// paying twice.
+		if len(completedIDs) > 0 {
+			result := tx.Model(&models.BookingCompletion{}).
+				Where("booking_id IN ? AND vendor_id = ? AND payout_status = ?", completedIDs, vendorID, "awaiting_release").
+				Updates(map[string]interface{}{"payout_status": "released", "settlement_error": ""})
+			if result.Error != nil {
+				return fmt.Errorf("failed to claim completions: %w", result.Error)
+			}
+			if result.RowsAffected != int64(len(completedIDs)) {
+				return ErrSettlementConflict
+			}
+		}
+
+		releasedIDs := completedIDs
+		if len(queuedIDs) > 0 {
+			result := tx.Model(&models.QueuedPayout{}).
+				Where("id IN ? AND vendor_id = ? AND settlement_id IS NULL", queuedIDs, vendorID).
+				Update("settlement_id", settlement.ID)
+			if result.Error != nil {
+				return fmt.Errorf("failed to claim queued payouts: %w", result.Error)
+			}
+			if result.RowsAffected != int64(len(queuedIDs)) {
+				return ErrSettlementConflict
+			}
+
+			// A disputed booking's payout is the last of its money, so
+			// settling it releases the booking.
+			var disputedIDs []uuid.UUID
+			if err := tx.Model(&models.QueuedPayout{}).
+				Where("id IN ? AND kind = ?", queuedIDs, models.QueuedPayoutDispute).
+				Pluck("booking_id", &disputedIDs).Error; err != nil {
+				return fmt.Errorf("failed to fetch queued payouts: %w", err)
+			}
+			releasedIDs = append(releasedIDs, disputedIDs...)
+		}
+
+		if len(installmentIDs) > 0 {
+			result := tx.Model(&models.BookingInstallment{}).
+				Where("id IN ? AND status = ? AND released_at IS NULL", installmentIDs, "paid").
+				Update("released_at", settlement.PaidAt)
+			if result.Error != nil {
+				return fmt.Errorf("failed to release installments: %w", result.Error)
+			}
+			if result.RowsAffected != int64(len(installmentIDs)) {
+				return ErrSettlementConflict
+			}
+		}
+
+		if len(overtimeChargeIDs) > 0 {
+			result := tx.Model(&models.OvertimeCharge{}).
+				Where("id IN ? AND status = ? AND released_at IS NULL", overtimeChargeIDs, "paid").
+				Update("released_at", settlement.PaidAt)
+			if result.Error != nil {
+				return fmt.Errorf("failed to release overtime charges: %w", result.Error)
+			}
+			if result.RowsAffected != int64(len(overtimeChargeIDs)) {
+				return ErrSettlementConflict
+			}
+		}
+
+		// A charge collected after the items were gathered would otherwise
+		// be left in escrow once its booking is paid out.
+		if len(completedIDs) > 0 {
+			var unsettled int64
+			if err := tx.Model(&models.OvertimeCharge{}).
+				Where("booking_id IN ? AND status = ? AND released_at IS NULL", completedIDs, "paid").
+				Count(&unsettled).Error; err != nil {
+				return fmt.Errorf("failed to check overtime charges: %w", err)
+			}
+			if unsettled > 0 {
+				return ErrSettlementConflict
+			}
+		}
+
+		rule, err := commissionRuleForVendor(tx, vendorID)
+		if err != nil {
+			return fmt.Errorf("failed to fetch commission rule: %w", err)
+		}
+
+		bookingIDs := settlementBookingIDs(items)
+		firstPayouts := make(map[uuid.UUID]bool, len(bookingIDs))
+		for _, bookingID := range bookingIDs {
+			if firstPayouts[bookingID], err = isFirstPayout(tx, bookingID); err != nil {
+				return err
+			}
+		}
+
+		if err := buildSettlement(&settlement, items, rule, firstPayouts); err != nil {
+			return err
+		}
+
+		if err := tx.Create(&settlement.Lines).Error; err != nil {
+			return fmt.Errorf("failed to create settlement lines: %w", err)
+		}
+
+		if len(releasedIDs) > 0 {
+			if err := tx.Model(&adminModel.Booking{}).
+				Where("booking_id IN ?", releasedIDs).
+				Updates(map[string]interface{}{
+					"is_vendor_approved": true,
+					"is_client_approved": true,
+					"is_fund_released":   true,
+					"updated_at":         settlement.PaidAt,
+				}).Error; err != nil {
+				return fmt.Errorf("failed to update bookings: %w", err)
+			}
+		}
+
+		if settlement.Gross.IsPositive() {
+			commission := models.PayoutCommission{
+				VendorID:  vendorID,
+				Reference: settlement.ID.String(),
+				Gross:     settlement.Gross,
+				Fee:       settlement.Fee,
+				Net:       settlement.Net,
+			}
+			if rule != nil {
+				commission.RuleID = &rule.ID
+			}
+			if err := r.payFromEscrow(tx, &commission, "Settlement Payout", settlement.PaidAt, bookingIDs...); err != nil {
+				return err
+			}
+			settlement.TransactionID = &commission.TransactionID
+
+			if err := issueSettlementInvoice(tx, &settlement); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Model(&settlement).Updates(map[string]interface{}{
+			"gross":          settlement.Gross,
+			"fee":            settlement.Fee,
+			"net":            settlement.Net,
+			"transaction_id": settlement.TransactionID,
+		}).Error; err != nil {
+			return err
+		}
+
+		return syncFundHolds(tx, vendorID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &settlement, nil
+}
+
+// settlementBookingIDs returns the bookings items pay for, each once, in the
+// order they first appear.
+func settlementBookingIDs(items []SettlementItem) []uuid.UUID {
+	seen := make(map[uuid.UUID]bool, len(items))
+	bookingIDs := make([]uuid.UUID, 0, len(items))
+	for _, item := range items {
+		if !seen[item.BookingID] {
+			seen[item.BookingID] = true
+			bookingIDs = append(bookingIDs, item.BookingID)
+		}
+	}
+	return bookingIDs
+}
+
+// buildSettlement adds a line for each item to settlement, less the
+// platform's commission under rule, and totals them. firstPayouts says which
+// bookings have never been paid out; the rule's fixed fee is charged on the
+// first line of each of them only.
+func buildSettlement(settlement *models.Settlement, items []SettlementItem, rule *models.CommissionRule, firstPayouts map[uuid.UUID]bool) error {
+	charged := make(map[uuid.UUID]bool, len(items))
+	for _, item := range items {
+		withFixedFee := firstPayouts[item.BookingID] && !charged[item.BookingID]
+		charged[item.BookingID] = true
+
+		fee, net, err := splitCommission(item.Amount, rule, withFixedFee)
+		if err != nil {
+			return fmt.Errorf("failed to calculate commission: %w", err)
+		}
+
+		settlement.Lines = append(settlement.Lines, models.SettlementLine{
+			SettlementID: settlement.ID,
+			BookingID:    item.BookingID,
+			Description:  item.Description,
+			Gross:        item.Amount,
+			Fee:          fee,
+			Net:          net,
+		})
+
+		if settlement.Gross, err = settlement.Gross.Add(item.Amount); err != nil {
+			return err
+		}
+		if settlement.Fee, err = settlement.Fee.Add(fee); err != nil {
+			return err
+		}
+		if settlement.Net, err = settlement.Net.Add(net); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *VendorStorage) ListSettlements(ctx context.Context, vendorID uuid.UUID, limit int) ([]models.Settlement, error) {
+	var settlements []models.Settlement
+	err := r.DB.WithContext(ctx).
+		Preload("Lines").
+		Where("vendor_id = ?", vendorID).
+		Order("period_start DESC").
+		Limit(limit).
+		Find(&settlements).Error
+	if err != nil {
+		return nil, err
+	}
+	return settlements, nil
+}
+
+// issueSettlementInvoice records a settlement for the vendor: a line per
+// booking at its gross amount, less the platform's commission on them all.
+func issueSettlementInvoice(tx *gorm.DB, settlement *models.Settlement) error {
+	var lines []models.InvoiceLine
+	for _, line := range settlement.Lines {
+		if line.Gross.IsPositive() {
+			lines = append(lines, singleItemLine(line.Description, line.Gross))
+		}
+	}
+
+	if settlement.Fee.IsPositive() {
+		fee, err := money.Zero().Sub(settlement.Fee)
+		if err != nil {
+			return err
+		}
+		lines = append(lines, models.InvoiceLine{
+			Kind:        models.InvoiceLineFee,
+			Description: "Platform commission",
+			Quantity:    1,
+			UnitPrice:   fee,
+			Amount:      fee,
+		})
+	}
+
+	_, err := issueInvoice(tx, invoiceSource{
+		kind:       models.InvoiceKindPayout,
+		sourceType: "settlement",
+		sourceID:   settlement.ID.String(),
+		vendorID:   settlement.VendorID,
+	}, lines)
+	return err
+}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/models"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/money"
+	"github.com/google/uuid"
+)
+
+func TestBuildSettlement(t *testing.T) {
+	first, repeat := uuid.New(), uuid.New()
+	rule := &models.CommissionRule{RateBasisPoints: 1000, FixedFee: money.New(5000)}
+	item := func(bookingID uuid.UUID, amount int64) SettlementItem {
+		return SettlementItem{BookingID: bookingID, Description: "Photography", Amount: money.New(amount)}
+	}
+
+	tests := []struct {
+		name     string
+		items    []SettlementItem
+		rule     *models.CommissionRule
+		wantFees []int64
+	}{
+		{name: "no rule", items: []SettlementItem{item(first, 100000)}, wantFees: []int64{0}},
+		{name: "first payout pays the fixed fee", items: []SettlementItem{item(first, 100000)}, rule: rule, wantFees: []int64{15000}},
+		{name: "booking paid out before", items: []SettlementItem{item(repeat, 100000)}, rule: rule, wantFees: []int64{10000}},
+		{
+			name:     "fixed fee once per booking",
+			items:    []SettlementItem{item(first, 100000), item(first, 50000), item(repeat, 20000)},
+			rule:     rule,
+			wantFees: []int64{15000, 5000, 2000},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settlement := models.Settlement{ID: uuid.New(), Gross: money.Zero(), Fee: money.Zero(), Net: money.Zero()}
+			firstPayouts := map[uuid.UUID]bool{first: true, repeat: false}
+
+			if err := buildSettlement(&settlement, tt.items, tt.rule, firstPayouts); err != nil {
+				t.Fatalf("buildSettlement: %v", err)
+			}
+			if len(settlement.Lines) != len(tt.items) {
+				t.Fatalf("%d lines, want %d", len(settlement.Lines), len(tt.items))
+			}
+
+			gross, fee := money.Zero(), money.Zero()
+			for i, line := range settlement.Lines {
+				if line.SettlementID != settlement.ID || line.BookingID != tt.items[i].BookingID || line.Gross != tt.items[i].Amount {
+					t.Errorf("line %d = %+v, want it to settle %+v", i, line, tt.items[i])
+				}
+				if line.Fee != money.New(tt.wantFees[i]) {
+					t.Errorf("line %d fee = %s, want %s", i, line.Fee, money.New(tt.wantFees[i]))
+				}
+				if sum, _ := line.Fee.Add(line.Net); sum != line.Gross {
+					t.Errorf("line %d fee %s and net %s add up to %s, want %s", i, line.Fee, line.Net, sum, line.Gross)
+				}
+				gross, _ = gross.Add(line.Gross)
+				fee, _ = fee.Add(line.Fee)
+			}
+			if settlement.Gross != gross || settlement.Fee != fee {
+				t.Errorf("totals = %s gross, %s fee; want %s, %s", settlement.Gross, settlement.Fee, gross, fee)
+			}
+			if sum, _ := settlement.Fee.Add(settlement.Net); sum != settlement.Gross {
+				t.Errorf("fee %s and net %s add up to %s, want %s", settlement.Fee, settlement.Net, sum, settlement.Gross)
+			}
+		})
+	}
+}
+
+func TestSettlementBookingIDs(t *testing.T) {
+	a, b := uuid.New(), uuid.New()
+	items := []SettlementItem{{BookingID: a}, {BookingID: b}, {BookingID: a}}
+
+	got := settlementBookingIDs(items)
+	if len(got) != 2 || got[0] != a || got[1] != b {
+		t.Errorf("settlementBookingIDs = %v, want [%s %s]", got, a, b)
+	}
+}
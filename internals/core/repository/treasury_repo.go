@@ -0,0 +1,232 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	adminModel "github.com/AthulKrishna2501/zyra-admin-service/internals/core/models"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/models"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/models/responses"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/money"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// TreasuryEscrow holds client payments until they are paid out to the
+	// vendor or refunded.
+	TreasuryEscrow = "escrow"
+	// TreasuryFees collects the platform's commission on payouts.
+	TreasuryFees = "fees"
+	// TreasuryRefunds is what refunds are paid out of. Money moves into it
+	// from escrow as each refund is made, so its totals show everything
+	// refunded.
+	TreasuryRefunds = "refunds"
+)
+
+var (
+	ErrTreasuryNotConfigured   = errors.New("treasury account is not configured")
+	ErrTreasuryWalletNotFound  = errors.New("treasury wallet not found")
+	ErrEscrowNotClientPayments = errors.New("escrow must be the client payments wallet")
+)
+
+// Treasury names the admin wallet behind each of the platform's accounts, by
+// email. ClientPayments is the admin wallet the client service pays booking
+// money into; escrow has to be that same wallet, or payments would land
+// outside escrow and payouts would drain a wallet nothing is paid into.
+type Treasury struct {
+	Escrow         string
+	Fees           string
+	Refunds        string
+	ClientPayments string
+}
+
+// TreasuryRoles lists the platform's accounts in the order they are reported.
+var TreasuryRoles = []string{TreasuryEscrow, TreasuryFees, TreasuryRefunds}
+
+func (t Treasury) email(role string) string {
+	switch role {
+	case TreasuryEscrow:
+		return t.Escrow
+	case TreasuryFees:
+		return t.Fees
+	case TreasuryRefunds:
+		return t.Refunds
+	default:
+		return ""
+	}
+}
+
+// treasuryLedgerAccount is the ledger account that mirrors each treasury
+// account. Fees predate the treasury and keep the revenue account.
+func treasuryLedgerAccount(role string) ledgerAccountRef {
+	switch role {
+	case TreasuryFees:
+		return platformAccount(ledgerCodeRevenue)
+	case TreasuryRefunds:
+		return platformAccount(ledgerCodeRefunds)
+	default:
+		return platformAccount(ledgerCodeEscrow)
+	}
+}
+
+// validate checks that every treasury account is configured with its own
+// admin wallet and that escrow is the wallet client payments go into.
+func (t Treasury) validate() error {
+	if t.ClientPayments == "" {
+		return fmt.Errorf("%w: client payments", ErrTreasuryNotConfigured)
+	}
+
+	seen := map[string]string{}
+	for _, role := range TreasuryRoles {
+		email := t.email(role)
+		if email == "" {
+			return fmt.Errorf("%w: %s", ErrTreasuryNotConfigured, role)
+		}
+		if other, ok := seen[email]; ok {
+			return fmt.Errorf("treasury accounts %s and %s share the wallet %s", other, role, email)
+		}
+		seen[email] = role
+	}
+
+	if t.Escrow != t.ClientPayments {
+		return fmt.Errorf("%w: escrow wallet %s is not the client payments wallet %s", ErrEscrowNotClientPayments, t.Escrow, t.ClientPayments)
+	}
+	return nil
+}
+
+// CheckTreasury makes sure every treasury account is configured, each with its
+// own admin wallet, that escrow is the wallet the client service pays into,
+// and that the wallets exist. It runs at startup so a missing or mistyped
+// email stops the service instead of splitting the books.
+func (r *VendorStorage) CheckTreasury(ctx context.Context) error {
+	if err := r.Treasury.validate(); err != nil {
+		return err
+	}
+
+	for _, role := range TreasuryRoles {
+		email := r.Treasury.email(role)
+
+		var count int64
+		err := r.DB.WithContext(ctx).Model(&adminModel.AdminWallet{}).Where("email = ?", email).Count(&count).Error
+		if err != nil {
+			return fmt.Errorf("failed to check %s wallet: %w", role, err)
+		}
+		if count == 0 {
+			return fmt.Errorf("%w: %s (%s)", ErrTreasuryWalletNotFound, role, email)
+		}
+	}
+	return nil
+}
+
+// GetTreasuryBalances reports each treasury account's wallet next to the
+// balance of its ledger account.
+func (r *VendorStorage) GetTreasuryBalances(ctx context.Context) ([]responses.TreasuryBalance, error) {
+	db := r.DB.WithContext(ctx)
+
+	var balances []responses.TreasuryBalance
+	for _, role := range TreasuryRoles {
+		email := r.Treasury.email(role)
+
+		var wallet adminModel.AdminWallet
+		if err := db.Where("email = ?", email).First(&wallet).Error; err != nil {
+			return nil, fmt.Errorf("failed to fetch %s wallet: %w", role, err)
+		}
+
+		balance := responses.TreasuryBalance{Role: role, Email: email}
+		var err error
+		if balance.WalletBalance, err = models.AdminWalletBalance(&wallet); err != nil {
+			return nil, err
+		}
+		if balance.TotalDeposits, err = money.FromMajorFloat(wallet.TotalDeposits); err != nil {
+			return nil, err
+		}
+		if balance.TotalWithdrawals, err = money.FromMajorFloat(wallet.TotalWithdrawals); err != nil {
+			return nil, err
+		}
+
+		ref := treasuryLedgerAccount(role)
+		err = db.Table("ledger_accounts a").
+			Select(ledgerBalanceSQL).
+			Joins("LEFT JOIN ledger_entries e ON e.account_id = a.id").
+			Where("a.owner_type = ? AND a.owner_id = ? AND a.code = ?", ref.OwnerType, ref.OwnerID, ref.Code).
+			Scan(&balance.LedgerBalance).Error
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch %s ledger balance: %w", role, err)
+		}
+
+		balances = append(balances, balance)
+	}
+
+	return balances, nil
+}
+
+// lockTreasuryWallet loads a treasury account's wallet with a row lock for the
+// rest of tx.
+func (r *VendorStorage) lockTreasuryWallet(tx *gorm.DB, role string) (*adminModel.AdminWallet, error) {
+	var wallet adminModel.AdminWallet
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("email = ?", r.Treasury.email(role)).
+		First(&wallet).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("%w: %s", ErrTreasuryWalletNotFound, role)
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s wallet: %w", role, err)
+	}
+	return &wallet, nil
+}
+
+// creditTreasury records money collected into a treasury account.
+func (r *VendorStorage) creditTreasury(tx *gorm.DB, role string, amount money.Money) error {
+	return creditAdminWallet(tx, r.Treasury.email(role), amount)
+}
+
+// debitTreasury records money paid out of a treasury account.
+func (r *VendorStorage) debitTreasury(tx *gorm.DB, role string, amount money.Money) error {
+	return debitAdminWallet(tx, r.Treasury.email(role), amount)
+}
+
+// moveTreasury shifts amount from one treasury account to another.
+func (r *VendorStorage) moveTreasury(tx *gorm.DB, from, to string, amount money.Money) error {
+	if err := r.debitTreasury(tx, from, amount); err != nil {
+		return err
+	}
+	return r.creditTreasury(tx, to, amount)
+}
+
+// treasuryOpeningReference marks a treasury account's opening balance in the
+// ledger.
+func treasuryOpeningReference(role string) string {
+	return "treasury:" + role
+}
+
+// seedTreasuryOpeningBalance posts the opening balance for one treasury
+// account: whatever its wallet holds that its ledger account does not.
+func (r *VendorStorage) seedTreasuryOpeningBalance(tx *gorm.DB, role string) (bool, error) {
+	wallet, err := r.lockTreasuryWallet(tx, role)
+	if err != nil {
+		return false, err
+	}
+	walletBalance, err := models.AdminWalletBalance(wallet)
+	if err != nil {
+		return false, err
+	}
+
+	ref := treasuryLedgerAccount(role)
+	account, _, err := ledgerAccount(tx, ref)
+	if err != nil {
+		return false, fmt.Errorf("failed to open ledger account: %w", err)
+	}
+
+	var ledgerBalance money.Money
+	if err := tx.Table("ledger_accounts a").
+		Select(ledgerBalanceSQL).
+		Joins("LEFT JOIN ledger_entries e ON e.account_id = a.id").
+		Where("a.id = ?", account.ID).
+		Scan(&ledgerBalance).Error; err != nil {
+		return false, err
+	}
+
+	return postOpeningBalance(tx, treasuryOpeningReference(role), ref, walletBalance, ledgerBalance)
+}
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestTreasuryValidate(t *testing.T) {
+	configured := Treasury{
+		Escrow:         "escrow@zyra.com",
+		Fees:           "fees@zyra.com",
+		Refunds:        "refunds@zyra.com",
+		ClientPayments: "escrow@zyra.com",
+	}
+
+	tests := []struct {
+		name    string
+		edit    func(t *Treasury)
+		wantErr error
+		anyErr  bool
+	}{
+		{name: "configured", edit: func(t *Treasury) {}},
+		{name: "no client payments wallet", edit: func(t *Treasury) { t.ClientPayments = "" }, wantErr: ErrTreasuryNotConfigured},
+		{name: "no fees wallet", edit: func(t *Treasury) { t.Fees = "" }, wantErr: ErrTreasuryNotConfigured},
+		{name: "shared wallet", edit: func(t *Treasury) { t.Refunds = t.Fees }, anyErr: true},
+		{name: "escrow is not the client payments wallet", edit: func(t *Treasury) { t.ClientPayments = "admin@zyra.com" }, wantErr: ErrEscrowNotClientPayments},
+		{name: "client payments go to the fees wallet", edit: func(t *Treasury) { t.ClientPayments = t.Fees }, wantErr: ErrEscrowNotClientPayments},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			treasury := configured
+			tt.edit(&treasury)
+
+			err := treasury.validate()
+			switch {
+			case tt.anyErr:
+				if err == nil {
+					t.Fatal("validate succeeded, want an error")
+				}
+			case !errors.Is(err, tt.wantErr):
+				t.Fatalf("validate error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
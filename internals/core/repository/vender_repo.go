@@ -13,51 +13,147 @@ import (
 	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/models"
 	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/models/requests"
 	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/models/responses"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/money"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
 type VendorStorage struct {
-	DB *gorm.DB
+	DB       *gorm.DB
+	Treasury Treasury
 }
 
 type VendorRepository interface {
-	AddToVendorWallet(ctx context.Context, vendorId string, amount int64) error
 	CategoryExists(ctx context.Context, categoryId string) (bool, error)
 	CreateAdminWalletTransaction(ctx context.Context, newAdminWalletTransaction *adminModel.AdminWalletTransaction) error
 	CreateService(service *models.Service) error
 	CreateTransaction(ctx context.Context, newTransaction *clientModel.Transaction) error
 	FindVendorProfile(ctx context.Context, VendorID uuid.UUID) (*responses.VendorProfileResponse, error)
 	GetBookingById(ctx context.Context, bookingId string) (*adminModel.Booking, error)
+	GetBookingDetail(ctx context.Context, bookingID string) (*responses.BookingDetail, error)
+	GetBookingTransactions(ctx context.Context, bookingID string) ([]clientModel.Transaction, error)
+	RecordNewBooking(ctx context.Context, booking *adminModel.Booking, service *models.Service, transaction *clientModel.Transaction, installments []models.BookingInstallment) error
+	GetTransactionByID(ctx context.Context, transactionID string) (*clientModel.Transaction, error)
+	GetBookingSnapshot(ctx context.Context, bookingID string) (*models.BookingSnapshot, error)
+	GetServiceByTitle(ctx context.Context, vendorID string, serviceTitle string) (*models.Service, error)
 	GetServicesByVendor(ctx context.Context, vendorID string) ([]models.Service, error)
 	GetVendorBookings(ctx context.Context, vendorID string) ([]responses.BookingInfo, error)
 	GetVendorByID(vendorID string) (*auth.User, error)
+	GetUserByID(ctx context.Context, userID string) (*auth.User, error)
 	GetVendorDashboard(ctx context.Context, vendorID string) (*requests.VendorDashboard, error)
 	GetVendorStatus(vendorID string) (*auth.User, error)
-	GetWalletBalance(ctx context.Context, vendorID string) (int64, error)
 	HasRequestedCategory(ctx context.Context, vendorID string) (bool, error)
 	ListCategories(ctx context.Context) ([]models.Category, error)
-	RefundAmount(ctx context.Context, adminEmail string, clientID string, amount int) error
 	RequestCategory(ctx context.Context, vendorID, categoryId string) error
-	UpdateBookingStatus(ctx context.Context, bookingId string, status string) error
-	ReleasePaymentToVendor(ctx context.Context, vendorID string, price float64) error
 	UpdateCategoryRequestStatus(ctx context.Context, vendorID, categoryID, status string) error
 	UpdateService(serviceID uuid.UUID, updatedService models.Service) error
 	UpdateVendorPassword(vendorID string, newPassword string) error
 	UpdateVendorProfile(ctx context.Context, vendorID uuid.UUID, updateData map[string]interface{}) error
-	UpdateVendorApproval(ctx context.Context, bookingID string, status bool) error
-	MarkBookingAsConfirmedAndReleased(ctx context.Context, bookingID string) error
+	DecideBooking(ctx context.Context, booking *adminModel.Booking, decision string, event *models.BookingEvent) (money.Money, error)
 	GetVendorWallet(ctx context.Context, vendorID string) (*models.Wallet, error)
-	GetVendorTransactions(ctx context.Context, vendorID string) ([]clientModel.Transaction, error)
+	GetVendorTransactions(ctx context.Context, filter VendorTransactionFilter) ([]responses.VendorTransactionLine, error)
+	EachVendorTransaction(ctx context.Context, filter VendorTransactionFilter, fn func(*responses.VendorTransactionLine) error) error
+	GetPayoutCommissions(ctx context.Context, transactionIDs []uuid.UUID) ([]models.PayoutCommission, error)
 	GetMonthlyRevenue(ctx context.Context, vendorId string) ([]*responses.Result, error)
 	GetTopServices(ctx context.Context, vendorId string) ([]*responses.ServiceStat, error)
 	IsInCategory(vendorID string) (bool, error)
-}
-
-func NewVendorRepository(db *gorm.DB) VendorRepository {
+	MarkBookingCompleted(ctx context.Context, completion *models.BookingCompletion, event *models.BookingEvent) error
+	GetBookingCompletion(ctx context.Context, bookingID string) (*models.BookingCompletion, error)
+	OpenDispute(ctx context.Context, dispute *models.BookingDispute, event *models.BookingEvent) error
+	GetDisputeByID(ctx context.Context, disputeID string) (*models.BookingDispute, error)
+	ResolveDispute(ctx context.Context, disputeID string, resolution string, refundAmount money.Money, resolvedBy uuid.UUID, event *models.BookingEvent) (*models.BookingDispute, error)
+	CreateBookingMessage(ctx context.Context, message *models.BookingMessage) error
+	GetBookingMessages(ctx context.Context, bookingID string, before time.Time, limit int) ([]models.BookingMessage, error)
+	GetBookingMessagesAfter(ctx context.Context, bookingID string, after time.Time, afterID uuid.UUID, limit int) ([]models.BookingMessage, error)
+	MarkMessagesRead(ctx context.Context, bookingID string, readerID uuid.UUID) (int64, error)
+	GetUnreadMessageCounts(ctx context.Context, vendorID string) ([]responses.UnreadCount, error)
+	GetCalendarFeed(ctx context.Context, vendorID string) (*models.CalendarFeed, error)
+	GetCalendarFeedByToken(ctx context.Context, token string) (*models.CalendarFeed, error)
+	SaveCalendarFeed(ctx context.Context, feed *models.CalendarFeed) error
+	GetVendorCalendarBookings(ctx context.Context, vendorID string) ([]responses.CalendarBooking, error)
+	CreateExternalCalendar(ctx context.Context, calendar *models.ExternalCalendar, blocks []models.BlockedTime) error
+	GetExternalCalendar(ctx context.Context, vendorID, calendarID string) (*models.ExternalCalendar, error)
+	ListExternalCalendars(ctx context.Context, vendorID string) ([]models.ExternalCalendar, error)
+	ListSubscribedCalendars(ctx context.Context) ([]models.ExternalCalendar, error)
+	DeleteExternalCalendar(ctx context.Context, vendorID, calendarID string) error
+	ReplaceBlockedTimes(ctx context.Context, calendar *models.ExternalCalendar, blocks []models.BlockedTime) error
+	RecordCalendarSyncError(ctx context.Context, calendarID uuid.UUID, syncErr string) error
+	GetBlockedTimes(ctx context.Context, vendorID string, from, to time.Time) ([]models.BlockedTime, error)
+	GetVendorBusyBookings(ctx context.Context, vendorID string, from, to time.Time) ([]responses.CalendarBooking, error)
+	CreateBookingEvent(ctx context.Context, event *models.BookingEvent) error
+	GetBookingEvents(ctx context.Context, bookingID string) ([]models.BookingEvent, error)
+	TransitionBookingStatus(ctx context.Context, bookingID string, fromStatuses []string, newStatus string, event *models.BookingEvent) error
+	GetServiceByID(ctx context.Context, serviceID string) (*models.Service, error)
+	GetInstantBookingRules(ctx context.Context, serviceID uuid.UUID) ([]models.InstantBookingRule, error)
+	ReplaceInstantBookingRules(ctx context.Context, serviceID uuid.UUID, rules []models.InstantBookingRule) error
+	CountBookingsOnDate(ctx context.Context, vendorID uuid.UUID, date time.Time, excludeBookingID uuid.UUID) (int64, error)
+	GetPaymentMilestones(ctx context.Context, serviceID uuid.UUID) ([]models.PaymentMilestone, error)
+	ReplacePaymentMilestones(ctx context.Context, serviceID uuid.UUID, milestones []models.PaymentMilestone) error
+	GetBookingInstallments(ctx context.Context, bookingID string) ([]models.BookingInstallment, error)
+	PayInstallment(ctx context.Context, installmentID string, clientID uuid.UUID) (*models.BookingInstallment, error)
+	CreateOvertimeCharge(ctx context.Context, charge *models.OvertimeCharge) error
+	GetOvertimeCharge(ctx context.Context, chargeID string) (*models.OvertimeCharge, error)
+	ListOvertimeCharges(ctx context.Context, bookingID string) ([]models.OvertimeCharge, error)
+	DeclineOvertimeCharge(ctx context.Context, chargeID string) error
+	CollectOvertimeCharge(ctx context.Context, chargeID string) (*models.OvertimeCharge, error)
+	CreateQuote(ctx context.Context, quote *models.Quote) error
+	GetQuote(ctx context.Context, quoteID string) (*models.Quote, error)
+	SubmitQuote(ctx context.Context, quoteID string, items []models.QuoteLineItem, note string, expiresAt time.Time) error
+	DeclineQuote(ctx context.Context, quoteID string, declinedBy, reason string) error
+	AcceptQuote(ctx context.Context, quoteID string, service *models.Service, event *models.BookingEvent) (*adminModel.Booking, error)
+	ListVendorQuotes(ctx context.Context, filter QuoteFilter) ([]models.Quote, int64, error)
+	CreateWaitlistEntry(ctx context.Context, entry *models.WaitlistEntry) error
+	LeaveWaitlist(ctx context.Context, entryID string, clientID uuid.UUID) (*models.WaitlistEntry, error)
+	ListWaitlistEntries(ctx context.Context, vendorID, clientID uuid.UUID) ([]models.WaitlistEntry, error)
+	CountWaitlistAhead(ctx context.Context, entry *models.WaitlistEntry) (int64, error)
+	OfferNextWaitlistEntry(ctx context.Context, serviceID uuid.UUID, date time.Time, expiresAt time.Time) (*models.WaitlistEntry, error)
+	ExpireWaitlistOffers(ctx context.Context, now time.Time) ([]models.WaitlistEntry, error)
+	ClaimWaitlistOffer(ctx context.Context, serviceID, clientID uuid.UUID, date time.Time) error
+	GetBundleDiscounts(ctx context.Context, vendorID uuid.UUID) ([]models.BundleDiscount, error)
+	ReplaceBundleDiscounts(ctx context.Context, vendorID uuid.UUID, discounts []models.BundleDiscount) error
+	CreateBundleBooking(ctx context.Context, bundle *models.BundleBooking, installments []models.BookingInstallment) error
+	GetBundleBooking(ctx context.Context, bundleID string) (*models.BundleBooking, error)
+	GetBundleItemByBookingID(ctx context.Context, bookingID uuid.UUID) (*models.BundleItem, error)
+	DecideBundleBooking(ctx context.Context, bundle *models.BundleBooking, bookings []*adminModel.Booking, decision string, event *models.BookingEvent) error
+	BlockClient(ctx context.Context, block *models.ClientBlock) ([]models.WaitlistEntry, error)
+	UnblockClient(ctx context.Context, vendorID, clientID uuid.UUID) error
+	IsClientBlocked(ctx context.Context, vendorID, clientID uuid.UUID) (bool, error)
+	ListBlockedClients(ctx context.Context, vendorID uuid.UUID) ([]responses.BlockedClient, error)
+	GetLedgerStatement(ctx context.Context, ownerType string, ownerID uuid.UUID, limit int) (money.Money, []responses.LedgerStatementLine, error)
+	ReconcileWallets(ctx context.Context) ([]responses.WalletDiscrepancy, error)
+	MergeDuplicateClientWallets(ctx context.Context) (int, error)
+	BackfillLedgerOpeningBalances(ctx context.Context) (int, error)
+	RequestWithdrawal(ctx context.Context, withdrawal *models.Withdrawal, dailyLimit money.Money) error
+	GetWithdrawal(ctx context.Context, withdrawalID string) (*models.Withdrawal, error)
+	ListWithdrawals(ctx context.Context, vendorID uuid.UUID, status string) ([]models.Withdrawal, error)
+	ApproveWithdrawal(ctx context.Context, withdrawalID string, adminID uuid.UUID) (*models.Withdrawal, error)
+	RejectWithdrawal(ctx context.Context, withdrawalID string, adminID uuid.UUID, reason string) (*models.Withdrawal, error)
+	CompleteWithdrawal(ctx context.Context, withdrawalID string, providerReference string) (*models.Withdrawal, error)
+	FailWithdrawal(ctx context.Context, withdrawalID string, failureReason string) (*models.Withdrawal, error)
+	SaveCommissionRule(ctx context.Context, rule *models.CommissionRule) error
+	ListCommissionRules(ctx context.Context) ([]models.CommissionRule, error)
+	DeleteCommissionRule(ctx context.Context, ruleID string) error
+	SetVendorTier(ctx context.Context, vendorID uuid.UUID, tier string) error
+	GetInvoice(ctx context.Context, invoiceID string) (*models.Invoice, error)
+	GetInvoiceByToken(ctx context.Context, token string) (*models.Invoice, error)
+	ListInvoices(ctx context.Context, filter InvoiceFilter) ([]models.Invoice, error)
+	GetVendorBusinessProfile(ctx context.Context, vendorID uuid.UUID) (*models.VendorBusinessProfile, error)
+	SaveVendorBusinessProfile(ctx context.Context, profile *models.VendorBusinessProfile) error
+	CheckTreasury(ctx context.Context) error
+	GetTreasuryBalances(ctx context.Context) ([]responses.TreasuryBalance, error)
+	ListSettleablePayouts(ctx context.Context, before time.Time) ([]models.BookingCompletion, error)
+	ListQueuedPayouts(ctx context.Context, before time.Time) ([]models.QueuedPayout, error)
+	RecordSettlementFailure(ctx context.Context, bookingID uuid.UUID, reason string) error
+	SettleVendor(ctx context.Context, vendorID uuid.UUID, periodStart, periodEnd time.Time, items []SettlementItem) (*models.Settlement, error)
+	ListSettlements(ctx context.Context, vendorID uuid.UUID, limit int) ([]models.Settlement, error)
+	ListFundHolds(ctx context.Context, vendorID uuid.UUID) ([]models.FundHold, error)
+}
+
+func NewVendorRepository(db *gorm.DB, treasury Treasury) VendorRepository {
 	return &VendorStorage{
-		DB: db,
+		DB:       db,
+		Treasury: treasury,
 	}
 }
 
@@ -190,6 +286,9 @@ func (r *VendorStorage) UpdateVendorProfile(ctx context.Context, vendorID uuid.U
 }
 
 func (r *VendorStorage) CreateService(service *models.Service) error {
+	if err := service.SetSharedPrices(); err != nil {
+		return err
+	}
 	return r.DB.Create(service).Error
 }
 
@@ -212,6 +311,11 @@ func (r *VendorStorage) UpdateService(serviceID uuid.UUID, updatedService models
 	service.ServiceDuration = updatedService.ServiceDuration
 	service.ServicePrice = updatedService.ServicePrice
 	service.AdditionalHourPrice = updatedService.AdditionalHourPrice
+	service.MinNoticeHours = updatedService.MinNoticeHours
+	service.MaxAdvanceDays = updatedService.MaxAdvanceDays
+	if err := service.SetSharedPrices(); err != nil {
+		return err
+	}
 
 	if err := r.DB.Save(&service).Error; err != nil {
 		return err
@@ -229,6 +333,15 @@ func (r *VendorStorage) GetVendorByID(vendorID string) (*auth.User, error) {
 	return &vendor, nil
 }
 
+// GetUserByID looks up any user, whatever their role.
+func (r *VendorStorage) GetUserByID(ctx context.Context, userID string) (*auth.User, error) {
+	var user auth.User
+	if err := r.DB.WithContext(ctx).Where("user_id = ?", userID).First(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
 func (r *VendorStorage) UpdateVendorPassword(vendorID string, newPassword string) error {
 	return r.DB.Model(&auth.User{}).Where("user_id = ?", vendorID).Update("password", newPassword).Error
 }
@@ -357,79 +470,92 @@ func (r *VendorStorage) GetBookingById(ctx context.Context, bookingId string) (*
 	return &booking, nil
 }
 
-func (r *VendorStorage) UpdateBookingStatus(ctx context.Context, bookingId string, status string) error {
-	return r.DB.WithContext(ctx).Model(&adminModel.Booking{}).Where("booking_id = ?", bookingId).Update("status", status).Error
-}
+func (r *VendorStorage) GetBookingDetail(ctx context.Context, bookingID string) (*responses.BookingDetail, error) {
+	var detail responses.BookingDetail
 
-func (r *VendorStorage) AddToVendorWallet(ctx context.Context, vendorId string, amount int64) error {
-	var wallet models.Wallet
-
-	err := r.DB.WithContext(ctx).Where("vendor_id = ?", vendorId).First(&wallet).Error
-
-	vendorUUID, _ := uuid.Parse(vendorId)
-	if errors.Is(err, gorm.ErrRecordNotFound) {
-		newWallet := models.Wallet{
-			VendorID:      vendorUUID,
-			WalletBalance: amount,
-		}
-		return r.DB.WithContext(ctx).Create(&newWallet).Error
-	} else if err != nil {
-		return err
+	err := r.DB.WithContext(ctx).
+		Table("bookings b").
+		Select(`
+			b.booking_id,
+			b.client_id,
+			b.vendor_id,
+			ud.first_name AS client_first_name,
+			ud.last_name AS client_last_name,
+			u.email AS client_email,
+			ud.phone AS client_phone,
+			b.service,
+			b.date,
+			b.price,
+			b.status,
+			b.is_vendor_approved,
+			b.is_client_approved,
+			b.is_fund_released,
+			b.created_at,
+			b.updated_at
+		`).
+		Joins("JOIN users u ON u.user_id = b.client_id").
+		Joins("LEFT JOIN user_details ud ON ud.user_id = b.client_id").
+		Where("b.booking_id = ?", bookingID).
+		Take(&detail).Error
+	if err != nil {
+		return nil, err
 	}
 
-	return r.DB.WithContext(ctx).
-		Model(&wallet).
-		Update("wallet_balance", gorm.Expr("wallet_balance + ?", amount)).Error
-
+	return &detail, nil
 }
 
-func (r *VendorStorage) GetWalletBalance(ctx context.Context, vendorID string) (int64, error) {
-	var wallet models.Wallet
-	err := r.DB.WithContext(ctx).Where("vendor_id = ?", vendorID).First(&wallet).Error
+func (r *VendorStorage) GetServiceByTitle(ctx context.Context, vendorID string, serviceTitle string) (*models.Service, error) {
+	var service models.Service
+	err := r.DB.WithContext(ctx).
+		Where("vendor_id = ? AND service_title = ?", vendorID, serviceTitle).
+		Order("created_at DESC").
+		First(&service).Error
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
-	return wallet.WalletBalance, nil
+	return &service, nil
 }
 
-func (r *VendorStorage) RefundAmount(ctx context.Context, adminEmail string, clientID string, amount int) error {
-	var wallet models.Wallet
-	var adminWallet adminModel.AdminWallet
-
-	err := r.DB.WithContext(ctx).Model(&adminWallet).Where("email = ?", adminEmail).Update("balance", gorm.Expr("balance - ?", amount)).Error
-	if err != nil {
+// refundFromEscrow returns money held in escrow for the booking to the
+// client's wallet by way of the refunds account, within the caller's
+// transaction. It records the client, admin and ledger transactions and the
+// client's credit note.
+func (r *VendorStorage) refundFromEscrow(tx *gorm.DB, clientID, bookingID uuid.UUID, amount money.Money, at time.Time) error {
+	if err := r.moveTreasury(tx, TreasuryEscrow, TreasuryRefunds, amount); err != nil {
+		return err
+	}
+	if err := r.debitTreasury(tx, TreasuryRefunds, amount); err != nil {
 		return err
 	}
 
-	err = r.DB.WithContext(ctx).Model(&adminWallet).Where("email = ?", adminEmail).Update("total_withdrawals", gorm.Expr("total_withdrawals + ?", amount)).Error
+	if err := creditWallet(tx, clientWalletOwner(clientID), amount); err != nil {
+		return err
+	}
 
+	transactionID, err := createUserTransaction(tx, clientID, "Vendor Booking", "refunded", amount, at)
 	if err != nil {
-		return nil
+		return err
 	}
-
-	err = r.DB.WithContext(ctx).Model(&wallet).Where("client_id = ?", clientID).First(&wallet).Error
-
-	clientUUID, _ := uuid.Parse(clientID)
-
-	if errors.Is(err, gorm.ErrRecordNotFound) {
-		newWallet := models.Wallet{
-			ClientID:      clientUUID,
-			WalletBalance: int64(amount),
-		}
-
-		return r.DB.WithContext(ctx).Create(&newWallet).Error
-	} else if err != nil {
+	if err := linkBookingTransaction(tx, transactionID, bookingID); err != nil {
 		return err
 	}
 
-	err = r.DB.WithContext(ctx).Model(wallet).Where("client_id = ?", clientID).Update("wallet_balance", gorm.Expr("wallet_balance + ?", amount)).Error
-
-	if err != nil {
+	var booking adminModel.Booking
+	if err := tx.Where("booking_id = ?", bookingID).First(&booking).Error; err != nil {
+		return fmt.Errorf("failed to find booking: %w", err)
+	}
+	if err := issueRefundCreditNote(tx, &booking, transactionID, amount); err != nil {
 		return err
 	}
 
-	return r.DB.WithContext(ctx).Model(wallet).Where("client_id = ?", clientID).Update("total_deposits", gorm.Expr("total_deposits + ?", amount)).Error
+	if err := recordAdminTransaction(tx, "Vendor Booking", "refunded", amount, at); err != nil {
+		return err
+	}
 
+	return postLedger(tx, "refund", bookingID.String(), "Booking refund",
+		ledgerLeg{From: treasuryLedgerAccount(TreasuryEscrow), To: treasuryLedgerAccount(TreasuryRefunds), Amount: amount},
+		ledgerLeg{From: treasuryLedgerAccount(TreasuryRefunds), To: clientWalletAccount(clientID), Amount: amount},
+	)
 }
 
 func (r *VendorStorage) CreateTransaction(ctx context.Context, newTransaction *clientModel.Transaction) error {
@@ -441,128 +567,154 @@ func (r *VendorStorage) CreateAdminWalletTransaction(ctx context.Context, newAdm
 	return r.DB.WithContext(ctx).Create(newAdminWalletTransaction).Error
 }
 
-func (r *VendorStorage) UpdateVendorApproval(ctx context.Context, bookingID string, status bool) error {
-	return r.DB.WithContext(ctx).Model(&adminModel.Booking{}).Where("booking_id = ?", bookingID).Update("is_vendor_approved", status).Error
+// DecideBooking moves a pending booking to approved or rejected together with
+// the money that goes with the decision, so a failure part way leaves the
+// booking pending and untouched. Rejecting refunds everything held for the
+// booking to the client; approving marks the booking vendor-approved and
+// queues the installments released on approval for the vendor's next
+// settlement. It returns the amount refunded or queued.
+func (r *VendorStorage) DecideBooking(ctx context.Context, booking *adminModel.Booking, decision string, event *models.BookingEvent) (money.Money, error) {
+	var moved money.Money
+
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		amount, err := r.decideBooking(tx, booking, decision, event)
+		if err != nil {
+			return err
+		}
+		moved = amount
+
+		return syncFundHolds(tx, booking.VendorID)
+	})
+	if err != nil {
+		return money.Money{}, err
+	}
+
+	return moved, nil
 }
 
-func (r *VendorStorage) ReleasePaymentToVendor(ctx context.Context, vendorID string, price float64) error {
-	tx := r.DB.WithContext(ctx).Begin() // Start transaction
+// decideBooking applies the decision and its money movements to one booking
+// within the caller's transaction.
+func (r *VendorStorage) decideBooking(tx *gorm.DB, booking *adminModel.Booking, decision string, event *models.BookingEvent) (money.Money, error) {
+	bookingID := booking.BookingID.String()
+	if err := transitionBookingStatus(tx, bookingID, []string{"pending"}, decision, event); err != nil {
+		return money.Money{}, err
+	}
 
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
+	now := time.Now()
+	if decision == "rejected" {
+		amount, held, err := heldBookingFunds(tx, booking)
+		if err != nil {
+			return money.Money{}, err
+		}
+		if amount.IsPositive() {
+			if err := r.refundFromEscrow(tx, booking.ClientID, booking.BookingID, amount, now); err != nil {
+				return money.Money{}, err
+			}
+		}
+		if err := refundInstallments(tx, installmentIDs(held)); err != nil {
+			return money.Money{}, err
 		}
-	}()
+		return amount, nil
+	}
 
-	var adminWallet adminModel.AdminWallet
-	if err := tx.Where("email = ?", "admin@gmail.com").First(&adminWallet).Error; err != nil {
-		tx.Rollback()
-		return fmt.Errorf("failed to fetch admin wallet: %w", err)
+	if err := tx.Model(&adminModel.Booking{}).
+		Where("booking_id = ?", bookingID).
+		Update("is_vendor_approved", true).Error; err != nil {
+		return money.Money{}, fmt.Errorf("failed to update vendor approval: %w", err)
 	}
 
-	if adminWallet.Balance < price {
-		tx.Rollback()
-		return fmt.Errorf("admin wallet has insufficient balance")
+	return r.releaseApprovalInstallments(tx, booking, decision, now)
+}
+
+// payFromEscrow pays the vendor commission.Net out of escrow and moves the
+// platform's commission to the fees account, recording the wallet, admin and
+// ledger transactions and the commission itself. The vendor's transaction is
+// recorded under purpose, linked to the bookings it pays for, and its ID
+// filled in on commission.
+func (r *VendorStorage) payFromEscrow(tx *gorm.DB, commission *models.PayoutCommission, purpose string, at time.Time, bookingIDs ...uuid.UUID) error {
+	escrowWallet, err := r.lockTreasuryWallet(tx, TreasuryEscrow)
+	if err != nil {
+		return err
 	}
 
-	var vendorWallet models.Wallet
-	vendorUUID, err := uuid.Parse(vendorID)
+	escrowBalance, err := models.AdminWalletBalance(escrowWallet)
 	if err != nil {
-		tx.Rollback()
-		return fmt.Errorf("invalid vendor ID: %w", err)
+		return fmt.Errorf("invalid escrow wallet balance: %w", err)
 	}
-	if err := tx.Where("vendor_id = ?", vendorUUID).First(&vendorWallet).Error; err != nil {
-		tx.Rollback()
-		return fmt.Errorf("failed to fetch vendor wallet: %w", err)
+	if short, err := escrowBalance.LessThan(commission.Gross); err != nil || short {
+		return fmt.Errorf("escrow wallet has insufficient balance")
 	}
 
-	adminWallet.Balance -= price
-	adminWallet.TotalWithdrawals += price
-	if err := tx.Save(&adminWallet).Error; err != nil {
-		tx.Rollback()
-		return fmt.Errorf("failed to update admin wallet: %w", err)
+	if _, err := lockWallet(tx, vendorWalletOwner(commission.VendorID)); err != nil {
+		return fmt.Errorf("failed to fetch vendor wallet: %w", err)
 	}
 
-	vendorWallet.WalletBalance += int64(price)
-	vendorWallet.TotalDeposits += int64(price)
-	if err := tx.Save(&vendorWallet).Error; err != nil {
-		tx.Rollback()
-		return fmt.Errorf("failed to update vendor wallet: %w", err)
+	// The net amount moves to the vendor and the platform's commission to
+	// the fees account.
+	if err := r.debitTreasury(tx, TreasuryEscrow, commission.Net); err != nil {
+		return err
 	}
 
-	adminWalletTxn := &adminModel.AdminWalletTransaction{
-		Date:   time.Now(),
-		Type:   "Vendor Payout",
-		Amount: price,
-		Status: "succeded",
-	}
-	if err := tx.Create(adminWalletTxn).Error; err != nil {
-		tx.Rollback()
-		return fmt.Errorf("failed to create admin wallet transaction: %w", err)
+	if err := creditWallet(tx, vendorWalletOwner(commission.VendorID), commission.Net); err != nil {
+		return err
 	}
 
-	vendorTxn := &clientModel.Transaction{
-		UserID:        vendorWallet.VendorID,
-		Purpose:       "Vendor Payout",
-		AmountPaid:    int(price),
-		PaymentMethod: "wallet",
-		PaymentStatus: "Paid",
-		DateOfPayment: time.Now(),
+	if err := recordAdminTransaction(tx, "Vendor Payout", "succeded", commission.Net, at); err != nil {
+		return err
 	}
-	if err := tx.Create(vendorTxn).Error; err != nil {
-		tx.Rollback()
-		return fmt.Errorf("failed to create vendor transaction: %w", err)
+
+	transactionID, err := createUserTransaction(tx, commission.VendorID, purpose, "Paid", commission.Net, at)
+	if err != nil {
+		return err
 	}
 
-	return tx.Commit().Error
-}
+	if err := linkBookingTransaction(tx, transactionID, bookingIDs...); err != nil {
+		return err
+	}
 
-func (r *VendorStorage) MarkBookingAsConfirmedAndReleased(ctx context.Context, bookingID string) error {
-	tx := r.DB.WithContext(ctx).Begin()
+	commission.TransactionID = transactionID
+	if err := tx.Create(commission).Error; err != nil {
+		return fmt.Errorf("failed to record commission: %w", err)
+	}
 
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
+	if commission.Net.IsPositive() {
+		leg := ledgerLeg{From: treasuryLedgerAccount(TreasuryEscrow), To: vendorWalletAccount(commission.VendorID), Amount: commission.Net}
+		if err := postLedgerAt(tx, at, "vendor_payout", commission.Reference, purpose, leg); err != nil {
+			return err
 		}
-	}()
-
-	var booking adminModel.Booking
-	if err := tx.Where("booking_id = ?", bookingID).First(&booking).Error; err != nil {
-		tx.Rollback()
-		return fmt.Errorf("failed to find booking: %w", err)
 	}
 
-	booking.IsVendorApproved = true
-	booking.IsClientApproved = true
-	booking.IsFundReleased = true
-	booking.UpdatedAt = time.Now()
+	if commission.Fee.IsPositive() {
+		if err := r.moveTreasury(tx, TreasuryEscrow, TreasuryFees, commission.Fee); err != nil {
+			return err
+		}
+
+		if err := recordAdminTransaction(tx, "Platform Commission", "succeded", commission.Fee, at); err != nil {
+			return err
+		}
 
-	if err := tx.Save(&booking).Error; err != nil {
-		tx.Rollback()
-		return fmt.Errorf("failed to update booking: %w", err)
+		leg := ledgerLeg{From: treasuryLedgerAccount(TreasuryEscrow), To: treasuryLedgerAccount(TreasuryFees), Amount: commission.Fee}
+		if err := postLedger(tx, "platform_commission", commission.Reference, "Platform Commission", leg); err != nil {
+			return err
+		}
 	}
 
-	return tx.Commit().Error
+	return nil
 }
 
 func (r *VendorStorage) GetVendorWallet(ctx context.Context, vendorID string) (*models.Wallet, error) {
-	var wallet models.Wallet
+	vendorUUID, err := uuid.Parse(vendorID)
+	if err != nil {
+		return nil, err
+	}
+	owner := vendorWalletOwner(vendorUUID)
 
-	err := r.DB.Where("vendor_id = ?", vendorID).First(&wallet).Error
+	var wallet models.Wallet
+	err = owner.query(r.DB.WithContext(ctx)).First(&wallet).Error
 
 	if errors.Is(err, gorm.ErrRecordNotFound) {
-		vendorUUID, err := uuid.Parse(vendorID)
-		if err != nil {
-			return nil, err
-		}
-		newWallet := &models.Wallet{
-			VendorID:         vendorUUID,
-			WalletBalance:    0,
-			TotalDeposits:    0,
-			TotalWithdrawals: 0,
-		}
-
-		err = r.DB.Create(newWallet).Error
+		wallet = owner.newWallet()
+		err = r.DB.WithContext(ctx).Create(&wallet).Error
 		if err != nil {
 			return nil, err
 		}
@@ -574,11 +726,75 @@ func (r *VendorStorage) GetVendorWallet(ctx context.Context, vendorID string) (*
 	return &wallet, nil
 }
 
-func (r *VendorStorage) GetVendorTransactions(ctx context.Context, vendorID string) ([]clientModel.Transaction, error) {
-	var transactions []clientModel.Transaction
-
-	err := r.DB.WithContext(ctx).Where("user_id = ?", vendorID).Find(&transactions).Error
-
+// VendorTransactionFilter narrows a vendor's transactions. From is inclusive
+// and To exclusive; amounts are in whole units. Zero values match everything.
+type VendorTransactionFilter struct {
+	VendorID  uuid.UUID
+	From      *time.Time
+	To        *time.Time
+	Purpose   string
+	Status    string
+	MinAmount *int64
+	MaxAmount *int64
+}
+
+// vendorTransactionQuery selects the vendor's transactions, oldest first,
+// with the balance of the vendor's wallet after each one. The balance comes
+// from the wallet's ledger account, so payouts, withdrawals and their
+// reversals all count whether or not they appear as transactions. The
+// vendor's ledger entries and transactions are merged into one timeline, with
+// entries first at the same instant so a payout's balance includes it, and
+// the filter applies afterwards so balances stay the same whichever rows are
+// shown.
+func (r *VendorStorage) vendorTransactionQuery(ctx context.Context, filter VendorTransactionFilter) *gorm.DB {
+	db := r.DB.WithContext(ctx)
+
+	running := db.Raw(`
+		SELECT transaction_id, purpose, amount_paid, payment_status, date_of_payment, is_transaction,
+			SUM(delta) OVER (ORDER BY date_of_payment ASC, is_transaction ASC, transaction_id ASC) AS running_balance
+		FROM (
+			SELECT NULL::uuid AS transaction_id, NULL AS purpose, NULL::bigint AS amount_paid,
+				NULL AS payment_status, e.created_at AS date_of_payment, FALSE AS is_transaction,
+				CASE WHEN e.direction = a.normal_side THEN e.amount ELSE -e.amount END AS delta
+			FROM ledger_entries e
+			JOIN ledger_accounts a ON a.id = e.account_id
+			WHERE a.owner_type = ? AND a.owner_id = ? AND a.code = ?
+			UNION ALL
+			SELECT transaction_id, purpose, amount_paid, payment_status, date_of_payment, TRUE, 0
+			FROM transactions
+			WHERE user_id = ?
+		) AS timeline`,
+		LedgerOwnerVendor, filter.VendorID, ledgerCodeWallet, filter.VendorID)
+
+	query := db.Table("(?) AS running", running).
+		Select("transaction_id, purpose, amount_paid, payment_status, date_of_payment, running_balance").
+		Where("is_transaction")
+	if filter.From != nil {
+		query = query.Where("date_of_payment >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("date_of_payment < ?", *filter.To)
+	}
+	if filter.Purpose != "" {
+		query = query.Where("LOWER(purpose) = LOWER(?)", filter.Purpose)
+	}
+	if filter.Status != "" {
+		query = query.Where("LOWER(payment_status) = LOWER(?)", filter.Status)
+	}
+	if filter.MinAmount != nil {
+		query = query.Where("amount_paid >= ?", *filter.MinAmount)
+	}
+	if filter.MaxAmount != nil {
+		query = query.Where("amount_paid <= ?", *filter.MaxAmount)
+	}
+
+	return query.Order("date_of_payment ASC, transaction_id ASC")
+}
+
+func (r *VendorStorage) GetVendorTransactions(ctx context.Context, filter VendorTransactionFilter) ([]responses.VendorTransactionLine, error) {
+	var transactions []responses.VendorTransactionLine
+
+	err := r.vendorTransactionQuery(ctx, filter).Scan(&transactions).Error
 	if err != nil {
 		return nil, err
 	}
@@ -586,6 +802,30 @@ func (r *VendorStorage) GetVendorTransactions(ctx context.Context, vendorID stri
 	return transactions, nil
 }
 
+// EachVendorTransaction calls fn with each matching transaction in turn,
+// reading them from the database as it goes rather than all at once.
+func (r *VendorStorage) EachVendorTransaction(ctx context.Context, filter VendorTransactionFilter, fn func(*responses.VendorTransactionLine) error) error {
+	query := r.vendorTransactionQuery(ctx, filter)
+
+	rows, err := query.Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var line responses.VendorTransactionLine
+		if err := query.ScanRows(rows, &line); err != nil {
+			return err
+		}
+		if err := fn(&line); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
 func (r *VendorStorage) GetMonthlyRevenue(ctx context.Context, vendorId string) ([]*responses.Result, error) {
 	var results []*responses.Result
 
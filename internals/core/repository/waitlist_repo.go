@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrWaitlistEntryNotFound = errors.New("waitlist entry not found")
+
+// notBlockedByVendor keeps waitlist entries whose client the vendor has not
+// blocked since they joined.
+const notBlockedByVendor = `NOT EXISTS (
+	SELECT 1 FROM client_blocks cb
+	WHERE cb.vendor_id = waitlist_entries.vendor_id AND cb.client_id = waitlist_entries.client_id)`
+
+func (r *VendorStorage) CreateWaitlistEntry(ctx context.Context, entry *models.WaitlistEntry) error {
+	return r.DB.WithContext(ctx).Create(entry).Error
+}
+
+// LeaveWaitlist takes the client's active entry off the waitlist and returns
+// it as it was before leaving, so callers can tell whether an outstanding
+// offer was given up.
+func (r *VendorStorage) LeaveWaitlist(ctx context.Context, entryID string, clientID uuid.UUID) (*models.WaitlistEntry, error) {
+	var entry models.WaitlistEntry
+
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND client_id = ? AND status IN ?", entryID, clientID, []string{"waiting", "offered"}).
+			First(&entry).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrWaitlistEntryNotFound
+		} else if err != nil {
+			return err
+		}
+
+		return tx.Model(&models.WaitlistEntry{}).
+			Where("id = ?", entry.ID).
+			Update("status", "left").Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+// ListWaitlistEntries returns active entries for a vendor or a client,
+// whichever ID is set.
+func (r *VendorStorage) ListWaitlistEntries(ctx context.Context, vendorID, clientID uuid.UUID) ([]models.WaitlistEntry, error) {
+	query := r.DB.WithContext(ctx).Where("status IN ?", []string{"waiting", "offered"})
+	if vendorID != uuid.Nil {
+		query = query.Where("vendor_id = ?", vendorID)
+	}
+	if clientID != uuid.Nil {
+		query = query.Where("client_id = ?", clientID)
+	}
+
+	var entries []models.WaitlistEntry
+	err := query.Order("date ASC, created_at ASC").Find(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (r *VendorStorage) CountWaitlistAhead(ctx context.Context, entry *models.WaitlistEntry) (int64, error) {
+	var count int64
+	err := r.DB.WithContext(ctx).
+		Model(&models.WaitlistEntry{}).
+		Where("service_id = ? AND date = ? AND status = ? AND created_at < ?", entry.ServiceID, entry.Date, "waiting", entry.CreatedAt).
+		Count(&count).Error
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// OfferNextWaitlistEntry offers a freed slot to the oldest waiting client for
+// the service and day, skipping clients the vendor has since blocked. Entries
+// for the day are locked so that only one offer is outstanding at a time; it
+// returns nil when an offer is already out or nobody is waiting.
+func (r *VendorStorage) OfferNextWaitlistEntry(ctx context.Context, serviceID uuid.UUID, date time.Time, expiresAt time.Time) (*models.WaitlistEntry, error) {
+	var offered *models.WaitlistEntry
+
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var entries []models.WaitlistEntry
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("service_id = ? AND date = ? AND status IN ?", serviceID, date, []string{"waiting", "offered"}).
+			Where(notBlockedByVendor).
+			Order("created_at ASC").
+			Find(&entries).Error; err != nil {
+			return err
+		}
+
+		for _, entry := range entries {
+			if entry.Status == "offered" {
+				return nil
+			}
+		}
+
+		if len(entries) == 0 {
+			return nil
+		}
+
+		now := time.Now()
+		entry := entries[0]
+		entry.Status = "offered"
+		entry.OfferedAt = &now
+		entry.OfferExpiresAt = &expiresAt
+		if err := tx.Model(&entry).Updates(map[string]interface{}{
+			"status":           entry.Status,
+			"offered_at":       now,
+			"offer_expires_at": expiresAt,
+		}).Error; err != nil {
+			return err
+		}
+
+		offered = &entry
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return offered, nil
+}
+
+func (r *VendorStorage) ExpireWaitlistOffers(ctx context.Context, now time.Time) ([]models.WaitlistEntry, error) {
+	var expired []models.WaitlistEntry
+	err := r.DB.WithContext(ctx).Raw(`
+		UPDATE waitlist_entries
+		SET status = 'expired', updated_at = ?
+		WHERE status = 'offered' AND offer_expires_at <= ?
+		RETURNING *`, now, now).
+		Scan(&expired).Error
+	if err != nil {
+		return nil, err
+	}
+	return expired, nil
+}
+
+// ClaimWaitlistOffer marks the client's outstanding offer for the service and
+// day as taken up. Offers to clients the vendor has blocked are not claimed.
+func (r *VendorStorage) ClaimWaitlistOffer(ctx context.Context, serviceID, clientID uuid.UUID, date time.Time) error {
+	return r.DB.WithContext(ctx).
+		Model(&models.WaitlistEntry{}).
+		Where("service_id = ? AND client_id = ? AND date = ? AND status = ?", serviceID, clientID, date, "offered").
+		Where(notBlockedByVendor).
+		Update("status", "claimed").Error
+}
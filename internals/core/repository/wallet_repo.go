@@ -0,0 +1,242 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	adminModel "github.com/AthulKrishna2501/zyra-admin-service/internals/core/models"
+	clientModel "github.com/AthulKrishna2501/zyra-client-service/internals/core/models"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/models"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/money"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// walletOwner identifies whose wallet a query touches. It can only be built
+// with clientWalletOwner or vendorWalletOwner, so a client's ID is never used
+// to look up a vendor's wallet or the other way round.
+type walletOwner struct {
+	kind string
+	id   uuid.UUID
+}
+
+func clientWalletOwner(clientID uuid.UUID) walletOwner {
+	return walletOwner{kind: models.WalletOwnerClient, id: clientID}
+}
+
+func vendorWalletOwner(vendorID uuid.UUID) walletOwner {
+	return walletOwner{kind: models.WalletOwnerVendor, id: vendorID}
+}
+
+// query scopes tx to the owner's wallet row.
+func (o walletOwner) query(tx *gorm.DB) *gorm.DB {
+	return tx.Model(&models.Wallet{}).Where("owner_type = ? AND owner_id = ?", o.kind, o.id)
+}
+
+func (o walletOwner) newWallet() models.Wallet {
+	wallet := models.Wallet{OwnerType: o.kind, OwnerID: o.id}
+	if o.kind == models.WalletOwnerVendor {
+		wallet.VendorID = o.id
+	} else {
+		wallet.ClientID = o.id
+	}
+	return wallet
+}
+
+// lockWallet loads the owner's wallet with a row lock for the rest of tx. A
+// missing wallet is reported as gorm.ErrRecordNotFound.
+func lockWallet(tx *gorm.DB, owner walletOwner) (*models.Wallet, error) {
+	var wallet models.Wallet
+	err := owner.query(tx).Clauses(clause.Locking{Strength: "UPDATE"}).First(&wallet).Error
+	if err != nil {
+		return nil, err
+	}
+	return &wallet, nil
+}
+
+// lockWalletForDebit locks the owner's wallet and checks it holds at least
+// amount, treating a missing wallet as an empty one.
+func lockWalletForDebit(tx *gorm.DB, owner walletOwner, amount money.Money) (*models.Wallet, error) {
+	wallet, err := lockWallet(tx, owner)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInsufficientBalance
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s wallet: %w", owner.kind, err)
+	}
+
+	balance, err := wallet.Balance()
+	if err != nil {
+		return nil, err
+	}
+	if short, err := balance.LessThan(amount); err != nil {
+		return nil, err
+	} else if short {
+		return nil, ErrInsufficientBalance
+	}
+	return wallet, nil
+}
+
+// debitWallet takes amount out of a wallet locked by lockWalletForDebit.
+func debitWallet(tx *gorm.DB, wallet *models.Wallet, amount money.Money) error {
+	major, err := amount.Major()
+	if err != nil {
+		return err
+	}
+
+	err = tx.Model(wallet).Updates(map[string]interface{}{
+		"wallet_balance":    gorm.Expr("wallet_balance - ?", major),
+		"total_withdrawals": gorm.Expr("total_withdrawals + ?", major),
+	}).Error
+	if err != nil {
+		return fmt.Errorf("failed to debit %s wallet: %w", wallet.OwnerType, err)
+	}
+	return nil
+}
+
+// creditWallet deposits amount into the owner's wallet, opening the wallet if
+// they do not have one yet.
+func creditWallet(tx *gorm.DB, owner walletOwner, amount money.Money) error {
+	major, err := amount.Major()
+	if err != nil {
+		return err
+	}
+
+	result := owner.query(tx).Updates(map[string]interface{}{
+		"wallet_balance": gorm.Expr("wallet_balance + ?", major),
+		"total_deposits": gorm.Expr("total_deposits + ?", major),
+	})
+	if result.Error != nil {
+		return fmt.Errorf("failed to credit %s wallet: %w", owner.kind, result.Error)
+	}
+	if result.RowsAffected > 0 {
+		return nil
+	}
+
+	wallet := owner.newWallet()
+	wallet.WalletBalance = major
+	wallet.TotalDeposits = major
+	if err := tx.Create(&wallet).Error; err != nil {
+		return fmt.Errorf("failed to create %s wallet: %w", owner.kind, err)
+	}
+	return nil
+}
+
+// moveWalletBalance shifts amount into (positive) or out of (negative) the
+// owner's balance without counting it as a deposit or withdrawal, as when
+// funds are held for a pending withdrawal.
+func moveWalletBalance(tx *gorm.DB, owner walletOwner, amount money.Money) error {
+	major, err := amount.Major()
+	if err != nil {
+		return err
+	}
+
+	err = owner.query(tx).Update("wallet_balance", gorm.Expr("wallet_balance + ?", major)).Error
+	if err != nil {
+		return fmt.Errorf("failed to update %s wallet: %w", owner.kind, err)
+	}
+	return nil
+}
+
+// creditAdminWallet records money collected into the admin wallet with the
+// given email.
+func creditAdminWallet(tx *gorm.DB, adminEmail string, amount money.Money) error {
+	major, err := amount.Major()
+	if err != nil {
+		return err
+	}
+
+	result := tx.Model(&adminModel.AdminWallet{}).Where("email = ?", adminEmail).Updates(map[string]interface{}{
+		"balance":        gorm.Expr("balance + ?", major),
+		"total_deposits": gorm.Expr("total_deposits + ?", major),
+	})
+	if result.Error != nil {
+		return fmt.Errorf("failed to credit admin wallet: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("%w: %s", ErrTreasuryWalletNotFound, adminEmail)
+	}
+	return nil
+}
+
+// debitAdminWallet records money paid out of the admin wallet with the given
+// email.
+func debitAdminWallet(tx *gorm.DB, adminEmail string, amount money.Money) error {
+	major, err := amount.Major()
+	if err != nil {
+		return err
+	}
+
+	result := tx.Model(&adminModel.AdminWallet{}).Where("email = ?", adminEmail).Updates(map[string]interface{}{
+		"balance":           gorm.Expr("balance - ?", major),
+		"total_withdrawals": gorm.Expr("total_withdrawals + ?", major),
+	})
+	if result.Error != nil {
+		return fmt.Errorf("failed to debit admin wallet: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("%w: %s", ErrTreasuryWalletNotFound, adminEmail)
+	}
+	return nil
+}
+
+// wholeUnits converts amount for the int columns other services define, such
+// as booking prices.
+func wholeUnits(amount money.Money) (int, error) {
+	major, err := amount.Major()
+	if err != nil {
+		return 0, err
+	}
+	return int(major), nil
+}
+
+// recordUserTransaction writes a wallet transaction to the history shared
+// with the client service, which stores whole major units.
+func recordUserTransaction(tx *gorm.DB, userID uuid.UUID, purpose, paymentStatus string, amount money.Money, at time.Time) error {
+	_, err := createUserTransaction(tx, userID, purpose, paymentStatus, amount, at)
+	return err
+}
+
+// createUserTransaction is recordUserTransaction for callers that need the
+// new transaction's ID.
+func createUserTransaction(tx *gorm.DB, userID uuid.UUID, purpose, paymentStatus string, amount money.Money, at time.Time) (uuid.UUID, error) {
+	major, err := amount.Major()
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	transactionID := uuid.New()
+	err = tx.Create(&clientModel.Transaction{
+		TransactionID: transactionID,
+		UserID:        userID,
+		Purpose:       purpose,
+		AmountPaid:    int(major),
+		PaymentMethod: "wallet",
+		PaymentStatus: paymentStatus,
+		DateOfPayment: at,
+	}).Error
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to create transaction: %w", err)
+	}
+	return transactionID, nil
+}
+
+// recordAdminTransaction writes an entry to the admin wallet's history.
+func recordAdminTransaction(tx *gorm.DB, kind, paymentStatus string, amount money.Money, at time.Time) error {
+	major, err := amount.Major()
+	if err != nil {
+		return err
+	}
+
+	err = tx.Create(&adminModel.AdminWalletTransaction{
+		Date:   at,
+		Type:   kind,
+		Amount: float64(major),
+		Status: paymentStatus,
+	}).Error
+	if err != nil {
+		return fmt.Errorf("failed to create admin wallet transaction: %w", err)
+	}
+	return nil
+}
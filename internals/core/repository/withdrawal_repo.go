@@ -0,0 +1,271 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/models"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/money"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrWithdrawalLimitExceeded = errors.New("withdrawal exceeds the daily limit")
+	ErrWithdrawalNotPending    = errors.New("withdrawal is no longer pending")
+	ErrWithdrawalNotProcessing = errors.New("withdrawal is not being paid out")
+)
+
+// RequestWithdrawal moves the requested amount from the vendor's wallet into
+// a withdrawal hold and records the pending withdrawal. The wallet row is
+// locked while the balance and the rolling 24 hour limit are checked, so
+// concurrent requests cannot overdraw it.
+func (r *VendorStorage) RequestWithdrawal(ctx context.Context, withdrawal *models.Withdrawal, dailyLimit money.Money) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		owner := vendorWalletOwner(withdrawal.VendorID)
+		if _, err := lockWalletForDebit(tx, owner, withdrawal.Amount); err != nil {
+			return err
+		}
+
+		if dailyLimit.IsPositive() {
+			var withdrawnToday money.Money
+			err := tx.Model(&models.Withdrawal{}).
+				Select("COALESCE(SUM(amount), 0)").
+				Where("vendor_id = ? AND created_at > ? AND status NOT IN ?",
+					withdrawal.VendorID, time.Now().Add(-24*time.Hour), []string{"rejected", "failed"}).
+				Scan(&withdrawnToday).Error
+			if err != nil {
+				return fmt.Errorf("failed to sum recent withdrawals: %w", err)
+			}
+			total, err := withdrawnToday.Add(withdrawal.Amount)
+			if err != nil {
+				return err
+			}
+			if over, err := dailyLimit.LessThan(total); err != nil || over {
+				return ErrWithdrawalLimitExceeded
+			}
+		}
+
+		if err := moveWalletBalance(tx, owner, money.New(-withdrawal.Amount.Amount)); err != nil {
+			return err
+		}
+
+		withdrawal.Status = "pending"
+		if err := tx.Create(withdrawal).Error; err != nil {
+			return fmt.Errorf("failed to create withdrawal: %w", err)
+		}
+
+		leg := ledgerLeg{From: vendorWalletAccount(withdrawal.VendorID), To: vendorWithdrawalHoldAccount(withdrawal.VendorID), Amount: withdrawal.Amount}
+		if err := postLedger(tx, "withdrawal_hold", withdrawal.ID.String(), "Withdrawal requested", leg); err != nil {
+			return err
+		}
+
+		return syncFundHolds(tx, withdrawal.VendorID)
+	})
+}
+
+func (r *VendorStorage) GetWithdrawal(ctx context.Context, withdrawalID string) (*models.Withdrawal, error) {
+	var withdrawal models.Withdrawal
+	err := r.DB.WithContext(ctx).Where("id = ?", withdrawalID).First(&withdrawal).Error
+	if err != nil {
+		return nil, err
+	}
+	return &withdrawal, nil
+}
+
+// ListWithdrawals returns withdrawals newest first. A nil vendorID lists every
+// vendor's withdrawals and an empty status lists every status.
+func (r *VendorStorage) ListWithdrawals(ctx context.Context, vendorID uuid.UUID, status string) ([]models.Withdrawal, error) {
+	query := r.DB.WithContext(ctx).Model(&models.Withdrawal{})
+	if vendorID != uuid.Nil {
+		query = query.Where("vendor_id = ?", vendorID)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var withdrawals []models.Withdrawal
+	if err := query.Order("created_at DESC").Find(&withdrawals).Error; err != nil {
+		return nil, err
+	}
+	return withdrawals, nil
+}
+
+// ApproveWithdrawal marks a pending withdrawal as processing so it can be sent
+// to the payout provider. A withdrawal already processing is returned as is so
+// a payout with an unknown outcome can be retried.
+func (r *VendorStorage) ApproveWithdrawal(ctx context.Context, withdrawalID string, adminID uuid.UUID) (*models.Withdrawal, error) {
+	var withdrawal models.Withdrawal
+
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", withdrawalID).
+			First(&withdrawal).Error; err != nil {
+			return err
+		}
+
+		switch withdrawal.Status {
+		case "processing":
+			return nil
+		case "pending":
+		default:
+			return ErrWithdrawalNotPending
+		}
+
+		now := time.Now()
+		withdrawal.Status = "processing"
+		withdrawal.DecidedBy = &adminID
+		withdrawal.DecidedAt = &now
+		return tx.Model(&withdrawal).Updates(map[string]interface{}{
+			"status":     withdrawal.Status,
+			"decided_by": adminID,
+			"decided_at": now,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &withdrawal, nil
+}
+
+// RejectWithdrawal refuses a pending withdrawal and returns the held amount
+// to the vendor's wallet.
+func (r *VendorStorage) RejectWithdrawal(ctx context.Context, withdrawalID string, adminID uuid.UUID, reason string) (*models.Withdrawal, error) {
+	var withdrawal models.Withdrawal
+
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", withdrawalID).
+			First(&withdrawal).Error; err != nil {
+			return err
+		}
+
+		if withdrawal.Status != "pending" {
+			return ErrWithdrawalNotPending
+		}
+
+		now := time.Now()
+		withdrawal.Status = "rejected"
+		withdrawal.DecidedBy = &adminID
+		withdrawal.DecidedAt = &now
+		withdrawal.Reason = reason
+		if err := tx.Model(&withdrawal).Updates(map[string]interface{}{
+			"status":     withdrawal.Status,
+			"decided_by": adminID,
+			"decided_at": now,
+			"reason":     reason,
+		}).Error; err != nil {
+			return err
+		}
+
+		return releaseWithdrawalHold(tx, &withdrawal, "withdrawal_rejected", "Withdrawal rejected")
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &withdrawal, nil
+}
+
+// CompleteWithdrawal records that the payout provider has sent a processing
+// withdrawal, clearing the hold.
+func (r *VendorStorage) CompleteWithdrawal(ctx context.Context, withdrawalID string, providerReference string) (*models.Withdrawal, error) {
+	var withdrawal models.Withdrawal
+
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", withdrawalID).
+			First(&withdrawal).Error; err != nil {
+			return err
+		}
+
+		if withdrawal.Status != "processing" {
+			return ErrWithdrawalNotProcessing
+		}
+
+		now := time.Now()
+		withdrawal.Status = "paid"
+		withdrawal.ProviderReference = providerReference
+		withdrawal.PaidAt = &now
+		if err := tx.Model(&withdrawal).Updates(map[string]interface{}{
+			"status":             withdrawal.Status,
+			"provider_reference": providerReference,
+			"paid_at":            now,
+		}).Error; err != nil {
+			return err
+		}
+
+		major, err := withdrawal.Amount.Major()
+		if err != nil {
+			return err
+		}
+		if err := vendorWalletOwner(withdrawal.VendorID).query(tx).
+			Update("total_withdrawals", gorm.Expr("total_withdrawals + ?", major)).Error; err != nil {
+			return fmt.Errorf("failed to update vendor wallet: %w", err)
+		}
+
+		leg := ledgerLeg{From: vendorWithdrawalHoldAccount(withdrawal.VendorID), To: platformAccount(ledgerCodeExternal), Amount: withdrawal.Amount}
+		if err := postLedger(tx, "withdrawal_paid", withdrawal.ID.String(), "Withdrawal paid out", leg); err != nil {
+			return err
+		}
+
+		return syncFundHolds(tx, withdrawal.VendorID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &withdrawal, nil
+}
+
+// FailWithdrawal records a payout the provider declined and returns the held
+// amount to the vendor's wallet. Only call it once the provider has refused
+// for certain; a payout with an unknown outcome must stay processing.
+func (r *VendorStorage) FailWithdrawal(ctx context.Context, withdrawalID string, failureReason string) (*models.Withdrawal, error) {
+	var withdrawal models.Withdrawal
+
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", withdrawalID).
+			First(&withdrawal).Error; err != nil {
+			return err
+		}
+
+		if withdrawal.Status != "processing" {
+			return ErrWithdrawalNotProcessing
+		}
+
+		withdrawal.Status = "failed"
+		withdrawal.FailureReason = failureReason
+		if err := tx.Model(&withdrawal).Updates(map[string]interface{}{
+			"status":         withdrawal.Status,
+			"failure_reason": failureReason,
+		}).Error; err != nil {
+			return err
+		}
+
+		return releaseWithdrawalHold(tx, &withdrawal, "withdrawal_failed", "Withdrawal payout failed")
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &withdrawal, nil
+}
+
+func releaseWithdrawalHold(tx *gorm.DB, withdrawal *models.Withdrawal, kind, description string) error {
+	if err := moveWalletBalance(tx, vendorWalletOwner(withdrawal.VendorID), withdrawal.Amount); err != nil {
+		return err
+	}
+
+	leg := ledgerLeg{From: vendorWithdrawalHoldAccount(withdrawal.VendorID), To: vendorWalletAccount(withdrawal.VendorID), Amount: withdrawal.Amount}
+	if err := postLedger(tx, kind, withdrawal.ID.String(), description, leg); err != nil {
+		return err
+	}
+
+	return syncFundHolds(tx, withdrawal.VendorID)
+}
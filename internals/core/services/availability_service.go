@@ -0,0 +1,553 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	pb "github.com/AthulKrishna2501/proto-repo/vendor"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/models"
+	"github.com/AthulKrishna2501/zyra-vendor-service/utils"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"gorm.io/gorm"
+)
+
+const (
+	maxCalendarSize             = 5 * 1024 * 1024
+	defaultCalendarSyncInterval = time.Hour
+	maxAvailabilityRange        = 366 * 24 * time.Hour
+)
+
+const maxCalendarRedirects = 5
+
+// calendarHTTPClient fetches vendor-supplied calendar URLs. It connects
+// directly rather than through a proxy and refuses to connect to anything but
+// public addresses, checked on the resolved IP of every connection, so
+// neither the URL nor a redirect can reach internal services.
+var calendarHTTPClient = &http.Client{
+	Timeout: 20 * time.Second,
+	Transport: &http.Transport{
+		Proxy: nil,
+		DialContext: (&net.Dialer{
+			Timeout: 10 * time.Second,
+			Control: publicAddressOnly,
+		}).DialContext,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ResponseHeaderTimeout: 15 * time.Second,
+	},
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		if len(via) >= maxCalendarRedirects {
+			return errors.New("too many redirects")
+		}
+		if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+			return fmt.Errorf("redirect to unsupported scheme %q", req.URL.Scheme)
+		}
+		return nil
+	},
+}
+
+var errPrivateAddress = errors.New("calendar URL must point to a public address")
+
+// publicAddressOnly is a net.Dialer Control function that rejects
+// connections to loopback, private, link-local and other non-public
+// addresses.
+func publicAddressOnly(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	if !isPublicAddress(ip) {
+		return fmt.Errorf("%w: %s", errPrivateAddress, ip)
+	}
+	return nil
+}
+
+func isPublicAddress(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	return ip.IsGlobalUnicast() &&
+		!ip.IsPrivate() &&
+		!ip.IsLoopback() &&
+		!ip.IsLinkLocalUnicast() &&
+		!sharedAddressSpace.Contains(ip)
+}
+
+// sharedAddressSpace is the carrier-grade NAT range (RFC 6598), which
+// netip.Addr.IsPrivate does not cover.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// calendarURL checks a vendor-supplied calendar link and returns the URL to
+// fetch it from, with webcal:// rewritten to https://.
+func calendarURL(rawURL string) (string, error) {
+	if strings.HasPrefix(rawURL, "webcal://") {
+		rawURL = "https://" + strings.TrimPrefix(rawURL, "webcal://")
+	}
+
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return "", errors.New("url must be an http(s) or webcal link")
+	}
+	if parsed.Hostname() == "" || parsed.User != nil {
+		return "", errors.New("url must have a host and no credentials")
+	}
+	if ip, err := netip.ParseAddr(parsed.Hostname()); err == nil && !isPublicAddress(ip) {
+		return "", errPrivateAddress
+	}
+	return parsed.String(), nil
+}
+
+func toProtoExternalCalendar(calendar *models.ExternalCalendar) *pb.ExternalCalendar {
+	protoCalendar := &pb.ExternalCalendar{
+		CalendarId:    calendar.ID.String(),
+		Name:          calendar.Name,
+		SourceUrl:     calendar.SourceURL,
+		LastSyncError: calendar.LastSyncError,
+	}
+	if calendar.LastSyncedAt != nil {
+		protoCalendar.LastSyncedAt = timestamppb.New(*calendar.LastSyncedAt)
+	}
+	return protoCalendar
+}
+
+func fetchCalendar(ctx context.Context, sourceURL string) ([]byte, error) {
+	sourceURL, err := calendarURL(sourceURL)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, sourceURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := calendarHTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("calendar server responded with %s", resp.Status)
+	}
+
+	content, err := io.ReadAll(io.LimitReader(resp.Body, maxCalendarSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(content) > maxCalendarSize {
+		return nil, errors.New("calendar exceeds the 5MB limit")
+	}
+	return content, nil
+}
+
+// blocksFromCalendar turns a calendar's busy events into blocked time.
+// Recurring events are expanded up to a year ahead; calendars are re-synced
+// often enough for that horizon to keep moving.
+func blocksFromCalendar(calendar *models.ExternalCalendar, content []byte, now time.Time) ([]models.BlockedTime, error) {
+	events, err := utils.ParseICalendar(bytes.NewReader(content), now, now.Add(maxAvailabilityRange))
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	var blocks []models.BlockedTime
+	for _, event := range events {
+		key := event.UID + "|" + event.Start.UTC().String()
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		blocks = append(blocks, models.BlockedTime{
+			VendorID:    calendar.VendorID,
+			CalendarID:  calendar.ID,
+			ExternalUID: event.UID,
+			StartsAt:    event.Start,
+			EndsAt:      event.End,
+			Summary:     event.Summary,
+		})
+	}
+	return blocks, nil
+}
+
+func (s *VendorService) syncCalendar(ctx context.Context, calendar *models.ExternalCalendar, content []byte) (int, error) {
+	if content == nil {
+		var err error
+		content, err = fetchCalendar(ctx, calendar.SourceURL)
+		if err != nil {
+			if recErr := s.vendorRepo.RecordCalendarSyncError(ctx, calendar.ID, err.Error()); recErr != nil {
+				s.log.Error("Failed to record calendar sync error", calendar.ID, recErr)
+			}
+			return 0, err
+		}
+	}
+
+	blocks, err := blocksFromCalendar(calendar, content, time.Now())
+	if err != nil {
+		if recErr := s.vendorRepo.RecordCalendarSyncError(ctx, calendar.ID, err.Error()); recErr != nil {
+			s.log.Error("Failed to record calendar sync error", calendar.ID, recErr)
+		}
+		return 0, err
+	}
+
+	if err := s.vendorRepo.ReplaceBlockedTimes(ctx, calendar, blocks); err != nil {
+		return 0, err
+	}
+	return len(blocks), nil
+}
+
+func (s *VendorService) AddExternalCalendar(ctx context.Context, req *pb.AddExternalCalendarRequest) (*pb.AddExternalCalendarResponse, error) {
+	vendorUUID, err := uuid.Parse(req.VendorId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid vendor ID format: %v", err)
+	}
+
+	if _, err := calendarURL(req.Url); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid calendar url: %v", err)
+	}
+
+	name := req.Name
+	if name == "" {
+		name = "External calendar"
+	}
+
+	calendar := &models.ExternalCalendar{
+		ID:        uuid.New(),
+		VendorID:  vendorUUID,
+		Name:      name,
+		SourceURL: req.Url,
+	}
+
+	content, err := fetchCalendar(ctx, req.Url)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to fetch calendar: %v", err)
+	}
+
+	return s.importNewCalendar(ctx, calendar, content)
+}
+
+func (s *VendorService) UploadCalendarFile(ctx context.Context, req *pb.UploadCalendarFileRequest) (*pb.UploadCalendarFileResponse, error) {
+	vendorUUID, err := uuid.Parse(req.VendorId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid vendor ID format: %v", err)
+	}
+
+	if len(req.Content) == 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "calendar file is empty")
+	}
+
+	if len(req.Content) > maxCalendarSize {
+		return nil, status.Errorf(codes.InvalidArgument, "calendar exceeds the 5MB limit")
+	}
+
+	if req.CalendarId == "" {
+		name := req.Name
+		if name == "" {
+			name = "Uploaded calendar"
+		}
+		calendar := &models.ExternalCalendar{
+			ID:       uuid.New(),
+			VendorID: vendorUUID,
+			Name:     name,
+		}
+
+		resp, err := s.importNewCalendar(ctx, calendar, req.Content)
+		if err != nil {
+			return nil, err
+		}
+		return &pb.UploadCalendarFileResponse{
+			Calendar:      resp.Calendar,
+			BlocksCreated: resp.BlocksCreated,
+		}, nil
+	}
+
+	calendar, err := s.vendorRepo.GetExternalCalendar(ctx, req.VendorId, req.CalendarId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, status.Errorf(codes.NotFound, "calendar not found")
+	} else if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to fetch calendar: %v", err)
+	}
+	if calendar.SourceURL != "" {
+		return nil, status.Errorf(codes.FailedPrecondition, "calendar is synced from a URL and cannot be replaced by an upload")
+	}
+
+	created, err := s.syncCalendar(ctx, calendar, req.Content)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to import calendar: %v", err)
+	}
+
+	return &pb.UploadCalendarFileResponse{
+		Calendar:      toProtoExternalCalendar(calendar),
+		BlocksCreated: int32(created),
+	}, nil
+}
+
+// importNewCalendar parses a calendar before saving it, so a calendar that
+// cannot be imported is never stored, and then saves it with its blocks in
+// one go.
+func (s *VendorService) importNewCalendar(ctx context.Context, calendar *models.ExternalCalendar, content []byte) (*pb.AddExternalCalendarResponse, error) {
+	blocks, err := blocksFromCalendar(calendar, content, time.Now())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to import calendar: %v", err)
+	}
+
+	if err := s.vendorRepo.CreateExternalCalendar(ctx, calendar, blocks); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to save calendar: %v", err)
+	}
+
+	return &pb.AddExternalCalendarResponse{
+		Calendar:      toProtoExternalCalendar(calendar),
+		BlocksCreated: int32(len(blocks)),
+	}, nil
+}
+
+func (s *VendorService) SyncExternalCalendar(ctx context.Context, req *pb.SyncExternalCalendarRequest) (*pb.SyncExternalCalendarResponse, error) {
+	calendar, err := s.vendorRepo.GetExternalCalendar(ctx, req.VendorId, req.CalendarId)
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "calendar not found: %v", err)
+	}
+
+	if calendar.SourceURL == "" {
+		return nil, status.Errorf(codes.FailedPrecondition, "uploaded calendars are re-synced by uploading the file again")
+	}
+
+	created, err := s.syncCalendar(ctx, calendar, nil)
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "failed to sync calendar: %v", err)
+	}
+
+	return &pb.SyncExternalCalendarResponse{
+		BlocksCreated: int32(created),
+	}, nil
+}
+
+func (s *VendorService) ListExternalCalendars(ctx context.Context, req *pb.ListExternalCalendarsRequest) (*pb.ListExternalCalendarsResponse, error) {
+	if req.VendorId == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "vendor_id is required")
+	}
+
+	calendars, err := s.vendorRepo.ListExternalCalendars(ctx, req.VendorId)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to fetch calendars: %v", err)
+	}
+
+	var protoCalendars []*pb.ExternalCalendar
+	for i := range calendars {
+		protoCalendars = append(protoCalendars, toProtoExternalCalendar(&calendars[i]))
+	}
+
+	return &pb.ListExternalCalendarsResponse{
+		Calendars: protoCalendars,
+	}, nil
+}
+
+func (s *VendorService) RemoveExternalCalendar(ctx context.Context, req *pb.RemoveExternalCalendarRequest) (*pb.RemoveExternalCalendarResponse, error) {
+	err := s.vendorRepo.DeleteExternalCalendar(ctx, req.VendorId, req.CalendarId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, status.Errorf(codes.NotFound, "calendar not found")
+	} else if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to remove calendar: %v", err)
+	}
+
+	return &pb.RemoveExternalCalendarResponse{
+		Message: "Calendar removed and its blocked time released",
+	}, nil
+}
+
+func (s *VendorService) GetVendorAvailability(ctx context.Context, req *pb.GetVendorAvailabilityRequest) (*pb.GetVendorAvailabilityResponse, error) {
+	if req.VendorId == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "vendor_id is required")
+	}
+
+	if req.From == nil || req.To == nil {
+		return nil, status.Errorf(codes.InvalidArgument, "from and to are required")
+	}
+
+	from, to := req.From.AsTime(), req.To.AsTime()
+	if !to.After(from) || to.Sub(from) > maxAvailabilityRange {
+		return nil, status.Errorf(codes.InvalidArgument, "range must be positive and at most one year")
+	}
+
+	blocks, err := s.vendorRepo.GetBlockedTimes(ctx, req.VendorId, from, to)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to fetch blocked time: %v", err)
+	}
+
+	bookings, err := s.vendorRepo.GetVendorBusyBookings(ctx, req.VendorId, from, to)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to fetch bookings: %v", err)
+	}
+
+	var slots []*pb.BusySlot
+	if req.ServiceId != "" {
+		service, err := s.ownedService(ctx, req.VendorId, req.ServiceId)
+		if err != nil {
+			return nil, err
+		}
+		slots = append(slots, bookingWindowSlots(service, time.Now(), from, to)...)
+	}
+
+	for _, block := range blocks {
+		slots = append(slots, &pb.BusySlot{
+			Start:  timestamppb.New(block.StartsAt),
+			End:    timestamppb.New(block.EndsAt),
+			Source: "external",
+			Label:  block.Summary,
+		})
+	}
+	for _, booking := range bookings {
+		end := booking.Date.Add(time.Duration(booking.ServiceDuration) * time.Hour)
+		if !end.After(from) {
+			continue
+		}
+		slots = append(slots, &pb.BusySlot{
+			Start:  timestamppb.New(booking.Date),
+			End:    timestamppb.New(end),
+			Source: "booking",
+			Label:  booking.Service,
+		})
+	}
+
+	sort.Slice(slots, func(i, j int) bool {
+		return slots[i].Start.AsTime().Before(slots[j].Start.AsTime())
+	})
+
+	return &pb.GetVendorAvailabilityResponse{
+		BusySlots: slots,
+	}, nil
+}
+
+// bookingWindowSlots marks the parts of [from, to) that fall inside the
+// service's minimum notice or beyond its advance booking window as busy.
+func bookingWindowSlots(service *models.Service, now, from, to time.Time) []*pb.BusySlot {
+	var slots []*pb.BusySlot
+
+	if service.MinNoticeHours > 0 {
+		noticeEnd := now.Add(time.Duration(service.MinNoticeHours) * time.Hour)
+		if noticeEnd.After(from) {
+			slots = append(slots, &pb.BusySlot{
+				Start:  timestamppb.New(from),
+				End:    timestamppb.New(minTime(noticeEnd, to)),
+				Source: "min_notice",
+				Label:  fmt.Sprintf("Requires %d hours' notice", service.MinNoticeHours),
+			})
+		}
+	}
+
+	if service.MaxAdvanceDays > 0 {
+		windowEnd := now.AddDate(0, 0, service.MaxAdvanceDays)
+		if windowEnd.Before(to) {
+			slots = append(slots, &pb.BusySlot{
+				Start:  timestamppb.New(maxTime(windowEnd, from)),
+				End:    timestamppb.New(to),
+				Source: "advance_window",
+				Label:  fmt.Sprintf("Bookable at most %d days in advance", service.MaxAdvanceDays),
+			})
+		}
+	}
+
+	return slots
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+// checkBlockedTime rejects a booking slot that overlaps time the vendor has
+// blocked through an imported calendar.
+func (s *VendorService) checkBlockedTime(ctx context.Context, vendorID string, start, end time.Time) error {
+	blocks, err := s.vendorRepo.GetBlockedTimes(ctx, vendorID, start, end)
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to check blocked time: %v", err)
+	}
+
+	if len(blocks) > 0 {
+		return status.Errorf(codes.FailedPrecondition, "vendor is busy from %s to %s",
+			blocks[0].StartsAt.Format(time.RFC3339), blocks[0].EndsAt.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// checkBookedTime rejects a booking slot that overlaps one of the vendor's
+// confirmed bookings.
+func (s *VendorService) checkBookedTime(ctx context.Context, vendorID string, start, end time.Time) error {
+	bookings, err := s.vendorRepo.GetVendorBusyBookings(ctx, vendorID, start, end)
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to check bookings: %v", err)
+	}
+
+	for _, booking := range bookings {
+		bookingEnd := booking.Date.Add(time.Duration(booking.ServiceDuration) * time.Hour)
+		if booking.Date.Before(end) && bookingEnd.After(start) {
+			return status.Errorf(codes.FailedPrecondition, "vendor is already booked from %s to %s",
+				booking.Date.Format(time.RFC3339), bookingEnd.Format(time.RFC3339))
+		}
+	}
+	return nil
+}
+
+// serviceDuration is how long a booking of the service takes, an hour when
+// the service is unknown or has no duration set.
+func serviceDuration(service *models.Service) time.Duration {
+	if service == nil || service.ServiceDuration <= 0 {
+		return time.Hour
+	}
+	return time.Duration(service.ServiceDuration) * time.Hour
+}
+
+func (s *VendorService) StartCalendarSync(ctx context.Context) {
+	interval := defaultCalendarSyncInterval
+	if s.cfg.CALENDAR_SYNC_INTERVAL_MINS > 0 {
+		interval = time.Duration(s.cfg.CALENDAR_SYNC_INTERVAL_MINS) * time.Minute
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			calendars, err := s.vendorRepo.ListSubscribedCalendars(ctx)
+			if err != nil {
+				s.log.Error("Failed to list external calendars", err)
+				continue
+			}
+			for i := range calendars {
+				if _, err := s.syncCalendar(ctx, &calendars[i], nil); err != nil {
+					s.log.Warn("Failed to sync external calendar", calendars[i].ID, err)
+				}
+			}
+		}
+	}
+}
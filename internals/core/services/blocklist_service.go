@@ -0,0 +1,175 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	pb "github.com/AthulKrishna2501/proto-repo/vendor"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/models"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/models/responses"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/repository"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const blockedClientReason = "Client is blocked by the vendor"
+
+func (s *VendorService) BlockClient(ctx context.Context, req *pb.BlockClientRequest) (*pb.BlockClientResponse, error) {
+	vendorUUID, err := uuid.Parse(req.VendorId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid vendor ID format: %v", err)
+	}
+
+	clientUUID, err := uuid.Parse(req.ClientId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid client ID format: %v", err)
+	}
+
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "reason is required")
+	}
+
+	block := &models.ClientBlock{
+		VendorID: vendorUUID,
+		ClientID: clientUUID,
+		Reason:   reason,
+	}
+
+	withdrawn, err := s.vendorRepo.BlockClient(ctx, block)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to block client: %v", err)
+	}
+
+	for _, entry := range withdrawn {
+		if _, err := s.offerFreedSlot(ctx, entry.VendorID, entry.ServiceID, entry.Date); err != nil {
+			s.log.Error("Failed to offer slot to next waitlisted client", entry.VendorID, entry.Date, err)
+		}
+	}
+
+	return &pb.BlockClientResponse{
+		Message: "Client blocked successfully",
+	}, nil
+}
+
+func (s *VendorService) UnblockClient(ctx context.Context, req *pb.UnblockClientRequest) (*pb.UnblockClientResponse, error) {
+	vendorUUID, err := uuid.Parse(req.VendorId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid vendor ID format: %v", err)
+	}
+
+	clientUUID, err := uuid.Parse(req.ClientId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid client ID format: %v", err)
+	}
+
+	err = s.vendorRepo.UnblockClient(ctx, vendorUUID, clientUUID)
+	if errors.Is(err, repository.ErrClientNotBlocked) {
+		return nil, status.Errorf(codes.NotFound, "%v", err)
+	} else if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to unblock client: %v", err)
+	}
+
+	return &pb.UnblockClientResponse{
+		Message: "Client unblocked successfully",
+	}, nil
+}
+
+func (s *VendorService) ListBlockedClients(ctx context.Context, req *pb.ListBlockedClientsRequest) (*pb.ListBlockedClientsResponse, error) {
+	vendorUUID, err := uuid.Parse(req.VendorId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid vendor ID format: %v", err)
+	}
+
+	blocked, err := s.vendorRepo.ListBlockedClients(ctx, vendorUUID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to fetch blocked clients: %v", err)
+	}
+
+	return &pb.ListBlockedClientsResponse{
+		Clients: blockedClientsToProto(blocked),
+	}, nil
+}
+
+// AdminListBlockedClients lets admins review blocklists, either for one
+// vendor or across all vendors when vendor_id is empty.
+func (s *VendorService) AdminListBlockedClients(ctx context.Context, req *pb.AdminListBlockedClientsRequest) (*pb.ListBlockedClientsResponse, error) {
+	if _, err := s.authorizeAdmin(ctx, req.AdminId); err != nil {
+		return nil, err
+	}
+
+	var vendorUUID uuid.UUID
+	if req.VendorId != "" {
+		parsed, err := uuid.Parse(req.VendorId)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid vendor ID format: %v", err)
+		}
+		vendorUUID = parsed
+	}
+
+	blocked, err := s.vendorRepo.ListBlockedClients(ctx, vendorUUID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to fetch blocked clients: %v", err)
+	}
+
+	return &pb.ListBlockedClientsResponse{
+		Clients: blockedClientsToProto(blocked),
+	}, nil
+}
+
+// CheckClientBlocked lets the client service refuse a booking before it
+// charges the client. Payment is taken by the client service, so this service
+// cannot stop it; a booking from a blocked client that is paid anyway is
+// rejected and refunded by ProcessNewBooking.
+func (s *VendorService) CheckClientBlocked(ctx context.Context, req *pb.CheckClientBlockedRequest) (*pb.CheckClientBlockedResponse, error) {
+	vendorUUID, err := uuid.Parse(req.VendorId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid vendor ID format: %v", err)
+	}
+
+	clientUUID, err := uuid.Parse(req.ClientId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid client ID format: %v", err)
+	}
+
+	blocked, err := s.vendorRepo.IsClientBlocked(ctx, vendorUUID, clientUUID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to check blocklist: %v", err)
+	}
+
+	return &pb.CheckClientBlockedResponse{
+		Blocked: blocked,
+	}, nil
+}
+
+// ensureClientNotBlocked refuses requests from clients on the vendor's
+// blocklist without revealing the vendor's reason.
+func (s *VendorService) ensureClientNotBlocked(ctx context.Context, vendorID, clientID uuid.UUID) error {
+	blocked, err := s.vendorRepo.IsClientBlocked(ctx, vendorID, clientID)
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to check blocklist: %v", err)
+	}
+	if blocked {
+		return status.Errorf(codes.PermissionDenied, "this vendor is not accepting requests from you")
+	}
+	return nil
+}
+
+func blockedClientsToProto(blocked []responses.BlockedClient) []*pb.BlockedClient {
+	var protoClients []*pb.BlockedClient
+	for _, client := range blocked {
+		protoClients = append(protoClients, &pb.BlockedClient{
+			VendorId:  client.VendorID,
+			ClientId:  client.ClientID,
+			FirstName: client.ClientFirstName,
+			LastName:  client.ClientLastName,
+			Email:     client.ClientEmail,
+			Reason:    client.Reason,
+			BlockedAt: timestamppb.New(client.CreatedAt),
+		})
+	}
+	return protoClients
+}
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	pb "github.com/AthulKrishna2501/proto-repo/vendor"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/models"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"gorm.io/gorm"
+)
+
+func (s *VendorService) ListRejectionReasons(ctx context.Context, req *pb.ListRejectionReasonsRequest) (*pb.ListRejectionReasonsResponse, error) {
+	var reasons []*pb.RejectionReason
+	for code, label := range models.RejectionReasons {
+		reasons = append(reasons, &pb.RejectionReason{
+			Code:  code,
+			Label: label,
+		})
+	}
+
+	sort.Slice(reasons, func(i, j int) bool {
+		return reasons[i].Code < reasons[j].Code
+	})
+
+	return &pb.ListRejectionReasonsResponse{
+		Reasons: reasons,
+	}, nil
+}
+
+// authorizeAdmin checks that adminID belongs to an admin user.
+func (s *VendorService) authorizeAdmin(ctx context.Context, adminID string) (uuid.UUID, error) {
+	adminUUID, err := uuid.Parse(adminID)
+	if err != nil {
+		return uuid.Nil, status.Errorf(codes.InvalidArgument, "invalid admin ID format: %v", err)
+	}
+
+	user, err := s.vendorRepo.GetUserByID(ctx, adminID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return uuid.Nil, status.Errorf(codes.PermissionDenied, "requester is not an admin")
+	} else if err != nil {
+		return uuid.Nil, status.Errorf(codes.Internal, "failed to fetch user: %v", err)
+	}
+	if user.Role != "admin" {
+		return uuid.Nil, status.Errorf(codes.PermissionDenied, "requester is not an admin")
+	}
+
+	return adminUUID, nil
+}
+
+func (s *VendorService) GetBookingEvents(ctx context.Context, req *pb.GetBookingEventsRequest) (*pb.GetBookingEventsResponse, error) {
+	if req.BookingId == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "booking_id is required")
+	}
+
+	requesterUUID, err := uuid.Parse(req.RequesterId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid requester ID format: %v", err)
+	}
+
+	booking, err := s.vendorRepo.GetBookingById(ctx, req.BookingId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, status.Errorf(codes.NotFound, "booking not found")
+	} else if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to fetch booking: %v", err)
+	}
+
+	// The requester's role is worked out from the booking and the users
+	// table; the role the caller claims is not trusted.
+	if participantRole(booking, requesterUUID) == "" {
+		if _, err := s.authorizeAdmin(ctx, req.RequesterId); err != nil {
+			return nil, err
+		}
+	}
+
+	events, err := s.vendorRepo.GetBookingEvents(ctx, req.BookingId)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to fetch booking events: %v", err)
+	}
+
+	var protoEvents []*pb.BookingEvent
+	for _, event := range events {
+		// System events have no actor. Events recorded before that was the
+		// case carry the vendor's ID and cannot be rewritten, since the log
+		// is append-only, so the role decides.
+		var actorID string
+		if event.ActorID != uuid.Nil && event.ActorRole != "system" {
+			actorID = event.ActorID.String()
+		}
+		protoEvents = append(protoEvents, &pb.BookingEvent{
+			EventId:    event.ID.String(),
+			ActorId:    actorID,
+			ActorRole:  event.ActorRole,
+			Action:     event.Action,
+			OldStatus:  event.OldStatus,
+			NewStatus:  event.NewStatus,
+			ReasonCode: event.ReasonCode,
+			Reason:     event.Reason,
+			OccurredAt: timestamppb.New(event.CreatedAt),
+		})
+	}
+
+	return &pb.GetBookingEventsResponse{
+		Events: protoEvents,
+	}, nil
+}
@@ -0,0 +1,105 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	clientModel "github.com/AthulKrishna2501/zyra-client-service/internals/core/models"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/models"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/models/responses"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/money"
+)
+
+func escrowStatus(detail *responses.BookingDetail) string {
+	switch {
+	case detail.IsFundReleased:
+		return "released"
+	case detail.Status == "rejected":
+		return "refunded"
+	default:
+		return "held"
+	}
+}
+
+func buildBookingTimeline(detail *responses.BookingDetail, events []models.BookingEvent, transactions []clientModel.Transaction) ([]responses.TimelineEvent, error) {
+	price, err := money.FromMajor(int64(detail.Price))
+	if err != nil {
+		return nil, fmt.Errorf("invalid booking price: %w", err)
+	}
+
+	timeline := []responses.TimelineEvent{
+		{
+			Event:       "booking_requested",
+			Description: fmt.Sprintf("Client requested %s", detail.Service),
+			Amount:      price,
+			Status:      "pending",
+			OccurredAt:  detail.CreatedAt,
+		},
+	}
+
+	for _, event := range events {
+		description := fmt.Sprintf("%s changed booking from %s to %s", event.ActorRole, event.OldStatus, event.NewStatus)
+		if event.Reason != "" {
+			description += ": " + event.Reason
+		}
+		timeline = append(timeline, responses.TimelineEvent{
+			Event:       event.Action,
+			Description: description,
+			Status:      event.NewStatus,
+			OccurredAt:  event.CreatedAt,
+		})
+	}
+
+	// Bookings changed before the event log existed only carry their last update.
+	if len(events) == 0 && detail.Status != "pending" && !detail.UpdatedAt.IsZero() {
+		timeline = append(timeline, responses.TimelineEvent{
+			Event:       "status_changed",
+			Description: fmt.Sprintf("Booking marked as %s", detail.Status),
+			Status:      detail.Status,
+			OccurredAt:  detail.UpdatedAt,
+		})
+	}
+
+	for i := range transactions {
+		txn := &transactions[i]
+		amount, err := models.TransactionAmount(txn)
+		if err != nil {
+			return nil, fmt.Errorf("invalid amount for transaction %s: %w", txn.TransactionID, err)
+		}
+		timeline = append(timeline, responses.TimelineEvent{
+			Event:       "transaction",
+			Description: txn.Purpose,
+			Amount:      amount,
+			Status:      txn.PaymentStatus,
+			OccurredAt:  txn.DateOfPayment,
+		})
+	}
+
+	sort.SliceStable(timeline, func(i, j int) bool {
+		return timeline[i].OccurredAt.Before(timeline[j].OccurredAt)
+	})
+
+	return timeline, nil
+}
+
+// bookingWindowViolation explains why a booking requested at requestedAt for
+// eventDate falls outside the service's notice or advance window, if it does.
+func bookingWindowViolation(service *models.Service, requestedAt, eventDate time.Time) string {
+	if service.MinNoticeHours > 0 && eventDate.Sub(requestedAt) < time.Duration(service.MinNoticeHours)*time.Hour {
+		return fmt.Sprintf("%s needs at least %d hours' notice", service.ServiceTitle, service.MinNoticeHours)
+	}
+
+	if service.MaxAdvanceDays > 0 && eventDate.After(requestedAt.AddDate(0, 0, service.MaxAdvanceDays)) {
+		return fmt.Sprintf("%s cannot be booked more than %d days in advance", service.ServiceTitle, service.MaxAdvanceDays)
+	}
+
+	return ""
+}
+
+// startOfDay normalises a booking date to midnight UTC, the key waitlist
+// entries are stored under.
+func startOfDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,53 @@
+package services
+
+import (
+	"context"
+
+	pb "github.com/AthulKrishna2501/proto-repo/vendor"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const maxBulkDecisions = 100
+
+func (s *VendorService) BulkDecideBookings(ctx context.Context, req *pb.BulkDecideBookingsRequest) (*pb.BulkDecideBookingsResponse, error) {
+	if req.VendorId == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "vendor_id is required")
+	}
+
+	if len(req.BookingIds) == 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "booking_ids is required")
+	}
+
+	if len(req.BookingIds) > maxBulkDecisions {
+		return nil, status.Errorf(codes.InvalidArgument, "at most %d bookings can be decided at once", maxBulkDecisions)
+	}
+
+	if _, err := bookingDecisionEvent(req.Status, req.RejectionReasonCode, req.RejectionNote); err != nil {
+		return nil, err
+	}
+
+	resp := &pb.BulkDecideBookingsResponse{}
+	seen := map[string]bool{}
+	for _, bookingID := range req.BookingIds {
+		if seen[bookingID] {
+			continue
+		}
+		seen[bookingID] = true
+
+		result := &pb.BulkDecisionResult{BookingId: bookingID, Success: true}
+
+		event, _ := bookingDecisionEvent(req.Status, req.RejectionReasonCode, req.RejectionNote)
+		if err := s.decideBooking(ctx, req.VendorId, bookingID, req.Status, event); err != nil {
+			result.Success = false
+			result.Error = status.Convert(err).Message()
+			resp.Failed++
+		} else {
+			resp.Succeeded++
+		}
+
+		resp.Results = append(resp.Results, result)
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,409 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	pb "github.com/AthulKrishna2501/proto-repo/vendor"
+	adminModel "github.com/AthulKrishna2501/zyra-admin-service/internals/core/models"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/models"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/money"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/repository"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"gorm.io/gorm"
+)
+
+const (
+	minBundleServices     = 2
+	maxBundleServices     = 10
+	maxBundleDiscountRate = 50
+)
+
+func (s *VendorService) SetBundleDiscounts(ctx context.Context, req *pb.SetBundleDiscountsRequest) (*pb.SetBundleDiscountsResponse, error) {
+	vendorUUID, err := uuid.Parse(req.VendorId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid vendor ID format: %v", err)
+	}
+
+	seen := make(map[int32]bool)
+	var discounts []models.BundleDiscount
+	for _, tier := range req.Discounts {
+		if tier.MinServices < minBundleServices {
+			return nil, status.Errorf(codes.InvalidArgument, "min_services must be at least %d", minBundleServices)
+		}
+		if tier.Percent <= 0 || tier.Percent > maxBundleDiscountRate {
+			return nil, status.Errorf(codes.InvalidArgument, "discount percent must be between 1 and %d", maxBundleDiscountRate)
+		}
+		if seen[tier.MinServices] {
+			return nil, status.Errorf(codes.InvalidArgument, "duplicate discount for %d services", tier.MinServices)
+		}
+		seen[tier.MinServices] = true
+
+		discounts = append(discounts, models.BundleDiscount{
+			VendorID:    vendorUUID,
+			MinServices: int(tier.MinServices),
+			Percent:     int(tier.Percent),
+		})
+	}
+
+	if err := s.vendorRepo.ReplaceBundleDiscounts(ctx, vendorUUID, discounts); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to save bundle discounts: %v", err)
+	}
+
+	return &pb.SetBundleDiscountsResponse{
+		Message: "Bundle discounts updated successfully",
+	}, nil
+}
+
+func (s *VendorService) ListBundleDiscounts(ctx context.Context, req *pb.ListBundleDiscountsRequest) (*pb.ListBundleDiscountsResponse, error) {
+	vendorUUID, err := uuid.Parse(req.VendorId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid vendor ID format: %v", err)
+	}
+
+	discounts, err := s.vendorRepo.GetBundleDiscounts(ctx, vendorUUID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to fetch bundle discounts: %v", err)
+	}
+
+	var protoDiscounts []*pb.BundleDiscount
+	for _, discount := range discounts {
+		protoDiscounts = append(protoDiscounts, &pb.BundleDiscount{
+			MinServices: int32(discount.MinServices),
+			Percent:     int32(discount.Percent),
+		})
+	}
+
+	return &pb.ListBundleDiscountsResponse{
+		Discounts: protoDiscounts,
+	}, nil
+}
+
+func (s *VendorService) CreateBundleBooking(ctx context.Context, req *pb.CreateBundleBookingRequest) (*pb.CreateBundleBookingResponse, error) {
+	clientUUID, err := uuid.Parse(req.ClientId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid client ID format: %v", err)
+	}
+
+	vendorUUID, err := uuid.Parse(req.VendorId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid vendor ID format: %v", err)
+	}
+
+	if err := s.ensureClientNotBlocked(ctx, vendorUUID, clientUUID); err != nil {
+		return nil, err
+	}
+
+	if req.Date == nil {
+		return nil, status.Errorf(codes.InvalidArgument, "date is required")
+	}
+	date := req.Date.AsTime()
+	now := time.Now()
+
+	seen := make(map[string]bool)
+	var serviceIDs []string
+	for _, serviceID := range req.ServiceIds {
+		if !seen[serviceID] {
+			seen[serviceID] = true
+			serviceIDs = append(serviceIDs, serviceID)
+		}
+	}
+	if len(serviceIDs) < minBundleServices || len(serviceIDs) > maxBundleServices {
+		return nil, status.Errorf(codes.InvalidArgument, "a bundle needs between %d and %d different services", minBundleServices, maxBundleServices)
+	}
+
+	bundle := &models.BundleBooking{
+		ClientID: clientUUID,
+		VendorID: vendorUUID,
+		Date:     date,
+		Status:   "pending",
+		Subtotal: money.Zero(),
+	}
+
+	for _, serviceID := range serviceIDs {
+		service, err := s.vendorRepo.GetServiceByID(ctx, serviceID)
+		if err != nil {
+			return nil, status.Errorf(codes.NotFound, "service %s not found: %v", serviceID, err)
+		}
+		if service.VendorID != vendorUUID {
+			return nil, status.Errorf(codes.InvalidArgument, "service %s does not belong to the vendor", serviceID)
+		}
+		if reason := bookingWindowViolation(service, now, date); reason != "" {
+			return nil, status.Errorf(codes.FailedPrecondition, "%s", reason)
+		}
+
+		if bundle.Subtotal, err = bundle.Subtotal.Add(service.ServicePrice); err != nil {
+			return nil, moneyError(err)
+		}
+		bundle.Items = append(bundle.Items, models.BundleItem{
+			ServiceID:    service.ID,
+			ServiceTitle: service.ServiceTitle,
+			ListPrice:    service.ServicePrice,
+		})
+	}
+
+	discounts, err := s.vendorRepo.GetBundleDiscounts(ctx, vendorUUID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to fetch bundle discounts: %v", err)
+	}
+	bundle.DiscountPercent = bundleDiscountPercent(discounts, len(bundle.Items))
+	if err := applyBundleDiscount(bundle); err != nil {
+		return nil, moneyError(err)
+	}
+
+	installments, err := s.bundleInstallments(ctx, bundle, now)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to prepare booking installments: %v", err)
+	}
+
+	err = s.vendorRepo.CreateBundleBooking(ctx, bundle, installments)
+	if errors.Is(err, repository.ErrInsufficientBalance) {
+		return nil, status.Errorf(codes.FailedPrecondition, "insufficient wallet balance for bundle total of %s", bundle.Total)
+	} else if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create bundle booking: %v", err)
+	}
+
+	return &pb.CreateBundleBookingResponse{
+		Bundle:  bundleToProto(bundle, nil),
+		Message: "Bundle booking requested successfully",
+	}, nil
+}
+
+// DecideBundleBooking applies one vendor decision to every booking in the
+// bundle. Approval is checked for all items before any is approved, and the
+// decision is recorded for the bundle and all its bookings in one
+// transaction, refunding or releasing each booking's own funds.
+func (s *VendorService) DecideBundleBooking(ctx context.Context, req *pb.DecideBundleBookingRequest) (*pb.DecideBundleBookingResponse, error) {
+	event, err := bookingDecisionEvent(req.Status, req.RejectionReasonCode, req.RejectionNote)
+	if err != nil {
+		return nil, err
+	}
+
+	bundle, err := s.getBundle(ctx, req.BundleId)
+	if err != nil {
+		return nil, err
+	}
+
+	vendorUUID, _ := uuid.Parse(req.VendorId)
+	if bundle.VendorID != vendorUUID {
+		return nil, status.Errorf(codes.PermissionDenied, "bundle does not belong to the vendor")
+	}
+
+	if bundle.Status != "pending" {
+		return nil, status.Errorf(codes.FailedPrecondition, "bundle is already %s", bundle.Status)
+	}
+
+	bookings, err := s.bundleBookings(ctx, bundle)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, booking := range bookings {
+		if booking.Status != "pending" {
+			return nil, status.Errorf(codes.FailedPrecondition, "bundle item %s is already %s", booking.Service, booking.Status)
+		}
+		if req.Status == "approved" {
+			if err := s.checkBookingApprovable(ctx, req.VendorId, booking); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	event.ActorID = vendorUUID
+	if event.Reason == "" {
+		event.Reason = fmt.Sprintf("Bundle %s", bundle.ID)
+	}
+
+	err = s.vendorRepo.DecideBundleBooking(ctx, bundle, bookings, req.Status, event)
+	if errors.Is(err, repository.ErrInvalidTransition) {
+		return nil, status.Errorf(codes.FailedPrecondition, "%v", err)
+	} else if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to record bundle decision: %v", err)
+	}
+
+	if req.Status == "rejected" {
+		for _, item := range bundle.Items {
+			if _, err := s.offerFreedSlot(ctx, bundle.VendorID, item.ServiceID, bundle.Date); err != nil {
+				s.log.Error("Failed to offer freed slot to waitlist", bundle.ID, err)
+			}
+		}
+	}
+
+	return &pb.DecideBundleBookingResponse{
+		Message: fmt.Sprintf("Bundle %s successfully", req.Status),
+	}, nil
+}
+
+func (s *VendorService) GetBundleBooking(ctx context.Context, req *pb.GetBundleBookingRequest) (*pb.GetBundleBookingResponse, error) {
+	bundle, err := s.getBundle(ctx, req.BundleId)
+	if err != nil {
+		return nil, err
+	}
+
+	requesterUUID, _ := uuid.Parse(req.RequesterId)
+	if bundle.ClientID != requesterUUID && bundle.VendorID != requesterUUID {
+		return nil, status.Errorf(codes.PermissionDenied, "bundle does not belong to the requester")
+	}
+
+	bookings, err := s.bundleBookings(ctx, bundle)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.GetBundleBookingResponse{
+		Bundle: bundleToProto(bundle, bookings),
+	}, nil
+}
+
+func (s *VendorService) getBundle(ctx context.Context, bundleID string) (*models.BundleBooking, error) {
+	if bundleID == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "bundle_id is required")
+	}
+
+	bundle, err := s.vendorRepo.GetBundleBooking(ctx, bundleID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, status.Errorf(codes.NotFound, "bundle not found")
+	} else if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to fetch bundle: %v", err)
+	}
+
+	return bundle, nil
+}
+
+// bundleInstallments gives every item its booking ID and builds the
+// installments for items whose service has a payment schedule. The bundle is
+// paid in full when it is created, so every installment starts out paid.
+func (s *VendorService) bundleInstallments(ctx context.Context, bundle *models.BundleBooking, now time.Time) ([]models.BookingInstallment, error) {
+	var installments []models.BookingInstallment
+	for i := range bundle.Items {
+		item := &bundle.Items[i]
+		item.BookingID = uuid.New()
+
+		milestones, err := s.vendorRepo.GetPaymentMilestones(ctx, item.ServiceID)
+		if err != nil {
+			return nil, err
+		}
+		if len(milestones) == 0 {
+			continue
+		}
+
+		price, err := item.Price.Major()
+		if err != nil {
+			return nil, err
+		}
+		booking := &adminModel.Booking{
+			BookingID: item.BookingID,
+			Date:      bundle.Date,
+			Price:     int(price),
+			CreatedAt: now,
+		}
+		itemInstallments, err := buildInstallments(booking, milestones, item.Price, now)
+		if err != nil {
+			return nil, err
+		}
+		installments = append(installments, itemInstallments...)
+	}
+	return installments, nil
+}
+
+func (s *VendorService) bundleBookings(ctx context.Context, bundle *models.BundleBooking) ([]*adminModel.Booking, error) {
+	bookings := make([]*adminModel.Booking, 0, len(bundle.Items))
+	for _, item := range bundle.Items {
+		booking, err := s.vendorRepo.GetBookingById(ctx, item.BookingID.String())
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to fetch bundle item booking: %v", err)
+		}
+		bookings = append(bookings, booking)
+	}
+	return bookings, nil
+}
+
+// bundleDiscountPercent picks the best discount the bundle size qualifies for.
+func bundleDiscountPercent(discounts []models.BundleDiscount, services int) int {
+	percent := 0
+	for _, discount := range discounts {
+		if services >= discount.MinServices && discount.Percent > percent {
+			percent = discount.Percent
+		}
+	}
+	return percent
+}
+
+// applyBundleDiscount spreads the bundle discount over the items in proportion
+// to their list prices, so refunds of individual items stay consistent with
+// what the client paid. Item prices become booking prices, which are whole
+// units, so shares are truncated to whole units and the last item absorbs the
+// remainder.
+func applyBundleDiscount(bundle *models.BundleBooking) error {
+	discount, err := bundle.Subtotal.Percent(int64(bundle.DiscountPercent))
+	if err != nil {
+		return err
+	}
+	bundle.DiscountAmount = discount.TruncateMajor()
+	if bundle.Total, err = bundle.Subtotal.Sub(bundle.DiscountAmount); err != nil {
+		return err
+	}
+
+	allocated := money.Zero()
+	for i := range bundle.Items {
+		share := money.Zero()
+		if i == len(bundle.Items)-1 {
+			share, err = bundle.DiscountAmount.Sub(allocated)
+		} else if bundle.Subtotal.IsPositive() {
+			share, err = bundle.Items[i].ListPrice.MulDiv(bundle.DiscountAmount.Amount, bundle.Subtotal.Amount)
+			share = share.TruncateMajor()
+		}
+		if err != nil {
+			return err
+		}
+
+		if allocated, err = allocated.Add(share); err != nil {
+			return err
+		}
+		if bundle.Items[i].Price, err = bundle.Items[i].ListPrice.Sub(share); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func bundleToProto(bundle *models.BundleBooking, bookings []*adminModel.Booking) *pb.BundleBooking {
+	statuses := make(map[uuid.UUID]string, len(bookings))
+	for _, booking := range bookings {
+		statuses[booking.BookingID] = booking.Status
+	}
+
+	protoBundle := &pb.BundleBooking{
+		BundleId:        bundle.ID.String(),
+		ClientId:        bundle.ClientID.String(),
+		VendorId:        bundle.VendorID.String(),
+		Date:            timestamppb.New(bundle.Date),
+		Status:          bundle.Status,
+		Subtotal:        moneyToProto(bundle.Subtotal),
+		DiscountPercent: int32(bundle.DiscountPercent),
+		DiscountAmount:  moneyToProto(bundle.DiscountAmount),
+		Total:           moneyToProto(bundle.Total),
+	}
+
+	for _, item := range bundle.Items {
+		itemStatus, ok := statuses[item.BookingID]
+		if !ok {
+			itemStatus = bundle.Status
+		}
+		protoBundle.Items = append(protoBundle.Items, &pb.BundleItem{
+			BookingId:    item.BookingID.String(),
+			ServiceId:    item.ServiceID.String(),
+			ServiceTitle: item.ServiceTitle,
+			ListPrice:    moneyToProto(item.ListPrice),
+			Price:        moneyToProto(item.Price),
+			Status:       itemStatus,
+		})
+	}
+
+	return protoBundle
+}
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/models"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/money"
+)
+
+func TestBundleDiscountPercent(t *testing.T) {
+	discounts := []models.BundleDiscount{
+		{MinServices: 2, Percent: 5},
+		{MinServices: 3, Percent: 10},
+		{MinServices: 5, Percent: 8},
+	}
+
+	tests := []struct {
+		services int
+		want     int
+	}{
+		{1, 0},
+		{2, 5},
+		{3, 10},
+		{4, 10},
+		{6, 10},
+	}
+
+	for _, tt := range tests {
+		if got := bundleDiscountPercent(discounts, tt.services); got != tt.want {
+			t.Errorf("bundleDiscountPercent(%d services) = %d, want %d", tt.services, got, tt.want)
+		}
+	}
+}
+
+func TestApplyBundleDiscount(t *testing.T) {
+	tests := []struct {
+		name         string
+		listPrices   []int64
+		percent      int
+		wantDiscount int64
+		wantPrices   []int64
+	}{
+		{
+			name:         "no discount",
+			listPrices:   []int64{400, 600},
+			percent:      0,
+			wantDiscount: 0,
+			wantPrices:   []int64{400, 600},
+		},
+		{
+			name:         "split in proportion",
+			listPrices:   []int64{400, 600},
+			percent:      10,
+			wantDiscount: 100,
+			wantPrices:   []int64{360, 540},
+		},
+		{
+			name:         "discount truncated to whole units",
+			listPrices:   []int64{333, 334},
+			percent:      15,
+			wantDiscount: 100,
+			wantPrices:   []int64{284, 283},
+		},
+		{
+			name:         "last item absorbs the remainder",
+			listPrices:   []int64{100, 100, 100},
+			percent:      10,
+			wantDiscount: 30,
+			wantPrices:   []int64{90, 90, 90},
+		},
+		{
+			name:         "shares that do not divide evenly",
+			listPrices:   []int64{101, 101, 101},
+			percent:      10,
+			wantDiscount: 30,
+			wantPrices:   []int64{91, 91, 91},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bundle := &models.BundleBooking{DiscountPercent: tt.percent, Subtotal: money.Zero()}
+			for _, listPrice := range tt.listPrices {
+				price, _ := money.FromMajor(listPrice)
+				bundle.Subtotal, _ = bundle.Subtotal.Add(price)
+				bundle.Items = append(bundle.Items, models.BundleItem{ListPrice: price})
+			}
+
+			if err := applyBundleDiscount(bundle); err != nil {
+				t.Fatalf("applyBundleDiscount: %v", err)
+			}
+
+			if want, _ := money.FromMajor(tt.wantDiscount); bundle.DiscountAmount != want {
+				t.Errorf("discount = %s, want %s", bundle.DiscountAmount, want)
+			}
+
+			total := money.Zero()
+			for i, item := range bundle.Items {
+				if want, _ := money.FromMajor(tt.wantPrices[i]); item.Price != want {
+					t.Errorf("item %d price = %s, want %s", i, item.Price, want)
+				}
+				if item.Price != item.Price.TruncateMajor() {
+					t.Errorf("item %d price %s is not whole units", i, item.Price)
+				}
+				total, _ = total.Add(item.Price)
+			}
+			if total != bundle.Total {
+				t.Errorf("item prices add up to %s, want the bundle total %s", total, bundle.Total)
+			}
+		})
+	}
+}
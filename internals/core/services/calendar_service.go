@@ -0,0 +1,55 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	pb "github.com/AthulKrishna2501/proto-repo/vendor"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/models"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+func newCalendarToken() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func (s *VendorService) GetCalendarFeed(ctx context.Context, req *pb.GetCalendarFeedRequest) (*pb.GetCalendarFeedResponse, error) {
+	vendorUUID, err := uuid.Parse(req.VendorId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid vendor ID format: %v", err)
+	}
+
+	feed, err := s.vendorRepo.GetCalendarFeed(ctx, req.VendorId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		feed = &models.CalendarFeed{VendorID: vendorUUID}
+	} else if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to fetch calendar feed: %v", err)
+	}
+
+	if feed.Token == "" || req.Rotate {
+		token, err := newCalendarToken()
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to generate calendar token: %v", err)
+		}
+		feed.Token = token
+
+		if err := s.vendorRepo.SaveCalendarFeed(ctx, feed); err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to save calendar feed: %v", err)
+		}
+	}
+
+	return &pb.GetCalendarFeedResponse{
+		FeedUrl: fmt.Sprintf("%s/calendar/%s.ics", strings.TrimSuffix(s.cfg.PUBLIC_BASE_URL, "/"), feed.Token),
+	}, nil
+}
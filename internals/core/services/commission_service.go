@@ -0,0 +1,152 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	pb "github.com/AthulKrishna2501/proto-repo/vendor"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/models"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/repository"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const maxCommissionBasisPoints = 10000
+
+// SetCommissionRule creates or replaces the platform commission for the
+// whole platform, a category or a vendor tier.
+func (s *VendorService) SetCommissionRule(ctx context.Context, req *pb.SetCommissionRuleRequest) (*pb.SetCommissionRuleResponse, error) {
+	if _, err := s.authorizeAdmin(ctx, req.AdminId); err != nil {
+		return nil, err
+	}
+
+	rule := &models.CommissionRule{Scope: req.Scope}
+	switch req.Scope {
+	case models.CommissionScopeGlobal:
+	case models.CommissionScopeCategory:
+		categoryUUID, err := uuid.Parse(req.CategoryId)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid category ID format: %v", err)
+		}
+		rule.ScopeKey = categoryUUID.String()
+	case models.CommissionScopeTier:
+		rule.ScopeKey = strings.TrimSpace(req.Tier)
+		if rule.ScopeKey == "" {
+			return nil, status.Errorf(codes.InvalidArgument, "tier is required for a tier commission")
+		}
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "scope must be global, category or tier")
+	}
+
+	if req.RateBasisPoints < 0 || req.RateBasisPoints > maxCommissionBasisPoints {
+		return nil, status.Errorf(codes.InvalidArgument, "rate must be between 0 and %d basis points", maxCommissionBasisPoints)
+	}
+	rule.RateBasisPoints = int(req.RateBasisPoints)
+
+	fixedFee, err := wholeMoneyFromProto(req.FixedFee, "fixed_fee")
+	if err != nil {
+		return nil, err
+	}
+	if fixedFee.IsNegative() {
+		return nil, status.Errorf(codes.InvalidArgument, "fixed fee cannot be negative")
+	}
+	rule.FixedFee = fixedFee
+
+	if err := s.vendorRepo.SaveCommissionRule(ctx, rule); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to save commission rule: %v", err)
+	}
+
+	return &pb.SetCommissionRuleResponse{
+		Message: "Commission rule saved",
+		Rule:    commissionRuleToProto(rule),
+	}, nil
+}
+
+func (s *VendorService) ListCommissionRules(ctx context.Context, req *pb.ListCommissionRulesRequest) (*pb.ListCommissionRulesResponse, error) {
+	if _, err := s.authorizeAdmin(ctx, req.AdminId); err != nil {
+		return nil, err
+	}
+
+	rules, err := s.vendorRepo.ListCommissionRules(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to fetch commission rules: %v", err)
+	}
+
+	var protoRules []*pb.CommissionRule
+	for i := range rules {
+		protoRules = append(protoRules, commissionRuleToProto(&rules[i]))
+	}
+
+	return &pb.ListCommissionRulesResponse{
+		Rules: protoRules,
+	}, nil
+}
+
+func (s *VendorService) DeleteCommissionRule(ctx context.Context, req *pb.DeleteCommissionRuleRequest) (*pb.DeleteCommissionRuleResponse, error) {
+	if _, err := s.authorizeAdmin(ctx, req.AdminId); err != nil {
+		return nil, err
+	}
+
+	if _, err := uuid.Parse(req.RuleId); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid rule ID format: %v", err)
+	}
+
+	err := s.vendorRepo.DeleteCommissionRule(ctx, req.RuleId)
+	if errors.Is(err, repository.ErrCommissionRuleNotFound) {
+		return nil, status.Errorf(codes.NotFound, "%v", err)
+	} else if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to delete commission rule: %v", err)
+	}
+
+	return &pb.DeleteCommissionRuleResponse{
+		Message: "Commission rule deleted",
+	}, nil
+}
+
+// SetVendorTier moves a vendor into a commission tier. An empty tier removes
+// the vendor from their tier.
+func (s *VendorService) SetVendorTier(ctx context.Context, req *pb.SetVendorTierRequest) (*pb.SetVendorTierResponse, error) {
+	if _, err := s.authorizeAdmin(ctx, req.AdminId); err != nil {
+		return nil, err
+	}
+
+	vendorUUID, err := uuid.Parse(req.VendorId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid vendor ID format: %v", err)
+	}
+
+	tier := strings.TrimSpace(req.Tier)
+	if err := s.vendorRepo.SetVendorTier(ctx, vendorUUID, tier); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to set vendor tier: %v", err)
+	}
+
+	message := "Vendor tier updated"
+	if tier == "" {
+		message = "Vendor removed from tier"
+	}
+	return &pb.SetVendorTierResponse{
+		Message: message,
+	}, nil
+}
+
+func commissionRuleToProto(rule *models.CommissionRule) *pb.CommissionRule {
+	protoRule := &pb.CommissionRule{
+		RuleId:          rule.ID.String(),
+		Scope:           rule.Scope,
+		RateBasisPoints: int32(rule.RateBasisPoints),
+		FixedFee:        moneyToProto(rule.FixedFee),
+		UpdatedAt:       timestamppb.New(rule.UpdatedAt),
+	}
+
+	switch rule.Scope {
+	case models.CommissionScopeCategory:
+		protoRule.CategoryId = rule.ScopeKey
+	case models.CommissionScopeTier:
+		protoRule.Tier = rule.ScopeKey
+	}
+
+	return protoRule
+}
@@ -0,0 +1,211 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	pb "github.com/AthulKrishna2501/proto-repo/vendor"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/models"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/money"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/repository"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"gorm.io/gorm"
+)
+
+const defaultDisputeWindow = 48 * time.Hour
+
+func (s *VendorService) disputeWindow() time.Duration {
+	if s.cfg.DISPUTE_WINDOW_HOURS > 0 {
+		return time.Duration(s.cfg.DISPUTE_WINDOW_HOURS) * time.Hour
+	}
+	return defaultDisputeWindow
+}
+
+func (s *VendorService) MarkBookingCompleted(ctx context.Context, req *pb.MarkBookingCompletedRequest) (*pb.MarkBookingCompletedResponse, error) {
+	if req.BookingId == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "booking_id is required")
+	}
+
+	if req.VendorId == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "vendor_id is required")
+	}
+
+	vendorUUID, err := uuid.Parse(req.VendorId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid vendor ID format: %v", err)
+	}
+
+	booking, err := s.vendorRepo.GetBookingById(ctx, req.BookingId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, status.Errorf(codes.NotFound, "booking not found")
+	} else if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to fetch booking: %v", err)
+	}
+
+	if booking.VendorID != vendorUUID {
+		return nil, status.Errorf(codes.PermissionDenied, "booking does not belong to the vendor")
+	}
+
+	if !booking.IsVendorApproved || !booking.IsClientApproved {
+		return nil, status.Errorf(codes.FailedPrecondition, "booking must be approved by both vendor and client")
+	}
+
+	if booking.IsFundReleased {
+		return nil, status.Errorf(codes.FailedPrecondition, "payment for this booking is already released")
+	}
+
+	now := time.Now()
+	if booking.Date.After(now) {
+		return nil, status.Errorf(codes.FailedPrecondition, "event has not taken place yet")
+	}
+
+	completion := &models.BookingCompletion{
+		BookingID:           booking.BookingID,
+		VendorID:            booking.VendorID,
+		CompletedAt:         now,
+		DisputeWindowEndsAt: now.Add(s.disputeWindow()),
+		PayoutStatus:        "awaiting_release",
+	}
+
+	event := &models.BookingEvent{
+		ActorID:   vendorUUID,
+		ActorRole: "vendor",
+		Action:    "booking_completed",
+	}
+
+	err = s.vendorRepo.MarkBookingCompleted(ctx, completion, event)
+	switch {
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return nil, status.Errorf(codes.AlreadyExists, "booking is already marked as completed")
+	case errors.Is(err, repository.ErrInvalidTransition):
+		return nil, status.Errorf(codes.FailedPrecondition, "%v", err)
+	case err != nil:
+		return nil, status.Errorf(codes.Internal, "failed to mark booking as completed: %v", err)
+	}
+
+	return &pb.MarkBookingCompletedResponse{
+		Message:             "Booking marked as completed",
+		DisputeWindowEndsAt: timestamppb.New(completion.DisputeWindowEndsAt),
+	}, nil
+}
+
+func (s *VendorService) OpenDispute(ctx context.Context, req *pb.OpenDisputeRequest) (*pb.OpenDisputeResponse, error) {
+	if req.BookingId == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "booking_id is required")
+	}
+
+	if req.Reason == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "reason is required")
+	}
+
+	clientUUID, err := uuid.Parse(req.ClientId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid client ID format: %v", err)
+	}
+
+	booking, err := s.vendorRepo.GetBookingById(ctx, req.BookingId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, status.Errorf(codes.NotFound, "booking not found")
+	} else if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to fetch booking: %v", err)
+	}
+
+	if booking.ClientID != clientUUID {
+		return nil, status.Errorf(codes.PermissionDenied, "booking does not belong to the client")
+	}
+
+	if _, err := s.vendorRepo.GetBookingCompletion(ctx, req.BookingId); errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, status.Errorf(codes.FailedPrecondition, "booking is not marked as completed")
+	} else if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to fetch booking completion: %v", err)
+	}
+
+	dispute := &models.BookingDispute{
+		ID:        uuid.New(),
+		BookingID: booking.BookingID,
+		ClientID:  clientUUID,
+		Reason:    req.Reason,
+		Status:    "open",
+	}
+
+	event := &models.BookingEvent{
+		ActorID:   clientUUID,
+		ActorRole: "client",
+		Action:    "dispute_opened",
+		Reason:    req.Reason,
+	}
+
+	err = s.vendorRepo.OpenDispute(ctx, dispute, event)
+	if errors.Is(err, repository.ErrDisputeWindowClosed) || errors.Is(err, repository.ErrInvalidTransition) {
+		return nil, status.Errorf(codes.FailedPrecondition, "dispute window for this booking is closed")
+	} else if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to open dispute: %v", err)
+	}
+
+	return &pb.OpenDisputeResponse{
+		DisputeId: dispute.ID.String(),
+		Message:   "Dispute opened, payout is on hold",
+	}, nil
+}
+
+func (s *VendorService) ResolveDispute(ctx context.Context, req *pb.ResolveDisputeRequest) (*pb.ResolveDisputeResponse, error) {
+	if req.DisputeId == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "dispute_id is required")
+	}
+
+	adminUUID, err := s.authorizeAdmin(ctx, req.AdminId)
+	if err != nil {
+		return nil, err
+	}
+
+	refundAmount := money.Zero()
+	switch req.Resolution {
+	case "partial_refund":
+		refundAmount, err = wholeMoneyFromProto(req.RefundAmount, "refund_amount")
+		if err != nil {
+			return nil, err
+		}
+		if !refundAmount.IsPositive() {
+			return nil, status.Errorf(codes.InvalidArgument, "partial refund must be positive")
+		}
+	case "full_refund", "no_refund":
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "Invalid resolution. Allowed values: 'full_refund', 'partial_refund', 'no_refund'")
+	}
+
+	event := &models.BookingEvent{
+		ActorID:    adminUUID,
+		ActorRole:  "admin",
+		Action:     "dispute_resolved",
+		ReasonCode: req.Resolution,
+	}
+
+	_, err = s.vendorRepo.ResolveDispute(ctx, req.DisputeId, req.Resolution, refundAmount, adminUUID, event)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return nil, status.Errorf(codes.NotFound, "dispute not found")
+	case errors.Is(err, repository.ErrDisputeNotOpen), errors.Is(err, repository.ErrInvalidTransition):
+		return nil, status.Errorf(codes.FailedPrecondition, "%v", err)
+	case errors.Is(err, repository.ErrRefundExceedsHeld):
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	case err != nil:
+		return nil, status.Errorf(codes.Internal, "failed to resolve dispute: %v", err)
+	}
+
+	return &pb.ResolveDisputeResponse{
+		Message: fmt.Sprintf("Dispute resolved with %s", req.Resolution),
+	}, nil
+}
+
+// recordBookingEvent appends an event that is not tied to a status change.
+// Failures are logged rather than undoing the money movement already made.
+func (s *VendorService) recordBookingEvent(ctx context.Context, event *models.BookingEvent) {
+	if err := s.vendorRepo.CreateBookingEvent(ctx, event); err != nil {
+		s.log.Error("Failed to record booking event", event.BookingID, event.Action, err)
+	}
+}
@@ -0,0 +1,258 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	pb "github.com/AthulKrishna2501/proto-repo/vendor"
+	adminModel "github.com/AthulKrishna2501/zyra-admin-service/internals/core/models"
+	clientModel "github.com/AthulKrishna2501/zyra-client-service/internals/core/models"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/models"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+func (s *VendorService) ownedService(ctx context.Context, vendorID, serviceID string) (*models.Service, error) {
+	service, err := s.vendorRepo.GetServiceByID(ctx, serviceID)
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "service not found: %v", err)
+	}
+
+	vendorUUID, _ := uuid.Parse(vendorID)
+	if service.VendorID != vendorUUID {
+		return nil, status.Errorf(codes.PermissionDenied, "service does not belong to the vendor")
+	}
+
+	return service, nil
+}
+
+func (s *VendorService) SetInstantBookingRules(ctx context.Context, req *pb.SetInstantBookingRulesRequest) (*pb.SetInstantBookingRulesResponse, error) {
+	service, err := s.ownedService(ctx, req.VendorId, req.ServiceId)
+	if err != nil {
+		return nil, err
+	}
+
+	var rules []models.InstantBookingRule
+	for _, rule := range req.Rules {
+		if rule.MinDaysAhead < 0 || rule.MaxDaysAhead < 0 {
+			return nil, status.Errorf(codes.InvalidArgument, "days ahead cannot be negative")
+		}
+		if rule.MaxDaysAhead > 0 && rule.MaxDaysAhead < rule.MinDaysAhead {
+			return nil, status.Errorf(codes.InvalidArgument, "max_days_ahead must not be less than min_days_ahead")
+		}
+
+		rules = append(rules, models.InstantBookingRule{
+			ServiceID:       service.ID,
+			Enabled:         rule.Enabled,
+			MinDaysAhead:    int(rule.MinDaysAhead),
+			MaxDaysAhead:    int(rule.MaxDaysAhead),
+			RequireOpenDate: rule.RequireOpenDate,
+		})
+	}
+
+	if err := s.vendorRepo.ReplaceInstantBookingRules(ctx, service.ID, rules); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to save instant booking rules: %v", err)
+	}
+
+	return &pb.SetInstantBookingRulesResponse{
+		Message: "Instant booking rules updated successfully",
+	}, nil
+}
+
+func (s *VendorService) ListInstantBookingRules(ctx context.Context, req *pb.ListInstantBookingRulesRequest) (*pb.ListInstantBookingRulesResponse, error) {
+	service, err := s.ownedService(ctx, req.VendorId, req.ServiceId)
+	if err != nil {
+		return nil, err
+	}
+
+	rules, err := s.vendorRepo.GetInstantBookingRules(ctx, service.ID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to fetch instant booking rules: %v", err)
+	}
+
+	var protoRules []*pb.InstantBookingRule
+	for _, rule := range rules {
+		protoRules = append(protoRules, &pb.InstantBookingRule{
+			RuleId:          rule.ID.String(),
+			Enabled:         rule.Enabled,
+			MinDaysAhead:    int32(rule.MinDaysAhead),
+			MaxDaysAhead:    int32(rule.MaxDaysAhead),
+			RequireOpenDate: rule.RequireOpenDate,
+		})
+	}
+
+	return &pb.ListInstantBookingRulesResponse{
+		Rules: protoRules,
+	}, nil
+}
+
+// ProcessNewBooking is called once a client has created a booking, with the
+// transaction the client paid it with. It keeps a snapshot of the service's
+// terms, links the payment to the booking and, when the service has a payment
+// schedule, sets up the booking's installments from the amount the
+// transaction actually collected. If the booking matches one of
+// the service's instant booking rules it is then approved on the vendor's
+// behalf through the same path as ApproveBooking.
+func (s *VendorService) ProcessNewBooking(ctx context.Context, req *pb.ProcessNewBookingRequest) (*pb.ProcessNewBookingResponse, error) {
+	if req.BookingId == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "booking_id is required")
+	}
+
+	transactionUUID := uuid.Nil
+	if req.TransactionId != "" {
+		var err error
+		if transactionUUID, err = uuid.Parse(req.TransactionId); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid transaction ID format: %v", err)
+		}
+	}
+
+	booking, err := s.vendorRepo.GetBookingById(ctx, req.BookingId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, status.Errorf(codes.NotFound, "booking not found")
+	} else if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to fetch booking: %v", err)
+	}
+
+	service, err := s.vendorRepo.GetServiceByTitle(ctx, booking.VendorID.String(), booking.Service)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		service = nil
+	} else if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to fetch service: %v", err)
+	}
+
+	var transaction *clientModel.Transaction
+	var installments []models.BookingInstallment
+	if transactionUUID != uuid.Nil {
+		transaction, err = s.vendorRepo.GetTransactionByID(ctx, req.TransactionId)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, status.Errorf(codes.NotFound, "transaction not found")
+		} else if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to fetch transaction: %v", err)
+		}
+		if transaction.UserID != booking.ClientID {
+			return nil, status.Errorf(codes.InvalidArgument, "transaction was not made by the booking's client")
+		}
+
+		collected, err := models.TransactionAmount(transaction)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "invalid transaction amount: %v", err)
+		}
+		installments, err = s.bookingInstallments(ctx, booking, service, collected, transaction.DateOfPayment)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to prepare booking installments: %v", err)
+		}
+	}
+
+	if err := s.vendorRepo.RecordNewBooking(ctx, booking, service, transaction, installments); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to record booking: %v", err)
+	}
+
+	if booking.Status != "pending" {
+		return &pb.ProcessNewBookingResponse{Message: "Booking is not awaiting approval"}, nil
+	}
+
+	blocked, err := s.vendorRepo.IsClientBlocked(ctx, booking.VendorID, booking.ClientID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to check blocklist: %v", err)
+	}
+	if blocked {
+		event := &models.BookingEvent{
+			ActorRole:  "system",
+			Action:     "booking_rejected",
+			ReasonCode: "client_conduct",
+			Reason:     blockedClientReason,
+		}
+		if err := s.decideBooking(ctx, booking.VendorID.String(), req.BookingId, "rejected", event); err != nil {
+			return nil, err
+		}
+
+		return &pb.ProcessNewBookingResponse{Message: "Booking rejected: " + blockedClientReason}, nil
+	}
+
+	if service == nil {
+		return &pb.ProcessNewBookingResponse{Message: "No service found for booking"}, nil
+	}
+
+	if err := s.vendorRepo.ClaimWaitlistOffer(ctx, service.ID, booking.ClientID, startOfDay(booking.Date)); err != nil {
+		s.log.Warn("Failed to mark waitlist offer as claimed", req.BookingId, err)
+	}
+
+	if reason := bookingWindowViolation(service, booking.CreatedAt, booking.Date); reason != "" {
+		event := &models.BookingEvent{
+			ActorRole:  "system",
+			Action:     "booking_rejected",
+			ReasonCode: "booking_window",
+			Reason:     reason,
+		}
+		if err := s.decideBooking(ctx, booking.VendorID.String(), req.BookingId, "rejected", event); err != nil {
+			return nil, err
+		}
+
+		return &pb.ProcessNewBookingResponse{Message: "Booking rejected: " + reason}, nil
+	}
+
+	rules, err := s.vendorRepo.GetInstantBookingRules(ctx, service.ID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to fetch instant booking rules: %v", err)
+	}
+
+	rule, err := s.matchInstantBookingRule(ctx, booking, rules, time.Now())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to evaluate instant booking rules: %v", err)
+	}
+	if rule == nil {
+		return &pb.ProcessNewBookingResponse{Message: "Booking awaits vendor approval"}, nil
+	}
+
+	event := &models.BookingEvent{
+		ActorRole: "system",
+		Action:    "booking_auto_approved",
+		Reason:    fmt.Sprintf("Matched instant booking rule %s", rule.ID),
+	}
+	if err := s.decideBooking(ctx, booking.VendorID.String(), req.BookingId, "approved", event); err != nil {
+		s.log.Warn("Instant booking rule matched but approval failed", req.BookingId, err)
+		return &pb.ProcessNewBookingResponse{Message: "Booking awaits vendor approval"}, nil
+	}
+
+	return &pb.ProcessNewBookingResponse{
+		AutoApproved: true,
+		Message:      "Booking approved instantly",
+	}, nil
+}
+
+func (s *VendorService) matchInstantBookingRule(ctx context.Context, booking *adminModel.Booking, rules []models.InstantBookingRule, now time.Time) (*models.InstantBookingRule, error) {
+	daysAhead := int(booking.Date.Sub(now).Hours() / 24)
+
+	var dateOpen *bool
+	for i := range rules {
+		rule := &rules[i]
+		if !rule.Enabled || daysAhead < rule.MinDaysAhead {
+			continue
+		}
+		if rule.MaxDaysAhead > 0 && daysAhead > rule.MaxDaysAhead {
+			continue
+		}
+
+		if rule.RequireOpenDate {
+			if dateOpen == nil {
+				count, err := s.vendorRepo.CountBookingsOnDate(ctx, booking.VendorID, booking.Date, booking.BookingID)
+				if err != nil {
+					return nil, err
+				}
+				open := count == 0
+				dateOpen = &open
+			}
+			if !*dateOpen {
+				continue
+			}
+		}
+
+		return rule, nil
+	}
+
+	return nil, nil
+}
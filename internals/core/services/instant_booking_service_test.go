@@ -0,0 +1,123 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	adminModel "github.com/AthulKrishna2501/zyra-admin-service/internals/core/models"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/models"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/repository"
+	"github.com/google/uuid"
+)
+
+// bookedDayRepo reports a fixed number of bookings on every day. Methods the
+// tests do not stub panic through the nil embedded interface.
+type bookedDayRepo struct {
+	repository.VendorRepository
+	booked int64
+	calls  int
+}
+
+func (r *bookedDayRepo) CountBookingsOnDate(ctx context.Context, vendorID uuid.UUID, date time.Time, excludeBookingID uuid.UUID) (int64, error) {
+	r.calls++
+	return r.booked, nil
+}
+
+func TestMatchInstantBookingRule(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	rule := func(id byte, enabled bool, minDays, maxDays int, openDate bool) models.InstantBookingRule {
+		return models.InstantBookingRule{
+			ID:              uuid.UUID{id},
+			Enabled:         enabled,
+			MinDaysAhead:    minDays,
+			MaxDaysAhead:    maxDays,
+			RequireOpenDate: openDate,
+		}
+	}
+
+	tests := []struct {
+		name      string
+		daysAhead int
+		booked    int64
+		rules     []models.InstantBookingRule
+		want      byte
+		wantCalls int
+	}{
+		{
+			name:      "no rules",
+			daysAhead: 10,
+		},
+		{
+			name:      "disabled rule is skipped",
+			daysAhead: 10,
+			rules:     []models.InstantBookingRule{rule(1, false, 0, 0, false)},
+		},
+		{
+			name:      "too soon",
+			daysAhead: 2,
+			rules:     []models.InstantBookingRule{rule(1, true, 3, 0, false)},
+		},
+		{
+			name:      "too far ahead",
+			daysAhead: 40,
+			rules:     []models.InstantBookingRule{rule(1, true, 0, 30, false)},
+		},
+		{
+			name:      "no upper bound",
+			daysAhead: 400,
+			rules:     []models.InstantBookingRule{rule(1, true, 0, 0, false)},
+			want:      1,
+		},
+		{
+			name:      "first matching rule wins",
+			daysAhead: 10,
+			rules:     []models.InstantBookingRule{rule(1, true, 20, 0, false), rule(2, true, 5, 15, false), rule(3, true, 0, 0, false)},
+			want:      2,
+		},
+		{
+			name:      "open date required and free",
+			daysAhead: 10,
+			rules:     []models.InstantBookingRule{rule(1, true, 0, 0, true)},
+			want:      1,
+			wantCalls: 1,
+		},
+		{
+			name:      "open date required but taken",
+			daysAhead: 10,
+			booked:    1,
+			rules:     []models.InstantBookingRule{rule(1, true, 0, 0, true), rule(2, true, 0, 0, true), rule(3, true, 0, 0, false)},
+			want:      3,
+			wantCalls: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &bookedDayRepo{booked: tt.booked}
+			s := &VendorService{vendorRepo: repo}
+			booking := &adminModel.Booking{
+				BookingID: uuid.New(),
+				VendorID:  uuid.New(),
+				Date:      now.AddDate(0, 0, tt.daysAhead).Add(time.Hour),
+			}
+
+			got, err := s.matchInstantBookingRule(context.Background(), booking, tt.rules, now)
+			if err != nil {
+				t.Fatalf("matchInstantBookingRule: %v", err)
+			}
+
+			var gotID byte
+			if got != nil {
+				gotID = got.ID[0]
+			}
+			if gotID != tt.want {
+				t.Errorf("matched rule %d, want %d", gotID, tt.want)
+			}
+			if repo.calls != tt.wantCalls {
+				t.Errorf("counted bookings %d times, want %d", repo.calls, tt.wantCalls)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	pb "github.com/AthulKrishna2501/proto-repo/vendor"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/invoice"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/models"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/repository"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"gorm.io/gorm"
+)
+
+const maxTaxRateBasisPoints = 10000
+
+// ListInvoices returns the requester's invoices: everything issued by a
+// vendor, or the receipts and credit notes issued to a client. With a booking
+// ID it lists only that booking's.
+func (s *VendorService) ListInvoices(ctx context.Context, req *pb.ListInvoicesRequest) (*pb.ListInvoicesResponse, error) {
+	requesterUUID, err := uuid.Parse(req.RequesterId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid requester ID format: %v", err)
+	}
+
+	filter := repository.InvoiceFilter{}
+
+	if req.BookingId != "" {
+		booking, err := s.vendorRepo.GetBookingById(ctx, req.BookingId)
+		if err != nil {
+			return nil, status.Errorf(codes.NotFound, "booking not found: %v", err)
+		}
+
+		switch requesterUUID {
+		case booking.VendorID:
+			filter.VendorID = requesterUUID
+		case booking.ClientID:
+			filter.ClientID = requesterUUID
+		default:
+			return nil, status.Errorf(codes.PermissionDenied, "booking does not belong to the requester")
+		}
+		filter.BookingID = booking.BookingID
+	} else if user, err := s.vendorRepo.GetVendorByID(req.RequesterId); err == nil && user.Role == "vendor" {
+		filter.VendorID = requesterUUID
+	} else {
+		filter.ClientID = requesterUUID
+	}
+
+	invoices, err := s.vendorRepo.ListInvoices(ctx, filter)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to fetch invoices: %v", err)
+	}
+
+	protoInvoices := make([]*pb.Invoice, 0, len(invoices))
+	for i := range invoices {
+		protoInvoices = append(protoInvoices, s.invoiceToProto(&invoices[i]))
+	}
+
+	return &pb.ListInvoicesResponse{Invoices: protoInvoices}, nil
+}
+
+// GetInvoiceDocument renders one invoice as a PDF, or as HTML when asked.
+func (s *VendorService) GetInvoiceDocument(ctx context.Context, req *pb.GetInvoiceDocumentRequest) (*pb.GetInvoiceDocumentResponse, error) {
+	requesterUUID, err := uuid.Parse(req.RequesterId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid requester ID format: %v", err)
+	}
+
+	format := strings.ToLower(req.Format)
+	if format == "" {
+		format = invoice.FormatPDF
+	}
+
+	inv, err := s.vendorRepo.GetInvoice(ctx, req.InvoiceId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, status.Errorf(codes.NotFound, "invoice not found")
+	} else if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to fetch invoice: %v", err)
+	}
+
+	isClient := inv.Kind != models.InvoiceKindPayout && inv.ClientID != nil && *inv.ClientID == requesterUUID
+	if inv.VendorID != requesterUUID && !isClient {
+		return nil, status.Errorf(codes.PermissionDenied, "invoice does not belong to the requester")
+	}
+
+	content, contentType, err := invoice.Render(inv, format)
+	if errors.Is(err, invoice.ErrUnknownFormat) {
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	} else if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to render invoice: %v", err)
+	}
+
+	return &pb.GetInvoiceDocumentResponse{
+		Invoice:     s.invoiceToProto(inv),
+		Content:     content,
+		ContentType: contentType,
+		FileName:    invoice.FileName(inv, format),
+	}, nil
+}
+
+func (s *VendorService) invoiceToProto(inv *models.Invoice) *pb.Invoice {
+	protoInvoice := &pb.Invoice{
+		InvoiceId:     inv.ID.String(),
+		Number:        inv.Number,
+		Kind:          inv.Kind,
+		VendorId:      inv.VendorID.String(),
+		SellerName:    inv.SellerName,
+		SellerAddress: inv.SellerAddress,
+		SellerTaxId:   inv.SellerTaxID,
+		BuyerName:     inv.BuyerName,
+		BuyerEmail:    inv.BuyerEmail,
+		TaxTotal:      moneyToProto(inv.TaxTotal),
+		Total:         moneyToProto(inv.Total),
+		IssuedAt:      timestamppb.New(inv.IssuedAt),
+		DownloadUrl:   fmt.Sprintf("%s/invoices/%s.pdf", strings.TrimSuffix(s.cfg.PUBLIC_BASE_URL, "/"), inv.AccessToken),
+	}
+	if inv.BookingID != nil {
+		protoInvoice.BookingId = inv.BookingID.String()
+	}
+	if inv.ClientID != nil {
+		protoInvoice.ClientId = inv.ClientID.String()
+	}
+
+	for _, line := range inv.Lines {
+		protoInvoice.Lines = append(protoInvoice.Lines, &pb.InvoiceLine{
+			Kind:        line.Kind,
+			Description: line.Description,
+			Quantity:    int32(line.Quantity),
+			UnitPrice:   moneyToProto(line.UnitPrice),
+			Amount:      moneyToProto(line.Amount),
+		})
+	}
+
+	return protoInvoice
+}
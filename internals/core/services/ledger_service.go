@@ -0,0 +1,129 @@
+package services
+
+import (
+	"context"
+
+	pb "github.com/AthulKrishna2501/proto-repo/vendor"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/repository"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const defaultLedgerStatementLimit = 50
+
+// GetWalletLedger returns the ledger balance and most recent entries of a
+// client or vendor wallet to its owner or an admin.
+func (s *VendorService) GetWalletLedger(ctx context.Context, req *pb.GetWalletLedgerRequest) (*pb.GetWalletLedgerResponse, error) {
+	ownerType := req.OwnerType
+	if ownerType != repository.LedgerOwnerClient && ownerType != repository.LedgerOwnerVendor {
+		return nil, status.Errorf(codes.InvalidArgument, "owner type must be client or vendor")
+	}
+
+	ownerUUID, err := uuid.Parse(req.OwnerId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid owner ID format: %v", err)
+	}
+
+	requesterUUID, err := uuid.Parse(req.RequesterId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid requester ID format: %v", err)
+	}
+
+	// Only the wallet's owner and admins may read its ledger.
+	if requesterUUID != ownerUUID {
+		if _, err := s.authorizeAdmin(ctx, req.RequesterId); err != nil {
+			return nil, err
+		}
+	}
+
+	limit := int(req.Limit)
+	if limit <= 0 || limit > 500 {
+		limit = defaultLedgerStatementLimit
+	}
+
+	balance, lines, err := s.vendorRepo.GetLedgerStatement(ctx, ownerType, ownerUUID, limit)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to fetch ledger: %v", err)
+	}
+
+	var entries []*pb.LedgerEntry
+	for _, line := range lines {
+		entries = append(entries, &pb.LedgerEntry{
+			TransactionId: line.TransactionID,
+			Kind:          line.Kind,
+			Reference:     line.Reference,
+			Description:   line.Description,
+			Direction:     line.Direction,
+			Amount:        moneyToProto(line.Amount),
+			CreatedAt:     timestamppb.New(line.CreatedAt),
+		})
+	}
+
+	return &pb.GetWalletLedgerResponse{
+		Balance: moneyToProto(balance),
+		Entries: entries,
+	}, nil
+}
+
+// ReconcileWallets reports every wallet whose stored balance disagrees with
+// its ledger. Money moved by other services outside the ledger shows up here.
+func (s *VendorService) ReconcileWallets(ctx context.Context, req *pb.ReconcileWalletsRequest) (*pb.ReconcileWalletsResponse, error) {
+	if _, err := s.authorizeAdmin(ctx, req.AdminId); err != nil {
+		return nil, err
+	}
+
+	discrepancies, err := s.vendorRepo.ReconcileWallets(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to reconcile wallets: %v", err)
+	}
+
+	var protoDiscrepancies []*pb.WalletDiscrepancy
+	for _, d := range discrepancies {
+		protoDiscrepancies = append(protoDiscrepancies, &pb.WalletDiscrepancy{
+			WalletId:      d.WalletID,
+			OwnerType:     d.OwnerType,
+			OwnerId:       d.OwnerID,
+			WalletBalance: moneyToProto(d.WalletBalance),
+			LedgerBalance: moneyToProto(d.LedgerBalance),
+		})
+	}
+
+	if len(protoDiscrepancies) > 0 {
+		s.log.Warn("Wallets out of balance with the ledger", len(protoDiscrepancies))
+	}
+
+	return &pb.ReconcileWalletsResponse{
+		Discrepancies: protoDiscrepancies,
+	}, nil
+}
+
+// GetTreasuryBalances shows each of the platform's treasury accounts: the
+// admin wallet behind it and the balance of its ledger account.
+func (s *VendorService) GetTreasuryBalances(ctx context.Context, req *pb.GetTreasuryBalancesRequest) (*pb.GetTreasuryBalancesResponse, error) {
+	if _, err := s.authorizeAdmin(ctx, req.AdminId); err != nil {
+		return nil, err
+	}
+
+	balances, err := s.vendorRepo.GetTreasuryBalances(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to fetch treasury balances: %v", err)
+	}
+
+	var accounts []*pb.TreasuryAccount
+	for _, balance := range balances {
+		accounts = append(accounts, &pb.TreasuryAccount{
+			Role:             balance.Role,
+			Email:            balance.Email,
+			WalletBalance:    moneyToProto(balance.WalletBalance),
+			TotalDeposits:    moneyToProto(balance.TotalDeposits),
+			TotalWithdrawals: moneyToProto(balance.TotalWithdrawals),
+			LedgerBalance:    moneyToProto(balance.LedgerBalance),
+		})
+	}
+
+	return &pb.GetTreasuryBalancesResponse{
+		Accounts: accounts,
+	}, nil
+}
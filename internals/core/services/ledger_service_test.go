@@ -0,0 +1,68 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	pb "github.com/AthulKrishna2501/proto-repo/vendor"
+	auth "github.com/AthulKrishna2501/zyra-auth-service/internals/core/models"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/models/responses"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/money"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/repository"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+type ledgerRepo struct {
+	repository.VendorRepository
+
+	users map[string]*auth.User
+}
+
+func (r *ledgerRepo) GetUserByID(ctx context.Context, userID string) (*auth.User, error) {
+	user, ok := r.users[userID]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return user, nil
+}
+
+func (r *ledgerRepo) GetLedgerStatement(ctx context.Context, ownerType string, ownerID uuid.UUID, limit int) (money.Money, []responses.LedgerStatementLine, error) {
+	return money.Zero(), nil, nil
+}
+
+func TestGetWalletLedgerAuthorization(t *testing.T) {
+	owner, admin, other := uuid.New(), uuid.New(), uuid.New()
+	repo := &ledgerRepo{users: map[string]*auth.User{
+		admin.String(): {UserID: admin, Role: "admin"},
+		other.String(): {UserID: other, Role: "client"},
+	}}
+	svc := &VendorService{vendorRepo: repo, log: nopLogger{}}
+
+	tests := []struct {
+		name      string
+		requester string
+		code      codes.Code
+	}{
+		{name: "owner", requester: owner.String(), code: codes.OK},
+		{name: "admin", requester: admin.String(), code: codes.OK},
+		{name: "another user", requester: other.String(), code: codes.PermissionDenied},
+		{name: "unknown user", requester: uuid.NewString(), code: codes.PermissionDenied},
+		{name: "missing requester", requester: "", code: codes.InvalidArgument},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.GetWalletLedger(context.Background(), &pb.GetWalletLedgerRequest{
+				OwnerType:   repository.LedgerOwnerVendor,
+				OwnerId:     owner.String(),
+				RequesterId: tt.requester,
+			})
+			if code := status.Code(err); code != tt.code {
+				t.Errorf("code = %s, want %s (%v)", code, tt.code, err)
+			}
+		})
+	}
+}
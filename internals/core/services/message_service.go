@@ -0,0 +1,302 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"time"
+
+	pb "github.com/AthulKrishna2501/proto-repo/vendor"
+	adminModel "github.com/AthulKrishna2501/zyra-admin-service/internals/core/models"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/models"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultMessagePageSize = 50
+	maxMessageAttachments  = 5
+)
+
+func bookingMessageChannel(bookingID string) string {
+	return "booking_messages:" + bookingID
+}
+
+func participantRole(booking *adminModel.Booking, userID uuid.UUID) string {
+	switch userID {
+	case booking.VendorID:
+		return "vendor"
+	case booking.ClientID:
+		return "client"
+	default:
+		return ""
+	}
+}
+
+func (s *VendorService) authorizeBookingParticipant(ctx context.Context, bookingID, userID string) (*adminModel.Booking, uuid.UUID, string, error) {
+	if bookingID == "" {
+		return nil, uuid.Nil, "", status.Errorf(codes.InvalidArgument, "booking_id is required")
+	}
+
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, uuid.Nil, "", status.Errorf(codes.InvalidArgument, "invalid user ID format: %v", err)
+	}
+
+	booking, err := s.vendorRepo.GetBookingById(ctx, bookingID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, uuid.Nil, "", status.Errorf(codes.NotFound, "booking not found")
+	} else if err != nil {
+		return nil, uuid.Nil, "", status.Errorf(codes.Internal, "failed to fetch booking: %v", err)
+	}
+
+	role := participantRole(booking, userUUID)
+	if role == "" {
+		return nil, uuid.Nil, "", status.Errorf(codes.PermissionDenied, "user is not part of this booking")
+	}
+
+	return booking, userUUID, role, nil
+}
+
+func toProtoBookingMessage(message *models.BookingMessage) *pb.BookingMessage {
+	var attachments []*pb.MessageAttachment
+	for _, attachment := range message.Attachments {
+		attachments = append(attachments, &pb.MessageAttachment{
+			FileName:    attachment.FileName,
+			FileUrl:     attachment.FileURL,
+			ContentType: attachment.ContentType,
+			SizeBytes:   attachment.SizeBytes,
+		})
+	}
+
+	protoMessage := &pb.BookingMessage{
+		MessageId:   message.ID.String(),
+		BookingId:   message.BookingID.String(),
+		SenderId:    message.SenderID.String(),
+		SenderRole:  message.SenderRole,
+		Body:        message.Body,
+		Attachments: attachments,
+		SentAt:      timestamppb.New(message.CreatedAt),
+	}
+	if message.ReadAt != nil {
+		protoMessage.ReadAt = timestamppb.New(*message.ReadAt)
+	}
+
+	return protoMessage
+}
+
+func (s *VendorService) SendBookingMessage(ctx context.Context, req *pb.SendBookingMessageRequest) (*pb.SendBookingMessageResponse, error) {
+	booking, senderUUID, role, err := s.authorizeBookingParticipant(ctx, req.BookingId, req.SenderId)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Body == "" && len(req.Attachments) == 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "message body or attachment is required")
+	}
+
+	if len(req.Attachments) > maxMessageAttachments {
+		return nil, status.Errorf(codes.InvalidArgument, "a message can carry at most %d attachments", maxMessageAttachments)
+	}
+
+	message := &models.BookingMessage{
+		ID:         uuid.New(),
+		BookingID:  booking.BookingID,
+		SenderID:   senderUUID,
+		SenderRole: role,
+		Body:       req.Body,
+		CreatedAt:  time.Now(),
+	}
+	for _, attachment := range req.Attachments {
+		if attachment.FileUrl == "" || attachment.FileName == "" {
+			return nil, status.Errorf(codes.InvalidArgument, "attachment file_name and file_url are required")
+		}
+		message.Attachments = append(message.Attachments, models.MessageAttachment{
+			MessageID:   message.ID,
+			FileName:    attachment.FileName,
+			FileURL:     attachment.FileUrl,
+			ContentType: attachment.ContentType,
+			SizeBytes:   attachment.SizeBytes,
+		})
+	}
+
+	if err := s.vendorRepo.CreateBookingMessage(ctx, message); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to send message: %v", err)
+	}
+
+	payload, err := json.Marshal(message)
+	if err == nil {
+		err = s.redisClient.Publish(ctx, bookingMessageChannel(req.BookingId), payload).Err()
+	}
+	if err != nil {
+		s.log.Warn("Failed to publish booking message", req.BookingId, err)
+	}
+
+	return &pb.SendBookingMessageResponse{
+		Message: toProtoBookingMessage(message),
+	}, nil
+}
+
+func (s *VendorService) ListBookingMessages(ctx context.Context, req *pb.ListBookingMessagesRequest) (*pb.ListBookingMessagesResponse, error) {
+	if _, _, _, err := s.authorizeBookingParticipant(ctx, req.BookingId, req.UserId); err != nil {
+		return nil, err
+	}
+
+	before := time.Now()
+	if req.Before != nil {
+		before = req.Before.AsTime()
+	}
+
+	limit := defaultMessagePageSize
+	if req.Limit > 0 && int(req.Limit) < defaultMessagePageSize {
+		limit = int(req.Limit)
+	}
+
+	messages, err := s.vendorRepo.GetBookingMessages(ctx, req.BookingId, before, limit)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to fetch messages: %v", err)
+	}
+
+	var protoMessages []*pb.BookingMessage
+	for i := range messages {
+		protoMessages = append(protoMessages, toProtoBookingMessage(&messages[i]))
+	}
+
+	return &pb.ListBookingMessagesResponse{
+		Messages: protoMessages,
+	}, nil
+}
+
+func (s *VendorService) MarkMessagesRead(ctx context.Context, req *pb.MarkMessagesReadRequest) (*pb.MarkMessagesReadResponse, error) {
+	_, readerUUID, _, err := s.authorizeBookingParticipant(ctx, req.BookingId, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	marked, err := s.vendorRepo.MarkMessagesRead(ctx, req.BookingId, readerUUID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to mark messages as read: %v", err)
+	}
+
+	return &pb.MarkMessagesReadResponse{
+		MarkedRead: marked,
+	}, nil
+}
+
+func (s *VendorService) GetUnreadMessageCounts(ctx context.Context, req *pb.GetUnreadMessageCountsRequest) (*pb.GetUnreadMessageCountsResponse, error) {
+	if req.VendorId == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "vendor_id is required")
+	}
+	if _, err := uuid.Parse(req.VendorId); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid vendor ID format: %v", err)
+	}
+
+	counts, err := s.vendorRepo.GetUnreadMessageCounts(ctx, req.VendorId)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to fetch unread counts: %v", err)
+	}
+
+	var total int64
+	var protoCounts []*pb.BookingUnreadCount
+	for _, count := range counts {
+		total += count.Unread
+		protoCounts = append(protoCounts, &pb.BookingUnreadCount{
+			BookingId: count.BookingID,
+			Unread:    count.Unread,
+		})
+	}
+
+	return &pb.GetUnreadMessageCountsResponse{
+		Counts:      protoCounts,
+		TotalUnread: total,
+	}, nil
+}
+
+// StreamBookingMessages sends the messages the caller missed since req.Since,
+// or the latest page when it is unset, followed by new messages as they are
+// sent. It subscribes before reading the history so nothing sent in between
+// is lost; a message that shows up in both is sent once.
+func (s *VendorService) StreamBookingMessages(req *pb.StreamBookingMessagesRequest, stream pb.VendorSevice_StreamBookingMessagesServer) error {
+	ctx := stream.Context()
+
+	if _, _, _, err := s.authorizeBookingParticipant(ctx, req.BookingId, req.UserId); err != nil {
+		return err
+	}
+
+	pubsub := s.redisClient.Subscribe(ctx, bookingMessageChannel(req.BookingId))
+	defer pubsub.Close()
+
+	if _, err := pubsub.Receive(ctx); err != nil {
+		return status.Errorf(codes.Unavailable, "failed to subscribe to messages: %v", err)
+	}
+	messages := pubsub.Channel()
+
+	sent := map[uuid.UUID]bool{}
+	send := func(history []models.BookingMessage) error {
+		for i := range history {
+			if err := stream.Send(toProtoBookingMessage(&history[i])); err != nil {
+				return err
+			}
+			sent[history[i].ID] = true
+		}
+		return nil
+	}
+
+	if req.Since != nil {
+		// Everything missed is replayed, a page at a time, before switching
+		// to live messages.
+		after, afterID := req.Since.AsTime(), uuid.Max
+		for {
+			history, err := s.vendorRepo.GetBookingMessagesAfter(ctx, req.BookingId, after, afterID, defaultMessagePageSize)
+			if err != nil {
+				return status.Errorf(codes.Internal, "failed to fetch messages: %v", err)
+			}
+			if err := send(history); err != nil {
+				return err
+			}
+			if len(history) < defaultMessagePageSize {
+				break
+			}
+			last := history[len(history)-1]
+			after, afterID = last.CreatedAt, last.ID
+		}
+	} else {
+		history, err := s.vendorRepo.GetBookingMessages(ctx, req.BookingId, time.Now(), defaultMessagePageSize)
+		if err != nil {
+			return status.Errorf(codes.Internal, "failed to fetch messages: %v", err)
+		}
+		slices.Reverse(history)
+		if err := send(history); err != nil {
+			return err
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg, ok := <-messages:
+			if !ok {
+				return status.Errorf(codes.Unavailable, "message stream closed")
+			}
+
+			var message models.BookingMessage
+			if err := json.Unmarshal([]byte(msg.Payload), &message); err != nil {
+				s.log.Warn("Dropping malformed booking message", req.BookingId, err)
+				continue
+			}
+			if sent[message.ID] {
+				delete(sent, message.ID)
+				continue
+			}
+
+			if err := stream.Send(toProtoBookingMessage(&message)); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package services
+
+import (
+	pb "github.com/AthulKrishna2501/proto-repo/vendor"
+	adminModel "github.com/AthulKrishna2501/zyra-admin-service/internals/core/models"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/models"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/money"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func moneyToProto(m money.Money) *pb.Money {
+	currency := m.Currency
+	if currency == "" {
+		currency = money.DefaultCurrency
+	}
+	return &pb.Money{Amount: m.Amount, Currency: currency}
+}
+
+// moneyFromProto reads an amount sent by a caller. A missing amount is zero
+// and a missing currency is the service's currency; any other currency is
+// rejected.
+func moneyFromProto(m *pb.Money) (money.Money, error) {
+	if m == nil {
+		return money.Zero(), nil
+	}
+	if m.Currency != "" && m.Currency != money.DefaultCurrency {
+		return money.Money{}, status.Errorf(codes.InvalidArgument, "unsupported currency %q, amounts must be in %s", m.Currency, money.DefaultCurrency)
+	}
+	return money.New(m.Amount), nil
+}
+
+// bookingPrice reads a booking's price, which the shared bookings table
+// stores in whole major units.
+func bookingPrice(booking *adminModel.Booking) (money.Money, error) {
+	price, err := models.BookingPrice(booking)
+	if err != nil {
+		return money.Money{}, status.Errorf(codes.Internal, "invalid booking price: %v", err)
+	}
+	return price, nil
+}
+
+// moneyError maps arithmetic failures to a gRPC error.
+func moneyError(err error) error {
+	return status.Errorf(codes.InvalidArgument, "invalid amount: %v", err)
+}
+
+// wholeMoneyFromProto reads an amount that will end up in a wallet or a
+// booking, both of which are kept in whole units.
+func wholeMoneyFromProto(m *pb.Money, field string) (money.Money, error) {
+	amount, err := moneyFromProto(m)
+	if err != nil {
+		return money.Money{}, err
+	}
+	if _, err := amount.Major(); err != nil {
+		return money.Money{}, status.Errorf(codes.InvalidArgument, "%s must be a whole amount, got %s", field, amount)
+	}
+	return amount, nil
+}
+
+// majorToProto converts a whole-unit amount read from one of the tables
+// shared with the other services.
+func majorToProto(major int64) (*pb.Money, error) {
+	amount, err := money.FromMajor(major)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "invalid stored amount: %v", err)
+	}
+	return moneyToProto(amount), nil
+}
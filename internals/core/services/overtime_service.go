@@ -0,0 +1,194 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	pb "github.com/AthulKrishna2501/proto-repo/vendor"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/models"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/repository"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"gorm.io/gorm"
+)
+
+const maxOvertimeHours = 24
+
+func (s *VendorService) SubmitOvertime(ctx context.Context, req *pb.SubmitOvertimeRequest) (*pb.SubmitOvertimeResponse, error) {
+	if req.BookingId == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "booking_id is required")
+	}
+
+	if req.ExtraHours <= 0 || req.ExtraHours > maxOvertimeHours {
+		return nil, status.Errorf(codes.InvalidArgument, "extra_hours must be between 1 and %d", maxOvertimeHours)
+	}
+
+	booking, err := s.vendorRepo.GetBookingById(ctx, req.BookingId)
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "booking not found: %v", err)
+	}
+
+	vendorUUID, _ := uuid.Parse(req.VendorId)
+	if booking.VendorID != vendorUUID {
+		return nil, status.Errorf(codes.PermissionDenied, "booking does not belong to the vendor")
+	}
+
+	if booking.Status != "completed" {
+		return nil, status.Errorf(codes.FailedPrecondition, "overtime can only be submitted on completed bookings")
+	}
+
+	service, err := s.vendorRepo.GetServiceByTitle(ctx, req.VendorId, booking.Service)
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "service not found for booking: %v", err)
+	}
+
+	if !service.AdditionalHourPrice.IsPositive() {
+		return nil, status.Errorf(codes.FailedPrecondition, "%s has no additional hour price set", service.ServiceTitle)
+	}
+
+	completion, err := s.vendorRepo.GetBookingCompletion(ctx, req.BookingId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, status.Errorf(codes.FailedPrecondition, "booking has no completion record")
+	} else if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to fetch booking completion: %v", err)
+	}
+	if completion.PayoutStatus != "awaiting_release" && completion.PayoutStatus != "disputed" {
+		return nil, status.Errorf(codes.FailedPrecondition, "booking has already been paid out")
+	}
+
+	amount, err := service.AdditionalHourPrice.Mul(int64(req.ExtraHours))
+	if err != nil {
+		return nil, moneyError(err)
+	}
+
+	charge := &models.OvertimeCharge{
+		BookingID:  booking.BookingID,
+		VendorID:   booking.VendorID,
+		ClientID:   booking.ClientID,
+		ExtraHours: int(req.ExtraHours),
+		HourlyRate: service.AdditionalHourPrice,
+		Amount:     amount,
+		Note:       strings.TrimSpace(req.Note),
+		Status:     "pending",
+	}
+
+	err = s.vendorRepo.CreateOvertimeCharge(ctx, charge)
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return nil, status.Errorf(codes.AlreadyExists, "an overtime charge is already awaiting client approval")
+	} else if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to submit overtime: %v", err)
+	}
+
+	s.recordBookingEvent(ctx, &models.BookingEvent{
+		BookingID: booking.BookingID,
+		ActorID:   vendorUUID,
+		ActorRole: "vendor",
+		Action:    "overtime_submitted",
+		OldStatus: booking.Status,
+		NewStatus: booking.Status,
+		Reason:    fmt.Sprintf("%d extra hours at %s", charge.ExtraHours, charge.HourlyRate),
+	})
+
+	return &pb.SubmitOvertimeResponse{
+		ChargeId: charge.ID.String(),
+		Amount:   moneyToProto(charge.Amount),
+		Message:  "Overtime submitted, awaiting client approval",
+	}, nil
+}
+
+func (s *VendorService) RespondToOvertime(ctx context.Context, req *pb.RespondToOvertimeRequest) (*pb.RespondToOvertimeResponse, error) {
+	if req.ChargeId == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "charge_id is required")
+	}
+
+	charge, err := s.vendorRepo.GetOvertimeCharge(ctx, req.ChargeId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, status.Errorf(codes.NotFound, "overtime charge not found")
+	} else if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to fetch overtime charge: %v", err)
+	}
+
+	clientUUID, _ := uuid.Parse(req.ClientId)
+	if charge.ClientID != clientUUID {
+		return nil, status.Errorf(codes.PermissionDenied, "overtime charge does not belong to the client")
+	}
+
+	action := "overtime_declined"
+	message := "Overtime charge declined"
+	if req.Approve {
+		_, err = s.vendorRepo.CollectOvertimeCharge(ctx, req.ChargeId)
+		action = "overtime_paid"
+		message = fmt.Sprintf("Overtime charge of %s paid", charge.Amount)
+	} else {
+		err = s.vendorRepo.DeclineOvertimeCharge(ctx, req.ChargeId)
+	}
+
+	switch {
+	case errors.Is(err, repository.ErrOvertimeNotPending), errors.Is(err, repository.ErrBookingPaidOut):
+		return nil, status.Errorf(codes.FailedPrecondition, "%v", err)
+	case errors.Is(err, repository.ErrInsufficientBalance):
+		return nil, status.Errorf(codes.FailedPrecondition, "insufficient wallet balance to pay overtime")
+	case err != nil:
+		return nil, status.Errorf(codes.Internal, "failed to update overtime charge: %v", err)
+	}
+
+	s.recordBookingEvent(ctx, &models.BookingEvent{
+		BookingID: charge.BookingID,
+		ActorID:   clientUUID,
+		ActorRole: "client",
+		Action:    action,
+		OldStatus: "completed",
+		NewStatus: "completed",
+		Reason:    fmt.Sprintf("%d extra hours, %s total", charge.ExtraHours, charge.Amount),
+	})
+
+	return &pb.RespondToOvertimeResponse{
+		Message: message,
+	}, nil
+}
+
+func (s *VendorService) ListOvertimeCharges(ctx context.Context, req *pb.ListOvertimeChargesRequest) (*pb.ListOvertimeChargesResponse, error) {
+	if req.BookingId == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "booking_id is required")
+	}
+
+	booking, err := s.vendorRepo.GetBookingById(ctx, req.BookingId)
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "booking not found: %v", err)
+	}
+
+	requesterUUID, _ := uuid.Parse(req.RequesterId)
+	if booking.ClientID != requesterUUID && booking.VendorID != requesterUUID {
+		return nil, status.Errorf(codes.PermissionDenied, "booking does not belong to the requester")
+	}
+
+	charges, err := s.vendorRepo.ListOvertimeCharges(ctx, req.BookingId)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to fetch overtime charges: %v", err)
+	}
+
+	var protoCharges []*pb.OvertimeCharge
+	for _, charge := range charges {
+		protoCharge := &pb.OvertimeCharge{
+			ChargeId:   charge.ID.String(),
+			ExtraHours: int32(charge.ExtraHours),
+			HourlyRate: moneyToProto(charge.HourlyRate),
+			Amount:     moneyToProto(charge.Amount),
+			Note:       charge.Note,
+			Status:     charge.Status,
+			CreatedAt:  timestamppb.New(charge.CreatedAt),
+		}
+		if charge.DecidedAt != nil {
+			protoCharge.DecidedAt = timestamppb.New(*charge.DecidedAt)
+		}
+		protoCharges = append(protoCharges, protoCharge)
+	}
+
+	return &pb.ListOvertimeChargesResponse{
+		Charges: protoCharges,
+	}, nil
+}
@@ -0,0 +1,431 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	pb "github.com/AthulKrishna2501/proto-repo/vendor"
+	adminModel "github.com/AthulKrishna2501/zyra-admin-service/internals/core/models"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/models"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/money"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/repository"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"gorm.io/gorm"
+)
+
+const maxPaymentMilestones = 5
+
+func (s *VendorService) SetPaymentSchedule(ctx context.Context, req *pb.SetPaymentScheduleRequest) (*pb.SetPaymentScheduleResponse, error) {
+	service, err := s.ownedService(ctx, req.VendorId, req.ServiceId)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(req.Milestones) > maxPaymentMilestones {
+		return nil, status.Errorf(codes.InvalidArgument, "a payment schedule can have at most %d milestones", maxPaymentMilestones)
+	}
+
+	var milestones []models.PaymentMilestone
+	totalPercent := 0
+	for i, milestone := range req.Milestones {
+		if milestone.Percent <= 0 {
+			return nil, status.Errorf(codes.InvalidArgument, "milestone percent must be positive")
+		}
+
+		if milestone.ReleaseOn != "approval" && milestone.ReleaseOn != "completion" {
+			return nil, status.Errorf(codes.InvalidArgument, "Invalid release_on. Allowed values: 'approval', 'completion'")
+		}
+
+		dueDaysBefore := int(milestone.DueDaysBefore)
+		if dueDaysBefore < 0 {
+			dueDaysBefore = -1
+		}
+
+		label := milestone.Label
+		if label == "" {
+			label = fmt.Sprintf("Installment %d", i+1)
+		}
+
+		totalPercent += int(milestone.Percent)
+		milestones = append(milestones, models.PaymentMilestone{
+			ServiceID:     service.ID,
+			Sequence:      i + 1,
+			Label:         label,
+			Percent:       int(milestone.Percent),
+			DueDaysBefore: dueDaysBefore,
+			ReleaseOn:     milestone.ReleaseOn,
+		})
+	}
+
+	if len(milestones) > 0 && totalPercent != 100 {
+		return nil, status.Errorf(codes.InvalidArgument, "milestone percents must add up to 100, got %d", totalPercent)
+	}
+
+	if err := s.vendorRepo.ReplacePaymentMilestones(ctx, service.ID, milestones); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to save payment schedule: %v", err)
+	}
+
+	return &pb.SetPaymentScheduleResponse{
+		Message: "Payment schedule updated successfully",
+	}, nil
+}
+
+func (s *VendorService) GetPaymentSchedule(ctx context.Context, req *pb.GetPaymentScheduleRequest) (*pb.GetPaymentScheduleResponse, error) {
+	service, err := s.ownedService(ctx, req.VendorId, req.ServiceId)
+	if err != nil {
+		return nil, err
+	}
+
+	milestones, err := s.vendorRepo.GetPaymentMilestones(ctx, service.ID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to fetch payment schedule: %v", err)
+	}
+
+	var protoMilestones []*pb.PaymentMilestone
+	for _, milestone := range milestones {
+		protoMilestones = append(protoMilestones, &pb.PaymentMilestone{
+			MilestoneId:   milestone.ID.String(),
+			Label:         milestone.Label,
+			Percent:       int32(milestone.Percent),
+			DueDaysBefore: int32(milestone.DueDaysBefore),
+			ReleaseOn:     milestone.ReleaseOn,
+		})
+	}
+
+	return &pb.GetPaymentScheduleResponse{
+		Milestones: protoMilestones,
+	}, nil
+}
+
+func (s *VendorService) GetBookingInstallments(ctx context.Context, req *pb.GetBookingInstallmentsRequest) (*pb.GetBookingInstallmentsResponse, error) {
+	if req.BookingId == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "booking_id is required")
+	}
+
+	booking, err := s.vendorRepo.GetBookingById(ctx, req.BookingId)
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "booking not found: %v", err)
+	}
+
+	requesterUUID, _ := uuid.Parse(req.RequesterId)
+	if booking.ClientID != requesterUUID && booking.VendorID != requesterUUID {
+		return nil, status.Errorf(codes.PermissionDenied, "booking does not belong to the requester")
+	}
+
+	installments, err := s.vendorRepo.GetBookingInstallments(ctx, req.BookingId)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to fetch installments: %v", err)
+	}
+
+	state, balanceDue, err := installmentPaymentState(installments, time.Now())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to total installments: %v", err)
+	}
+
+	var protoInstallments []*pb.BookingInstallment
+	for _, installment := range installments {
+		protoInstallment := &pb.BookingInstallment{
+			InstallmentId: installment.ID.String(),
+			Label:         installment.Label,
+			Status:        installment.Status,
+			ReleaseOn:     installment.ReleaseOn,
+			Sequence:      int32(installment.Sequence),
+			Amount:        moneyToProto(installment.Amount),
+			DueAt:         timestamppb.New(installment.DueAt),
+		}
+		if installment.PaidAt != nil {
+			protoInstallment.PaidAt = timestamppb.New(*installment.PaidAt)
+		}
+		if installment.ReleasedAt != nil {
+			protoInstallment.ReleasedAt = timestamppb.New(*installment.ReleasedAt)
+		}
+		protoInstallments = append(protoInstallments, protoInstallment)
+	}
+
+	return &pb.GetBookingInstallmentsResponse{
+		Installments: protoInstallments,
+		PaymentState: state,
+		BalanceDue:   moneyToProto(balanceDue),
+	}, nil
+}
+
+func (s *VendorService) PayInstallment(ctx context.Context, req *pb.PayInstallmentRequest) (*pb.PayInstallmentResponse, error) {
+	if req.BookingId == "" || req.InstallmentId == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "booking_id and installment_id are required")
+	}
+
+	booking, err := s.vendorRepo.GetBookingById(ctx, req.BookingId)
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "booking not found: %v", err)
+	}
+
+	clientUUID, _ := uuid.Parse(req.ClientId)
+	if booking.ClientID != clientUUID {
+		return nil, status.Errorf(codes.PermissionDenied, "booking does not belong to the client")
+	}
+
+	if booking.Status != "approved" {
+		return nil, status.Errorf(codes.FailedPrecondition, "installments can only be paid on approved bookings, booking is %s", booking.Status)
+	}
+
+	installments, err := s.vendorRepo.GetBookingInstallments(ctx, req.BookingId)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to fetch installments: %v", err)
+	}
+
+	found := false
+	for _, installment := range installments {
+		if installment.ID.String() == req.InstallmentId {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, status.Errorf(codes.NotFound, "installment not found for booking")
+	}
+
+	installment, err := s.vendorRepo.PayInstallment(ctx, req.InstallmentId, clientUUID)
+	switch {
+	case errors.Is(err, repository.ErrInstallmentNotPayable):
+		return nil, status.Errorf(codes.FailedPrecondition, "%v", err)
+	case errors.Is(err, repository.ErrInsufficientBalance):
+		return nil, status.Errorf(codes.FailedPrecondition, "insufficient wallet balance to pay installment")
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return nil, status.Errorf(codes.NotFound, "installment not found")
+	case err != nil:
+		return nil, status.Errorf(codes.Internal, "failed to pay installment: %v", err)
+	}
+
+	s.recordBookingEvent(ctx, &models.BookingEvent{
+		BookingID: booking.BookingID,
+		ActorID:   clientUUID,
+		ActorRole: "client",
+		Action:    "installment_paid",
+		OldStatus: booking.Status,
+		NewStatus: booking.Status,
+		Reason:    fmt.Sprintf("%s of %s paid", installment.Label, installment.Amount),
+	})
+
+	installments, err = s.vendorRepo.GetBookingInstallments(ctx, req.BookingId)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to fetch installments: %v", err)
+	}
+	state, balanceDue, err := installmentPaymentState(installments, time.Now())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to total installments: %v", err)
+	}
+
+	return &pb.PayInstallmentResponse{
+		Message:      fmt.Sprintf("%s paid successfully", installment.Label),
+		PaymentState: state,
+		BalanceDue:   moneyToProto(balanceDue),
+	}, nil
+}
+
+// bookingInstallments works out a new booking's installments from its
+// service's payment schedule. collected is what the client actually paid when
+// booking: it covers the installments in order, and only what it covers is
+// marked paid. Bookings for services without a schedule have none and are
+// paid in full up front; bundle items get theirs when the bundle is created.
+func (s *VendorService) bookingInstallments(ctx context.Context, booking *adminModel.Booking, service *models.Service, collected money.Money, paidAt time.Time) ([]models.BookingInstallment, error) {
+	if service == nil {
+		return nil, nil
+	}
+
+	if _, err := s.vendorRepo.GetBundleItemByBookingID(ctx, booking.BookingID); err == nil {
+		return nil, nil
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	milestones, err := s.vendorRepo.GetPaymentMilestones(ctx, service.ID)
+	if err != nil || len(milestones) == 0 {
+		return nil, err
+	}
+
+	return buildInstallments(booking, milestones, collected, paidAt)
+}
+
+// buildInstallments splits the booking price across the schedule in whole
+// units. Rounding is absorbed by the last installment so the amounts always
+// add up to the price. collected is applied to the installments in order and
+// the ones it covers are paid at paidAt; an installment it covers only in part
+// is split into a paid part and the balance still due. Installments due at
+// booking time that were not collected are due straight away.
+func buildInstallments(booking *adminModel.Booking, milestones []models.PaymentMilestone, collected money.Money, paidAt time.Time) ([]models.BookingInstallment, error) {
+	price, err := bookingPrice(booking)
+	if err != nil {
+		return nil, err
+	}
+
+	installments := make([]models.BookingInstallment, 0, len(milestones))
+	allocated := money.Zero()
+	uncollected := collected
+
+	add := func(milestone models.PaymentMilestone, label string, amount money.Money, paid bool) {
+		installment := models.BookingInstallment{
+			BookingID: booking.BookingID,
+			Sequence:  len(installments) + 1,
+			Label:     label,
+			Amount:    amount,
+			ReleaseOn: milestone.ReleaseOn,
+			Status:    "pending",
+			DueAt:     booking.CreatedAt,
+		}
+		if milestone.DueDaysBefore >= 0 {
+			installment.DueAt = booking.Date.AddDate(0, 0, -milestone.DueDaysBefore)
+		}
+		if paid {
+			at := paidAt
+			installment.Status = "paid"
+			installment.PaidAt = &at
+		}
+		installments = append(installments, installment)
+	}
+
+	for i, milestone := range milestones {
+		amount, err := price.Percent(int64(milestone.Percent))
+		if err != nil {
+			return nil, err
+		}
+		amount = amount.TruncateMajor()
+		if i == len(milestones)-1 {
+			if amount, err = price.Sub(allocated); err != nil {
+				return nil, err
+			}
+		}
+		if allocated, err = allocated.Add(amount); err != nil {
+			return nil, err
+		}
+
+		short, err := uncollected.LessThan(amount)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case !short:
+			add(milestone, milestone.Label, amount, true)
+			if uncollected, err = uncollected.Sub(amount); err != nil {
+				return nil, err
+			}
+		case uncollected.IsPositive():
+			balance, err := amount.Sub(uncollected)
+			if err != nil {
+				return nil, err
+			}
+			add(milestone, milestone.Label+" (part paid)", uncollected, true)
+			add(milestone, milestone.Label+" (balance)", balance, false)
+			uncollected = money.Zero()
+		default:
+			add(milestone, milestone.Label, amount, false)
+		}
+	}
+
+	return installments, nil
+}
+
+// installmentPaymentState summarises a booking's installments as
+// paid_in_full, balance_due, overdue or refunded, with the amount still owed.
+func installmentPaymentState(installments []models.BookingInstallment, now time.Time) (string, money.Money, error) {
+	balanceDue := money.Zero()
+	overdue := false
+	refunded := len(installments) > 0
+
+	for _, installment := range installments {
+		switch installment.Status {
+		case "pending":
+			var err error
+			if balanceDue, err = balanceDue.Add(installment.Amount); err != nil {
+				return "", money.Money{}, err
+			}
+			if installment.DueAt.Before(now) {
+				overdue = true
+			}
+		case "paid":
+			refunded = false
+		}
+	}
+
+	switch {
+	case overdue:
+		return "overdue", balanceDue, nil
+	case balanceDue.IsPositive():
+		return "balance_due", balanceDue, nil
+	case refunded:
+		return "refunded", money.Zero(), nil
+	default:
+		return "paid_in_full", money.Zero(), nil
+	}
+}
+
+// heldFunds returns what the platform is holding for a booking: the paid
+// installments not yet released, or the full price when the booking has no
+// payment schedule.
+func (s *VendorService) heldFunds(ctx context.Context, booking *adminModel.Booking) (money.Money, []models.BookingInstallment, error) {
+	installments, err := s.vendorRepo.GetBookingInstallments(ctx, booking.BookingID.String())
+	if err != nil {
+		return money.Money{}, nil, err
+	}
+
+	if len(installments) == 0 {
+		price, err := bookingPrice(booking)
+		return price, nil, err
+	}
+
+	held := []models.BookingInstallment{}
+	amount := money.Zero()
+	for _, installment := range installments {
+		if installment.Status == "paid" && installment.ReleasedAt == nil {
+			held = append(held, installment)
+			if amount, err = amount.Add(installment.Amount); err != nil {
+				return money.Money{}, nil, err
+			}
+		}
+	}
+
+	return amount, held, nil
+}
+
+// heldOvertime returns the booking's paid overtime charges still in escrow and
+// their total.
+func (s *VendorService) heldOvertime(ctx context.Context, booking *adminModel.Booking) (money.Money, []models.OvertimeCharge, error) {
+	charges, err := s.vendorRepo.ListOvertimeCharges(ctx, booking.BookingID.String())
+	if err != nil {
+		return money.Money{}, nil, err
+	}
+
+	var held []models.OvertimeCharge
+	amount := money.Zero()
+	for _, charge := range charges {
+		if charge.Status == "paid" && charge.ReleasedAt == nil {
+			held = append(held, charge)
+			if amount, err = amount.Add(charge.Amount); err != nil {
+				return money.Money{}, nil, err
+			}
+		}
+	}
+
+	return amount, held, nil
+}
+
+func overtimeChargeIDs(charges []models.OvertimeCharge) []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(charges))
+	for _, charge := range charges {
+		ids = append(ids, charge.ID)
+	}
+	return ids
+}
+
+func installmentIDs(installments []models.BookingInstallment) []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(installments))
+	for _, installment := range installments {
+		ids = append(ids, installment.ID)
+	}
+	return ids
+}
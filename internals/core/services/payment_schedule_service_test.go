@@ -0,0 +1,140 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	adminModel "github.com/AthulKrishna2501/zyra-admin-service/internals/core/models"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/models"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/money"
+	"github.com/google/uuid"
+)
+
+func TestBuildInstallments(t *testing.T) {
+	createdAt := time.Date(2026, 1, 10, 9, 0, 0, 0, time.UTC)
+	eventDate := time.Date(2026, 3, 1, 18, 0, 0, 0, time.UTC)
+	paidAt := createdAt.Add(time.Minute)
+
+	depositAndBalance := []models.PaymentMilestone{
+		{Sequence: 1, Label: "Deposit", Percent: 30, DueDaysBefore: -1, ReleaseOn: "approval"},
+		{Sequence: 2, Label: "Balance", Percent: 70, DueDaysBefore: 7, ReleaseOn: "completion"},
+	}
+	thirds := []models.PaymentMilestone{
+		{Sequence: 1, Label: "First", Percent: 33, DueDaysBefore: -1, ReleaseOn: "approval"},
+		{Sequence: 2, Label: "Second", Percent: 33, DueDaysBefore: 30, ReleaseOn: "completion"},
+		{Sequence: 3, Label: "Third", Percent: 34, DueDaysBefore: 7, ReleaseOn: "completion"},
+	}
+
+	type want struct {
+		label  string
+		amount int64
+		status string
+		due    time.Time
+	}
+
+	tests := []struct {
+		name       string
+		price      int
+		milestones []models.PaymentMilestone
+		collected  int64
+		want       []want
+	}{
+		{
+			name:       "deposit collected",
+			price:      1000,
+			milestones: depositAndBalance,
+			collected:  300,
+			want: []want{
+				{"Deposit", 300, "paid", createdAt},
+				{"Balance", 700, "pending", eventDate.AddDate(0, 0, -7)},
+			},
+		},
+		{
+			name:       "full price collected",
+			price:      1000,
+			milestones: depositAndBalance,
+			collected:  1000,
+			want: []want{
+				{"Deposit", 300, "paid", createdAt},
+				{"Balance", 700, "paid", eventDate.AddDate(0, 0, -7)},
+			},
+		},
+		{
+			name:       "nothing collected",
+			price:      1000,
+			milestones: depositAndBalance,
+			collected:  0,
+			want: []want{
+				{"Deposit", 300, "pending", createdAt},
+				{"Balance", 700, "pending", eventDate.AddDate(0, 0, -7)},
+			},
+		},
+		{
+			name:       "collected part of an installment",
+			price:      1000,
+			milestones: depositAndBalance,
+			collected:  500,
+			want: []want{
+				{"Deposit", 300, "paid", createdAt},
+				{"Balance (part paid)", 200, "paid", eventDate.AddDate(0, 0, -7)},
+				{"Balance (balance)", 500, "pending", eventDate.AddDate(0, 0, -7)},
+			},
+		},
+		{
+			name:       "rounding goes to the last installment",
+			price:      1001,
+			milestones: thirds,
+			collected:  330,
+			want: []want{
+				{"First", 330, "paid", createdAt},
+				{"Second", 330, "pending", eventDate.AddDate(0, 0, -30)},
+				{"Third", 341, "pending", eventDate.AddDate(0, 0, -7)},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			booking := &adminModel.Booking{
+				BookingID: uuid.New(),
+				Price:     tt.price,
+				Date:      eventDate,
+				CreatedAt: createdAt,
+			}
+			collected, err := money.FromMajor(tt.collected)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := buildInstallments(booking, tt.milestones, collected, paidAt)
+			if err != nil {
+				t.Fatalf("buildInstallments: %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d installments, want %d: %+v", len(got), len(tt.want), got)
+			}
+
+			total := money.Zero()
+			for i, installment := range got {
+				w := tt.want[i]
+				wantAmount, _ := money.FromMajor(w.amount)
+				if installment.Label != w.label || installment.Amount != wantAmount || installment.Status != w.status || !installment.DueAt.Equal(w.due) {
+					t.Errorf("installment %d = %s %s %s due %s, want %s %s %s due %s",
+						i, installment.Label, installment.Amount, installment.Status, installment.DueAt,
+						w.label, wantAmount, w.status, w.due)
+				}
+				if installment.Sequence != i+1 || installment.BookingID != booking.BookingID {
+					t.Errorf("installment %d has sequence %d and booking %s", i, installment.Sequence, installment.BookingID)
+				}
+				if paid := installment.PaidAt != nil; paid != (w.status == "paid") {
+					t.Errorf("installment %d paid_at set = %v, want %v", i, paid, w.status == "paid")
+				}
+				total, _ = total.Add(installment.Amount)
+			}
+
+			if price, _ := money.FromMajor(int64(tt.price)); total != price {
+				t.Errorf("installments add up to %s, want %s", total, price)
+			}
+		})
+	}
+}
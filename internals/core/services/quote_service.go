@@ -0,0 +1,394 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	pb "github.com/AthulKrishna2501/proto-repo/vendor"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/models"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/money"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/repository"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultQuoteValidity = 7 * 24 * time.Hour
+	maxQuoteLineItems    = 50
+	defaultQuotePageSize = 20
+	maxQuotePageSize     = 100
+)
+
+var quoteStatuses = map[string]bool{
+	"requested": true,
+	"quoted":    true,
+	"accepted":  true,
+	"declined":  true,
+	"expired":   true,
+}
+
+func (s *VendorService) RequestQuote(ctx context.Context, req *pb.RequestQuoteRequest) (*pb.RequestQuoteResponse, error) {
+	clientUUID, err := uuid.Parse(req.ClientId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid client ID format: %v", err)
+	}
+
+	vendorUUID, err := uuid.Parse(req.VendorId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid vendor ID format: %v", err)
+	}
+
+	if err := s.ensureClientNotBlocked(ctx, vendorUUID, clientUUID); err != nil {
+		return nil, err
+	}
+
+	service, err := s.quotedService(ctx, vendorUUID, req.ServiceId)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSpace(req.Title) == "" || strings.TrimSpace(req.Details) == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "title and details are required")
+	}
+
+	if req.EventDate == nil || !req.EventDate.AsTime().After(time.Now()) {
+		return nil, status.Errorf(codes.InvalidArgument, "event_date must be in the future")
+	}
+
+	if req.GuestCount < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "guest_count cannot be negative")
+	}
+
+	quote := &models.Quote{
+		ClientID:   clientUUID,
+		VendorID:   vendorUUID,
+		ServiceID:  &service.ID,
+		Title:      strings.TrimSpace(req.Title),
+		EventDate:  req.EventDate.AsTime(),
+		Location:   strings.TrimSpace(req.Location),
+		GuestCount: int(req.GuestCount),
+		Details:    strings.TrimSpace(req.Details),
+		Status:     "requested",
+	}
+
+	if err := s.vendorRepo.CreateQuote(ctx, quote); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create quote request: %v", err)
+	}
+
+	return &pb.RequestQuoteResponse{
+		QuoteId: quote.ID.String(),
+		Message: "Quote requested successfully",
+	}, nil
+}
+
+func (s *VendorService) SubmitQuote(ctx context.Context, req *pb.SubmitQuoteRequest) (*pb.SubmitQuoteResponse, error) {
+	quote, err := s.getQuote(ctx, req.QuoteId)
+	if err != nil {
+		return nil, err
+	}
+
+	vendorUUID, _ := uuid.Parse(req.VendorId)
+	if quote.VendorID != vendorUUID {
+		return nil, status.Errorf(codes.PermissionDenied, "quote does not belong to the vendor")
+	}
+
+	if len(req.LineItems) == 0 || len(req.LineItems) > maxQuoteLineItems {
+		return nil, status.Errorf(codes.InvalidArgument, "a quote needs between 1 and %d line items", maxQuoteLineItems)
+	}
+
+	var items []models.QuoteLineItem
+	total := money.Zero()
+	for i, item := range req.LineItems {
+		if strings.TrimSpace(item.Description) == "" {
+			return nil, status.Errorf(codes.InvalidArgument, "line item description is required")
+		}
+
+		unitPrice, err := moneyFromProto(item.UnitPrice)
+		if err != nil {
+			return nil, err
+		}
+		if item.Quantity <= 0 || unitPrice.IsNegative() {
+			return nil, status.Errorf(codes.InvalidArgument, "line item quantity must be positive and unit price non-negative")
+		}
+
+		amount, err := unitPrice.Mul(int64(item.Quantity))
+		if err != nil {
+			return nil, moneyError(err)
+		}
+		if total, err = total.Add(amount); err != nil {
+			return nil, moneyError(err)
+		}
+		items = append(items, models.QuoteLineItem{
+			Position:    i + 1,
+			Description: strings.TrimSpace(item.Description),
+			Quantity:    int(item.Quantity),
+			UnitPrice:   unitPrice,
+			Amount:      amount,
+		})
+	}
+
+	if !total.IsPositive() {
+		return nil, status.Errorf(codes.InvalidArgument, "quote total must be positive")
+	}
+	// An accepted quote becomes a booking, whose price is kept in whole units.
+	if _, err := total.Major(); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "quote total must be a whole amount, got %s", total)
+	}
+
+	expiresAt := time.Now().Add(defaultQuoteValidity)
+	if req.ExpiresAt != nil {
+		expiresAt = req.ExpiresAt.AsTime()
+	}
+	if !expiresAt.After(time.Now()) || expiresAt.After(quote.EventDate) {
+		return nil, status.Errorf(codes.InvalidArgument, "expires_at must be in the future and before the event date")
+	}
+
+	err = s.vendorRepo.SubmitQuote(ctx, req.QuoteId, items, strings.TrimSpace(req.Note), expiresAt)
+	if errors.Is(err, repository.ErrQuoteNotOpen) {
+		return nil, status.Errorf(codes.FailedPrecondition, "%v", err)
+	} else if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to submit quote: %v", err)
+	}
+
+	return &pb.SubmitQuoteResponse{
+		TotalPrice: moneyToProto(total),
+		ExpiresAt:  timestamppb.New(expiresAt),
+		Message:    "Quote sent to client",
+	}, nil
+}
+
+func (s *VendorService) AcceptQuote(ctx context.Context, req *pb.AcceptQuoteRequest) (*pb.AcceptQuoteResponse, error) {
+	quote, err := s.getQuote(ctx, req.QuoteId)
+	if err != nil {
+		return nil, err
+	}
+
+	clientUUID, _ := uuid.Parse(req.ClientId)
+	if quote.ClientID != clientUUID {
+		return nil, status.Errorf(codes.PermissionDenied, "quote does not belong to the client")
+	}
+
+	if err := s.ensureClientNotBlocked(ctx, quote.VendorID, clientUUID); err != nil {
+		return nil, err
+	}
+
+	if quote.ServiceID == nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "quote is not linked to a service, request a new quote")
+	}
+	service, err := s.quotedService(ctx, quote.VendorID, quote.ServiceID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	end := quote.EventDate.Add(serviceDuration(service))
+	if err := s.checkBlockedTime(ctx, quote.VendorID.String(), quote.EventDate, end); err != nil {
+		return nil, err
+	}
+	if err := s.checkBookedTime(ctx, quote.VendorID.String(), quote.EventDate, end); err != nil {
+		return nil, err
+	}
+
+	event := &models.BookingEvent{
+		ActorID:   clientUUID,
+		ActorRole: "client",
+		Action:    "quote_accepted",
+		Reason:    fmt.Sprintf("Accepted quote %s for %s", quote.ID, quote.TotalPrice),
+	}
+
+	booking, err := s.vendorRepo.AcceptQuote(ctx, req.QuoteId, service, event)
+	switch {
+	case errors.Is(err, repository.ErrQuoteNotOpen):
+		return nil, status.Errorf(codes.FailedPrecondition, "quote has expired or is no longer open")
+	case errors.Is(err, repository.ErrInsufficientBalance):
+		return nil, status.Errorf(codes.FailedPrecondition, "insufficient wallet balance to accept quote")
+	case err != nil:
+		return nil, status.Errorf(codes.Internal, "failed to accept quote: %v", err)
+	}
+
+	return &pb.AcceptQuoteResponse{
+		BookingId: booking.BookingID.String(),
+		Message:   "Quote accepted and booking confirmed",
+	}, nil
+}
+
+func (s *VendorService) DeclineQuote(ctx context.Context, req *pb.DeclineQuoteRequest) (*pb.DeclineQuoteResponse, error) {
+	quote, err := s.getQuote(ctx, req.QuoteId)
+	if err != nil {
+		return nil, err
+	}
+
+	requesterUUID, _ := uuid.Parse(req.RequesterId)
+
+	var declinedBy string
+	switch requesterUUID {
+	case quote.ClientID:
+		declinedBy = "client"
+	case quote.VendorID:
+		declinedBy = "vendor"
+	default:
+		return nil, status.Errorf(codes.PermissionDenied, "quote does not belong to the requester")
+	}
+
+	err = s.vendorRepo.DeclineQuote(ctx, req.QuoteId, declinedBy, strings.TrimSpace(req.Reason))
+	if errors.Is(err, repository.ErrQuoteNotOpen) {
+		return nil, status.Errorf(codes.FailedPrecondition, "%v", err)
+	} else if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to decline quote: %v", err)
+	}
+
+	return &pb.DeclineQuoteResponse{
+		Message: "Quote declined",
+	}, nil
+}
+
+func (s *VendorService) GetQuote(ctx context.Context, req *pb.GetQuoteRequest) (*pb.GetQuoteResponse, error) {
+	quote, err := s.getQuote(ctx, req.QuoteId)
+	if err != nil {
+		return nil, err
+	}
+
+	requesterUUID, _ := uuid.Parse(req.RequesterId)
+	if quote.ClientID != requesterUUID && quote.VendorID != requesterUUID {
+		return nil, status.Errorf(codes.PermissionDenied, "quote does not belong to the requester")
+	}
+
+	return &pb.GetQuoteResponse{
+		Quote: quoteToProto(quote),
+	}, nil
+}
+
+func (s *VendorService) ListVendorQuotes(ctx context.Context, req *pb.ListVendorQuotesRequest) (*pb.ListVendorQuotesResponse, error) {
+	vendorUUID, err := uuid.Parse(req.VendorId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid vendor ID format: %v", err)
+	}
+
+	filter := repository.QuoteFilter{
+		VendorID: vendorUUID,
+		Search:   strings.TrimSpace(req.Search),
+		Limit:    defaultQuotePageSize,
+	}
+
+	for _, quoteStatus := range req.Statuses {
+		if quoteStatus == "open" {
+			filter.Statuses = append(filter.Statuses, "requested", "quoted")
+			continue
+		}
+		if !quoteStatuses[quoteStatus] {
+			return nil, status.Errorf(codes.InvalidArgument, "unknown quote status %q", quoteStatus)
+		}
+		filter.Statuses = append(filter.Statuses, quoteStatus)
+	}
+
+	if req.EventFrom != nil {
+		from := req.EventFrom.AsTime()
+		filter.From = &from
+	}
+	if req.EventTo != nil {
+		to := req.EventTo.AsTime()
+		filter.To = &to
+	}
+
+	if req.Limit > 0 && req.Limit <= maxQuotePageSize {
+		filter.Limit = int(req.Limit)
+	}
+	if req.Offset > 0 {
+		filter.Offset = int(req.Offset)
+	}
+
+	quotes, total, err := s.vendorRepo.ListVendorQuotes(ctx, filter)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to fetch quotes: %v", err)
+	}
+
+	var protoQuotes []*pb.Quote
+	now := time.Now()
+	for i := range quotes {
+		quotes[i].Status = quotes[i].EffectiveStatus(now)
+		protoQuotes = append(protoQuotes, quoteToProto(&quotes[i]))
+	}
+
+	return &pb.ListVendorQuotesResponse{
+		Quotes: protoQuotes,
+		Total:  total,
+	}, nil
+}
+
+func (s *VendorService) getQuote(ctx context.Context, quoteID string) (*models.Quote, error) {
+	if quoteID == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "quote_id is required")
+	}
+
+	quote, err := s.vendorRepo.GetQuote(ctx, quoteID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, status.Errorf(codes.NotFound, "quote not found")
+	} else if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to fetch quote: %v", err)
+	}
+
+	quote.Status = quote.EffectiveStatus(time.Now())
+	return quote, nil
+}
+
+// quotedService returns the vendor's service a quote is for.
+func (s *VendorService) quotedService(ctx context.Context, vendorID uuid.UUID, serviceID string) (*models.Service, error) {
+	if _, err := uuid.Parse(serviceID); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid service ID format: %v", err)
+	}
+
+	service, err := s.vendorRepo.GetServiceByID(ctx, serviceID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, status.Errorf(codes.NotFound, "service not found")
+	} else if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to fetch service: %v", err)
+	}
+	if service.VendorID != vendorID {
+		return nil, status.Errorf(codes.NotFound, "service not found")
+	}
+
+	return service, nil
+}
+
+func quoteToProto(quote *models.Quote) *pb.Quote {
+	protoQuote := &pb.Quote{
+		QuoteId:    quote.ID.String(),
+		ClientId:   quote.ClientID.String(),
+		VendorId:   quote.VendorID.String(),
+		Title:      quote.Title,
+		EventDate:  timestamppb.New(quote.EventDate),
+		Location:   quote.Location,
+		GuestCount: int32(quote.GuestCount),
+		Details:    quote.Details,
+		Status:     quote.Status,
+		TotalPrice: moneyToProto(quote.TotalPrice),
+		VendorNote: quote.VendorNote,
+		CreatedAt:  timestamppb.New(quote.CreatedAt),
+	}
+
+	if quote.ExpiresAt != nil {
+		protoQuote.ExpiresAt = timestamppb.New(*quote.ExpiresAt)
+	}
+	if quote.ServiceID != nil {
+		protoQuote.ServiceId = quote.ServiceID.String()
+	}
+	if quote.BookingID != nil {
+		protoQuote.BookingId = quote.BookingID.String()
+	}
+
+	for _, item := range quote.LineItems {
+		protoQuote.LineItems = append(protoQuote.LineItems, &pb.QuoteLineItem{
+			Description: item.Description,
+			Quantity:    int32(item.Quantity),
+			UnitPrice:   moneyToProto(item.UnitPrice),
+			Amount:      moneyToProto(item.Amount),
+		})
+	}
+
+	return protoQuote
+}
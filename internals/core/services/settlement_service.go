@@ -0,0 +1,242 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	pb "github.com/AthulKrishna2501/proto-repo/vendor"
+	adminModel "github.com/AthulKrishna2501/zyra-admin-service/internals/core/models"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/models"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/repository"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	defaultSettlementPeriodDays   = 7
+	defaultSettlementInterval     = 15 * time.Minute
+	defaultSettlementListingLimit = 20
+)
+
+// settlementEpoch is a Monday, so weekly settlement periods run Monday to
+// Monday, UTC.
+var settlementEpoch = time.Date(1970, time.January, 5, 0, 0, 0, 0, time.UTC)
+
+func (s *VendorService) settlementPeriodLength() time.Duration {
+	days := defaultSettlementPeriodDays
+	if s.cfg.SETTLEMENT_PERIOD_DAYS > 0 {
+		days = s.cfg.SETTLEMENT_PERIOD_DAYS
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// lastSettlementPeriod returns the most recent settlement period to have
+// ended by now.
+func lastSettlementPeriod(now time.Time, length time.Duration) (start, end time.Time) {
+	elapsed := now.Sub(settlementEpoch)
+	end = settlementEpoch.Add(elapsed - elapsed%length)
+	return end.Add(-length), end
+}
+
+// StartSettlementScheduler settles the last period's payouts on every tick.
+// Everything a settlement pays is claimed in its transaction, so a rerun pays
+// nothing twice and replicas can all run it.
+func (s *VendorService) StartSettlementScheduler(ctx context.Context) {
+	interval := defaultSettlementInterval
+	if s.cfg.PAYOUT_RELEASE_INTERVAL_MINS > 0 {
+		interval = time.Duration(s.cfg.PAYOUT_RELEASE_INTERVAL_MINS) * time.Minute
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.RunSettlements(ctx, time.Now()); err != nil {
+				s.log.Error("Settlement run failed", err)
+			}
+		}
+	}
+}
+
+// RunSettlements pays each vendor, in one payout, for every booking whose
+// dispute window closed before the end of the last settlement period and
+// every payout queued before then. A booking that cannot be read is left out,
+// with the reason recorded on its completion, and tried again on the next
+// run; so is a vendor whose bookings changed while they were being settled.
+// What becomes releasable after the period ends waits for the next period.
+func (s *VendorService) RunSettlements(ctx context.Context, now time.Time) error {
+	periodStart, periodEnd := lastSettlementPeriod(now, s.settlementPeriodLength())
+
+	completions, err := s.vendorRepo.ListSettleablePayouts(ctx, periodEnd)
+	if err != nil {
+		return fmt.Errorf("failed to list settleable payouts: %w", err)
+	}
+	queued, err := s.vendorRepo.ListQueuedPayouts(ctx, periodEnd)
+	if err != nil {
+		return fmt.Errorf("failed to list queued payouts: %w", err)
+	}
+
+	var vendorIDs []uuid.UUID
+	seen := map[uuid.UUID]bool{}
+	addVendor := func(vendorID uuid.UUID) {
+		if !seen[vendorID] {
+			seen[vendorID] = true
+			vendorIDs = append(vendorIDs, vendorID)
+		}
+	}
+
+	completed := map[uuid.UUID][]models.BookingCompletion{}
+	for _, completion := range completions {
+		addVendor(completion.VendorID)
+		completed[completion.VendorID] = append(completed[completion.VendorID], completion)
+	}
+	queuedByVendor := map[uuid.UUID][]models.QueuedPayout{}
+	for _, payout := range queued {
+		addVendor(payout.VendorID)
+		queuedByVendor[payout.VendorID] = append(queuedByVendor[payout.VendorID], payout)
+	}
+
+	for _, vendorID := range vendorIDs {
+		if err := s.settleVendor(ctx, vendorID, periodStart, periodEnd, completed[vendorID], queuedByVendor[vendorID]); err != nil {
+			s.log.Error("Failed to settle vendor", vendorID, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *VendorService) settleVendor(ctx context.Context, vendorID uuid.UUID, periodStart, periodEnd time.Time, completions []models.BookingCompletion, queued []models.QueuedPayout) error {
+	var items []repository.SettlementItem
+	bookingStatus := map[uuid.UUID]string{}
+	for _, completion := range completions {
+		item, booking, err := s.settlementItem(ctx, completion)
+		if err != nil {
+			s.log.Error("Leaving booking out of settlement", completion.BookingID, err)
+			if err := s.vendorRepo.RecordSettlementFailure(ctx, completion.BookingID, err.Error()); err != nil {
+				s.log.Error("Failed to record settlement failure", completion.BookingID, err)
+			}
+			continue
+		}
+
+		bookingStatus[booking.BookingID] = booking.Status
+		items = append(items, *item)
+	}
+	for i := range queued {
+		items = append(items, repository.SettlementItem{
+			BookingID:      queued[i].BookingID,
+			Description:    queued[i].Description,
+			Amount:         queued[i].Amount,
+			QueuedPayoutID: &queued[i].ID,
+		})
+	}
+	if len(items) == 0 {
+		return nil
+	}
+
+	settlement, err := s.vendorRepo.SettleVendor(ctx, vendorID, periodStart, periodEnd, items)
+	if errors.Is(err, repository.ErrSettlementConflict) {
+		s.log.Warn("Settlement bookings changed, retrying on the next run", vendorID)
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	for _, line := range settlement.Lines {
+		if _, ok := bookingStatus[line.BookingID]; !ok {
+			if booking, err := s.vendorRepo.GetBookingById(ctx, line.BookingID.String()); err == nil {
+				bookingStatus[line.BookingID] = booking.Status
+			}
+		}
+		s.recordBookingEvent(ctx, &models.BookingEvent{
+			BookingID: line.BookingID,
+			ActorRole: "system",
+			Action:    "payout_released",
+			OldStatus: bookingStatus[line.BookingID],
+			NewStatus: bookingStatus[line.BookingID],
+			Reason:    fmt.Sprintf("Settled %s for the period starting %s", line.Gross, periodStart.Format("02 Jan 2006")),
+		})
+	}
+
+	return nil
+}
+
+// settlementItem gathers what is held for a completed booking: its unreleased
+// installments, or its price without a schedule, plus paid overtime.
+func (s *VendorService) settlementItem(ctx context.Context, completion models.BookingCompletion) (*repository.SettlementItem, *adminModel.Booking, error) {
+	booking, err := s.vendorRepo.GetBookingById(ctx, completion.BookingID.String())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	amount, held, err := s.heldFunds(ctx, booking)
+	if err != nil {
+		return nil, nil, err
+	}
+	overtimeAmount, overtime, err := s.heldOvertime(ctx, booking)
+	if err != nil {
+		return nil, nil, err
+	}
+	if amount, err = amount.Add(overtimeAmount); err != nil {
+		return nil, nil, err
+	}
+
+	return &repository.SettlementItem{
+		BookingID:         booking.BookingID,
+		Description:       fmt.Sprintf("%s on %s", booking.Service, booking.Date.Format("02 Jan 2006")),
+		Amount:            amount,
+		InstallmentIDs:    installmentIDs(held),
+		OvertimeChargeIDs: overtimeChargeIDs(overtime),
+	}, booking, nil
+}
+
+func (s *VendorService) ListSettlements(ctx context.Context, req *pb.ListSettlementsRequest) (*pb.ListSettlementsResponse, error) {
+	vendorUUID, err := uuid.Parse(req.VendorId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid vendor ID format: %v", err)
+	}
+
+	limit := int(req.Limit)
+	if limit <= 0 || limit > 100 {
+		limit = defaultSettlementListingLimit
+	}
+
+	settlements, err := s.vendorRepo.ListSettlements(ctx, vendorUUID, limit)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to fetch settlements: %v", err)
+	}
+
+	var protoSettlements []*pb.Settlement
+	for _, settlement := range settlements {
+		protoSettlement := &pb.Settlement{
+			SettlementId: settlement.ID.String(),
+			PeriodStart:  timestamppb.New(settlement.PeriodStart),
+			PeriodEnd:    timestamppb.New(settlement.PeriodEnd),
+			Gross:        moneyToProto(settlement.Gross),
+			Fee:          moneyToProto(settlement.Fee),
+			Net:          moneyToProto(settlement.Net),
+			PaidAt:       timestamppb.New(settlement.PaidAt),
+		}
+		for _, line := range settlement.Lines {
+			protoSettlement.Lines = append(protoSettlement.Lines, &pb.SettlementLine{
+				BookingId:   line.BookingID.String(),
+				Description: line.Description,
+				Gross:       moneyToProto(line.Gross),
+				Fee:         moneyToProto(line.Fee),
+				Net:         moneyToProto(line.Net),
+			})
+		}
+		protoSettlements = append(protoSettlements, protoSettlement)
+	}
+
+	return &pb.ListSettlementsResponse{
+		Settlements: protoSettlements,
+	}, nil
+}
@@ -0,0 +1,305 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	adminModel "github.com/AthulKrishna2501/zyra-admin-service/internals/core/models"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/app/config"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/models"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/money"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/repository"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func TestLastSettlementPeriod(t *testing.T) {
+	week := 7 * 24 * time.Hour
+	monday := time.Date(2026, time.October, 12, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		now    time.Time
+		length time.Duration
+		start  time.Time
+	}{
+		{name: "mid week", now: monday.Add(3*24*time.Hour + 5*time.Hour), length: week, start: monday.Add(-week)},
+		{name: "on the boundary", now: monday, length: week, start: monday.Add(-week)},
+		{name: "just before the boundary", now: monday.Add(-time.Nanosecond), length: week, start: monday.Add(-2 * week)},
+		{name: "other time zone", now: monday.In(time.FixedZone("IST", 5*3600+1800)), length: week, start: monday.Add(-week)},
+		{name: "daily", now: monday.Add(30 * time.Hour), length: 24 * time.Hour, start: monday},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end := lastSettlementPeriod(tt.now, tt.length)
+			if !start.Equal(tt.start) || !end.Equal(tt.start.Add(tt.length)) {
+				t.Errorf("period = %s to %s, want %s to %s", start, end, tt.start, tt.start.Add(tt.length))
+			}
+			if end.After(tt.now) {
+				t.Errorf("period ends at %s, after %s", end, tt.now)
+			}
+		})
+	}
+}
+
+// settlementRepo is an in-memory stand-in for the parts of the repository the
+// settlement run uses. SettleVendor claims items the way the database does, so
+// reruns and conflicts behave as they would there.
+type settlementRepo struct {
+	repository.VendorRepository
+
+	bookings    map[uuid.UUID]*adminModel.Booking
+	completions []models.BookingCompletion
+	queued      []models.QueuedPayout
+	unreadable  map[uuid.UUID]bool
+	conflicts   int
+
+	settlements []models.Settlement
+	events      []models.BookingEvent
+}
+
+func (r *settlementRepo) ListSettleablePayouts(ctx context.Context, before time.Time) ([]models.BookingCompletion, error) {
+	var completions []models.BookingCompletion
+	for _, completion := range r.completions {
+		if completion.PayoutStatus == "awaiting_release" && completion.DisputeWindowEndsAt.Before(before) {
+			completions = append(completions, completion)
+		}
+	}
+	return completions, nil
+}
+
+func (r *settlementRepo) ListQueuedPayouts(ctx context.Context, before time.Time) ([]models.QueuedPayout, error) {
+	var payouts []models.QueuedPayout
+	for _, payout := range r.queued {
+		if payout.SettlementID == nil && payout.CreatedAt.Before(before) {
+			payouts = append(payouts, payout)
+		}
+	}
+	return payouts, nil
+}
+
+func (r *settlementRepo) GetBookingById(ctx context.Context, bookingID string) (*adminModel.Booking, error) {
+	id := uuid.MustParse(bookingID)
+	if r.unreadable[id] {
+		return nil, errors.New("connection reset")
+	}
+	booking, ok := r.bookings[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return booking, nil
+}
+
+func (r *settlementRepo) GetBookingInstallments(ctx context.Context, bookingID string) ([]models.BookingInstallment, error) {
+	return nil, nil
+}
+
+func (r *settlementRepo) ListOvertimeCharges(ctx context.Context, bookingID string) ([]models.OvertimeCharge, error) {
+	return nil, nil
+}
+
+func (r *settlementRepo) RecordSettlementFailure(ctx context.Context, bookingID uuid.UUID, reason string) error {
+	for i := range r.completions {
+		if r.completions[i].BookingID == bookingID {
+			r.completions[i].SettlementAttempts++
+			r.completions[i].SettlementError = reason
+		}
+	}
+	return nil
+}
+
+func (r *settlementRepo) SettleVendor(ctx context.Context, vendorID uuid.UUID, periodStart, periodEnd time.Time, items []repository.SettlementItem) (*models.Settlement, error) {
+	if r.conflicts > 0 {
+		r.conflicts--
+		return nil, repository.ErrSettlementConflict
+	}
+
+	settlement := models.Settlement{ID: uuid.New(), VendorID: vendorID, PeriodStart: periodStart, PeriodEnd: periodEnd}
+	for _, item := range items {
+		claimed := false
+		if item.QueuedPayoutID != nil {
+			for i := range r.queued {
+				if r.queued[i].ID == *item.QueuedPayoutID && r.queued[i].SettlementID == nil {
+					r.queued[i].SettlementID = &settlement.ID
+					claimed = true
+				}
+			}
+		} else {
+			for i := range r.completions {
+				if r.completions[i].BookingID == item.BookingID && r.completions[i].PayoutStatus == "awaiting_release" {
+					r.completions[i].PayoutStatus = "released"
+					r.completions[i].SettlementError = ""
+					claimed = true
+				}
+			}
+		}
+		if !claimed {
+			return nil, repository.ErrSettlementConflict
+		}
+		settlement.Lines = append(settlement.Lines, models.SettlementLine{BookingID: item.BookingID, Gross: item.Amount})
+	}
+
+	r.settlements = append(r.settlements, settlement)
+	return &settlement, nil
+}
+
+func (r *settlementRepo) CreateBookingEvent(ctx context.Context, event *models.BookingEvent) error {
+	r.events = append(r.events, *event)
+	return nil
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Info(string, ...interface{})  {}
+func (nopLogger) Error(string, ...interface{}) {}
+func (nopLogger) Debug(string, ...interface{}) {}
+func (nopLogger) Warn(string, ...interface{})  {}
+
+func TestRunSettlements(t *testing.T) {
+	now := time.Date(2026, time.October, 14, 9, 0, 0, 0, time.UTC)
+	periodEnd := time.Date(2026, time.October, 12, 0, 0, 0, 0, time.UTC)
+	vendorA, vendorB := uuid.New(), uuid.New()
+
+	type run struct {
+		settlements int
+		lines       int
+		pending     int
+	}
+
+	tests := []struct {
+		name       string
+		completed  int
+		late       int
+		queued     int
+		unreadable int
+		conflicts  int
+		runs       []run
+		attempts   int
+	}{
+		{
+			name:      "completions and queued payouts settle together",
+			completed: 2,
+			queued:    1,
+			runs:      []run{{settlements: 2, lines: 3}},
+		},
+		{
+			name:      "a rerun pays nothing more",
+			completed: 2,
+			queued:    1,
+			runs:      []run{{settlements: 2, lines: 3}, {settlements: 2, lines: 3}},
+		},
+		{
+			name:      "bookings released after the period wait",
+			completed: 1,
+			late:      1,
+			runs:      []run{{settlements: 1, lines: 1, pending: 1}},
+		},
+		{
+			name:       "unreadable booking is recorded and retried",
+			completed:  2,
+			unreadable: 1,
+			runs:       []run{{settlements: 1, lines: 1, pending: 1}, {settlements: 2, lines: 2}},
+			attempts:   1,
+		},
+		{
+			name:      "conflict pays nothing and is retried",
+			completed: 1,
+			queued:    1,
+			conflicts: 1,
+			runs:      []run{{pending: 2}, {settlements: 1, lines: 2}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &settlementRepo{
+				bookings:   map[uuid.UUID]*adminModel.Booking{},
+				unreadable: map[uuid.UUID]bool{},
+				conflicts:  tt.conflicts,
+			}
+			vendors := []uuid.UUID{vendorA, vendorB}
+			addBooking := func(i int, windowEnds time.Time) uuid.UUID {
+				booking := &adminModel.Booking{BookingID: uuid.New(), VendorID: vendors[i%2], Service: "Photography", Price: 1000, Status: "completed"}
+				repo.bookings[booking.BookingID] = booking
+				repo.completions = append(repo.completions, models.BookingCompletion{
+					BookingID:           booking.BookingID,
+					VendorID:            booking.VendorID,
+					DisputeWindowEndsAt: windowEnds,
+					PayoutStatus:        "awaiting_release",
+				})
+				return booking.BookingID
+			}
+			for i := 0; i < tt.completed; i++ {
+				bookingID := addBooking(i, periodEnd.Add(-time.Hour))
+				if i < tt.unreadable {
+					repo.unreadable[bookingID] = true
+				}
+			}
+			for i := 0; i < tt.late; i++ {
+				addBooking(i, periodEnd.Add(time.Hour))
+			}
+			for i := 0; i < tt.queued; i++ {
+				repo.queued = append(repo.queued, models.QueuedPayout{
+					ID:        uuid.New(),
+					VendorID:  vendorA,
+					BookingID: uuid.New(),
+					Kind:      models.QueuedPayoutApproval,
+					Amount:    money.New(50000),
+					CreatedAt: periodEnd.Add(-24 * time.Hour),
+				})
+			}
+
+			svc := &VendorService{vendorRepo: repo, log: nopLogger{}, cfg: config.Config{}}
+			for i, want := range tt.runs {
+				if err := svc.RunSettlements(context.Background(), now); err != nil {
+					t.Fatalf("run %d: %v", i+1, err)
+				}
+				// The unreadable booking can be read again on later runs.
+				repo.unreadable = map[uuid.UUID]bool{}
+
+				lines := 0
+				for _, settlement := range repo.settlements {
+					lines += len(settlement.Lines)
+					if !settlement.PeriodEnd.Equal(periodEnd) {
+						t.Errorf("run %d: settlement period ends %s, want %s", i+1, settlement.PeriodEnd, periodEnd)
+					}
+				}
+				if len(repo.settlements) != want.settlements || lines != want.lines {
+					t.Errorf("run %d: %d settlements with %d lines, want %d with %d", i+1, len(repo.settlements), lines, want.settlements, want.lines)
+				}
+				if len(repo.events) != lines {
+					t.Errorf("run %d: %d payout events, want %d", i+1, len(repo.events), lines)
+				}
+
+				pending := 0
+				for _, completion := range repo.completions {
+					if completion.PayoutStatus == "awaiting_release" {
+						pending++
+					}
+				}
+				for _, payout := range repo.queued {
+					if payout.SettlementID == nil {
+						pending++
+					}
+				}
+				if pending != want.pending {
+					t.Errorf("run %d: %d items left unpaid, want %d", i+1, pending, want.pending)
+				}
+			}
+
+			attempts := 0
+			for _, completion := range repo.completions {
+				attempts += completion.SettlementAttempts
+				if completion.PayoutStatus == "released" && completion.SettlementError != "" {
+					t.Errorf("settled booking kept its error %q", completion.SettlementError)
+				}
+			}
+			if attempts != tt.attempts {
+				t.Errorf("recorded %d failed attempts, want %d", attempts, tt.attempts)
+			}
+		})
+	}
+}
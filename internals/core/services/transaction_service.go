@@ -0,0 +1,240 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"time"
+
+	pb "github.com/AthulKrishna2501/proto-repo/vendor"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/models"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/models/responses"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/money"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/repository"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	// transactionExportBatch is how many transactions are read before their
+	// commissions are looked up and the rows written out.
+	transactionExportBatch = 500
+	// transactionExportChunkSize is roughly how much CSV goes in each
+	// streamed message.
+	transactionExportChunkSize = 32 * 1024
+)
+
+var transactionCSVHeader = []string{
+	"transaction_id", "date", "type", "status", "currency",
+	"amount", "gross", "fee", "net", "running_balance",
+}
+
+// vendorTransactionQuery is what listing and exporting transactions have in
+// common.
+type vendorTransactionQuery interface {
+	GetVendorId() string
+	GetFrom() *timestamppb.Timestamp
+	GetTo() *timestamppb.Timestamp
+	GetPurpose() string
+	GetStatus() string
+	GetMinAmount() *pb.Money
+	GetMaxAmount() *pb.Money
+}
+
+// vendorTransactionFilter reads the filter from req. Transactions are stored
+// in whole units, so the amount bounds are rounded inwards to whole units.
+func vendorTransactionFilter(req vendorTransactionQuery) (repository.VendorTransactionFilter, error) {
+	vendorUUID, err := uuid.Parse(req.GetVendorId())
+	if err != nil {
+		return repository.VendorTransactionFilter{}, status.Errorf(codes.InvalidArgument, "invalid vendor ID format: %v", err)
+	}
+
+	filter := repository.VendorTransactionFilter{
+		VendorID: vendorUUID,
+		Purpose:  req.GetPurpose(),
+		Status:   req.GetStatus(),
+	}
+
+	if req.GetFrom() != nil {
+		from := req.GetFrom().AsTime()
+		filter.From = &from
+	}
+	if req.GetTo() != nil {
+		to := req.GetTo().AsTime()
+		filter.To = &to
+	}
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return repository.VendorTransactionFilter{}, status.Errorf(codes.InvalidArgument, "from must be before to")
+	}
+
+	if req.GetMinAmount() != nil {
+		minAmount, err := moneyFromProto(req.GetMinAmount())
+		if err != nil {
+			return repository.VendorTransactionFilter{}, err
+		}
+		if minAmount.IsNegative() {
+			return repository.VendorTransactionFilter{}, status.Errorf(codes.InvalidArgument, "min_amount cannot be negative")
+		}
+		major := (minAmount.Amount + money.MinorPerMajor - 1) / money.MinorPerMajor
+		filter.MinAmount = &major
+	}
+	if req.GetMaxAmount() != nil {
+		maxAmount, err := moneyFromProto(req.GetMaxAmount())
+		if err != nil {
+			return repository.VendorTransactionFilter{}, err
+		}
+		if maxAmount.IsNegative() {
+			return repository.VendorTransactionFilter{}, status.Errorf(codes.InvalidArgument, "max_amount cannot be negative")
+		}
+		major := maxAmount.Amount / money.MinorPerMajor
+		filter.MaxAmount = &major
+	}
+
+	return filter, nil
+}
+
+// payoutCommissionsFor maps each of the transactions that was a payout to the
+// commission split behind it.
+func (s *VendorService) payoutCommissionsFor(ctx context.Context, lines []responses.VendorTransactionLine) (map[uuid.UUID]models.PayoutCommission, error) {
+	transactionIDs := make([]uuid.UUID, 0, len(lines))
+	for _, line := range lines {
+		transactionIDs = append(transactionIDs, line.TransactionID)
+	}
+
+	commissions, err := s.vendorRepo.GetPayoutCommissions(ctx, transactionIDs)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to retrieve payout commissions: %v", err)
+	}
+
+	commissionByTransaction := make(map[uuid.UUID]models.PayoutCommission, len(commissions))
+	for _, commission := range commissions {
+		commissionByTransaction[commission.TransactionID] = commission
+	}
+	return commissionByTransaction, nil
+}
+
+// vendorTransactionAmounts converts a stored transaction's amounts. Payouts
+// show the commission taken; everything else was moved without a fee.
+func vendorTransactionAmounts(line *responses.VendorTransactionLine, commissions map[uuid.UUID]models.PayoutCommission) (amount, gross, fee, net money.Money, err error) {
+	if amount, err = money.FromMajor(line.AmountPaid); err != nil {
+		return
+	}
+
+	gross, fee, net = amount, money.Zero(), amount
+	if commission, ok := commissions[line.TransactionID]; ok {
+		gross, fee, net = commission.Gross, commission.Fee, commission.Net
+	}
+	return
+}
+
+func vendorTransactionToProto(line *responses.VendorTransactionLine, commissions map[uuid.UUID]models.PayoutCommission) (*pb.VendorTransaction, error) {
+	amount, gross, fee, net, err := vendorTransactionAmounts(line, commissions)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "invalid stored amount: %v", err)
+	}
+
+	return &pb.VendorTransaction{
+		TransactionId:  line.TransactionID.String(),
+		Date:           line.DateOfPayment.UTC().Format(time.RFC3339),
+		Type:           line.Purpose,
+		Amount:         moneyToProto(amount),
+		Status:         line.PaymentStatus,
+		Gross:          moneyToProto(gross),
+		Fee:            moneyToProto(fee),
+		Net:            moneyToProto(net),
+		RunningBalance: moneyToProto(line.RunningBalance),
+	}, nil
+}
+
+// ExportVendorTransactions streams the vendor's transactions, filtered as in
+// GetVendorTransactions, as CSV. Transactions are read and sent a batch at a
+// time so large histories are never held in memory.
+func (s *VendorService) ExportVendorTransactions(req *pb.ExportVendorTransactionsRequest, stream pb.VendorSevice_ExportVendorTransactionsServer) error {
+	ctx := stream.Context()
+
+	filter, err := vendorTransactionFilter(req)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+
+	send := func(force bool) error {
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			return status.Errorf(codes.Internal, "failed to write CSV: %v", err)
+		}
+		if buf.Len() == 0 || (!force && buf.Len() < transactionExportChunkSize) {
+			return nil
+		}
+		data := append([]byte(nil), buf.Bytes()...)
+		buf.Reset()
+		return stream.Send(&pb.ExportVendorTransactionsChunk{Data: data})
+	}
+
+	batch := make([]responses.VendorTransactionLine, 0, transactionExportBatch)
+	writeBatch := func() error {
+		commissions, err := s.payoutCommissionsFor(ctx, batch)
+		if err != nil {
+			return err
+		}
+
+		for i := range batch {
+			line := &batch[i]
+			amount, gross, fee, net, err := vendorTransactionAmounts(line, commissions)
+			if err != nil {
+				return status.Errorf(codes.Internal, "invalid stored amount: %v", err)
+			}
+
+			if err := writer.Write([]string{
+				line.TransactionID.String(),
+				line.DateOfPayment.UTC().Format(time.RFC3339),
+				line.Purpose,
+				line.PaymentStatus,
+				money.DefaultCurrency,
+				amount.Decimal(),
+				gross.Decimal(),
+				fee.Decimal(),
+				net.Decimal(),
+				line.RunningBalance.Decimal(),
+			}); err != nil {
+				return status.Errorf(codes.Internal, "failed to write CSV: %v", err)
+			}
+			if err := send(false); err != nil {
+				return err
+			}
+		}
+
+		batch = batch[:0]
+		return nil
+	}
+
+	if err := writer.Write(transactionCSVHeader); err != nil {
+		return status.Errorf(codes.Internal, "failed to write CSV: %v", err)
+	}
+
+	err = s.vendorRepo.EachVendorTransaction(ctx, filter, func(line *responses.VendorTransactionLine) error {
+		batch = append(batch, *line)
+		if len(batch) < transactionExportBatch {
+			return nil
+		}
+		return writeBatch()
+	})
+	if err != nil {
+		if _, ok := status.FromError(err); ok {
+			return err
+		}
+		return status.Errorf(codes.Internal, "failed to export transactions: %v", err)
+	}
+
+	if len(batch) > 0 {
+		if err := writeBatch(); err != nil {
+			return err
+		}
+	}
+
+	return send(true)
+}
@@ -0,0 +1,149 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	pb "github.com/AthulKrishna2501/proto-repo/vendor"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/models"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/models/responses"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/money"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestVendorTransactionFilter(t *testing.T) {
+	vendorID := uuid.New()
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+	major := func(amount int64) *int64 { return &amount }
+
+	tests := []struct {
+		name     string
+		req      *pb.ViewVendorTransactionsRequest
+		wantCode codes.Code
+		wantMin  *int64
+		wantMax  *int64
+	}{
+		{
+			name:     "invalid vendor ID",
+			req:      &pb.ViewVendorTransactionsRequest{VendorId: "vendor"},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name: "no filters",
+			req:  &pb.ViewVendorTransactionsRequest{VendorId: vendorID.String()},
+		},
+		{
+			name: "date range",
+			req:  &pb.ViewVendorTransactionsRequest{VendorId: vendorID.String(), From: timestamppb.New(from), To: timestamppb.New(to)},
+		},
+		{
+			name:     "empty date range",
+			req:      &pb.ViewVendorTransactionsRequest{VendorId: vendorID.String(), From: timestamppb.New(from), To: timestamppb.New(from)},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:    "whole amounts",
+			req:     &pb.ViewVendorTransactionsRequest{VendorId: vendorID.String(), MinAmount: &pb.Money{Amount: 10000}, MaxAmount: &pb.Money{Amount: 50000}},
+			wantMin: major(100),
+			wantMax: major(500),
+		},
+		{
+			name:    "fractional amounts round inwards",
+			req:     &pb.ViewVendorTransactionsRequest{VendorId: vendorID.String(), MinAmount: &pb.Money{Amount: 10001}, MaxAmount: &pb.Money{Amount: 50099}},
+			wantMin: major(101),
+			wantMax: major(500),
+		},
+		{
+			name:     "negative amount",
+			req:      &pb.ViewVendorTransactionsRequest{VendorId: vendorID.String(), MinAmount: &pb.Money{Amount: -100}},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "other currency",
+			req:      &pb.ViewVendorTransactionsRequest{VendorId: vendorID.String(), MaxAmount: &pb.Money{Amount: 100, Currency: "USD"}},
+			wantCode: codes.InvalidArgument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := vendorTransactionFilter(tt.req)
+			if got := status.Code(err); got != tt.wantCode {
+				t.Fatalf("error code = %v, want %v (%v)", got, tt.wantCode, err)
+			}
+			if err != nil {
+				return
+			}
+
+			if filter.VendorID != vendorID {
+				t.Errorf("vendor = %s, want %s", filter.VendorID, vendorID)
+			}
+			if (filter.From != nil) != (tt.req.From != nil) || (filter.To != nil) != (tt.req.To != nil) {
+				t.Errorf("date range = %v to %v, want %v to %v", filter.From, filter.To, tt.req.From, tt.req.To)
+			}
+			if !equalAmount(filter.MinAmount, tt.wantMin) || !equalAmount(filter.MaxAmount, tt.wantMax) {
+				t.Errorf("amounts = %v to %v, want %v to %v", deref(filter.MinAmount), deref(filter.MaxAmount), deref(tt.wantMin), deref(tt.wantMax))
+			}
+		})
+	}
+}
+
+func equalAmount(got, want *int64) bool {
+	if got == nil || want == nil {
+		return got == want
+	}
+	return *got == *want
+}
+
+func deref(amount *int64) interface{} {
+	if amount == nil {
+		return nil
+	}
+	return *amount
+}
+
+func TestVendorTransactionAmounts(t *testing.T) {
+	payoutID := uuid.New()
+	commissions := map[uuid.UUID]models.PayoutCommission{
+		payoutID: {TransactionID: payoutID, Gross: money.New(100000), Fee: money.New(10000), Net: money.New(90000)},
+	}
+
+	tests := []struct {
+		name            string
+		line            responses.VendorTransactionLine
+		gross, fee, net int64
+	}{
+		{
+			name:  "payout with commission",
+			line:  responses.VendorTransactionLine{TransactionID: payoutID, AmountPaid: 900},
+			gross: 100000,
+			fee:   10000,
+			net:   90000,
+		},
+		{
+			name:  "transaction without commission",
+			line:  responses.VendorTransactionLine{TransactionID: uuid.New(), AmountPaid: 500},
+			gross: 50000,
+			net:   50000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			amount, gross, fee, net, err := vendorTransactionAmounts(&tt.line, commissions)
+			if err != nil {
+				t.Fatalf("vendorTransactionAmounts: %v", err)
+			}
+			if want, _ := money.FromMajor(tt.line.AmountPaid); amount != want {
+				t.Errorf("amount = %s, want %s", amount, want)
+			}
+			if gross != money.New(tt.gross) || fee != money.New(tt.fee) || net != money.New(tt.net) {
+				t.Errorf("gross, fee, net = %s, %s, %s; want %s, %s, %s", gross, fee, net, money.New(tt.gross), money.New(tt.fee), money.New(tt.net))
+			}
+		})
+	}
+}
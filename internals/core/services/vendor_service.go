@@ -5,14 +5,16 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	pb "github.com/AthulKrishna2501/proto-repo/vendor"
 	adminModel "github.com/AthulKrishna2501/zyra-admin-service/internals/core/models"
-	clientModel "github.com/AthulKrishna2501/zyra-client-service/internals/core/models"
 
 	"github.com/AthulKrishna2501/zyra-vendor-service/internals/app/config"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/app/payout"
 	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/models"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/money"
 	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/repository"
 	"github.com/AthulKrishna2501/zyra-vendor-service/internals/logger"
 	"github.com/AthulKrishna2501/zyra-vendor-service/utils"
@@ -22,6 +24,7 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
+	"gorm.io/gorm"
 )
 
 type VendorService struct {
@@ -30,10 +33,13 @@ type VendorService struct {
 	redisClient *redis.Client
 	log         logger.Logger
 	cfg         config.Config
+	payouts     payout.Provider
 }
 
-func NewVendorService(vendorRepo repository.VendorRepository, logger logger.Logger, cfg config.Config) *VendorService {
-	return &VendorService{vendorRepo: vendorRepo, redisClient: config.RedisClient, log: logger, cfg: cfg}
+// NewVendorService builds the service. payouts may be nil, in which case
+// withdrawals cannot be approved.
+func NewVendorService(vendorRepo repository.VendorRepository, logger logger.Logger, cfg config.Config, payouts payout.Provider) *VendorService {
+	return &VendorService{vendorRepo: vendorRepo, redisClient: config.RedisClient, log: logger, cfg: cfg, payouts: payouts}
 }
 
 func (s *VendorService) RequestCategory(ctx context.Context, req *pb.RequestCategoryRequest) (*pb.RequestCategoryResponse, error) {
@@ -117,15 +123,25 @@ func (s *VendorService) VendorProfile(ctx context.Context, req *pb.VendorProfile
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
+	business, err := s.vendorRepo.GetVendorBusinessProfile(ctx, vendorUUID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to fetch business profile: %v", err)
+	}
+
 	return &pb.VendorProfileResponse{
-		UserId:        vendor.UserID,
-		FirstName:     vendor.FirstName,
-		LastName:      vendor.LastName,
-		Email:         vendor.Email,
-		PhoneNumber:   vendor.PhoneNumber,
-		ProfileImage:  vendor.ProfileImage,
-		RequestStatus: vendor.RequestStatus,
-		Category:      vendor.CategoryName,
+		UserId:             vendor.UserID,
+		FirstName:          vendor.FirstName,
+		LastName:           vendor.LastName,
+		Email:              vendor.Email,
+		PhoneNumber:        vendor.PhoneNumber,
+		ProfileImage:       vendor.ProfileImage,
+		RequestStatus:      vendor.RequestStatus,
+		Category:           vendor.CategoryName,
+		BusinessName:       business.BusinessName,
+		BusinessAddress:    business.BusinessAddress,
+		TaxId:              business.TaxID,
+		TaxName:            business.TaxName,
+		TaxRateBasisPoints: int32(business.TaxRateBasisPoints),
 	}, nil
 }
 
@@ -151,18 +167,54 @@ func (s *VendorService) UpdateProfile(ctx context.Context, req *pb.UpdateVendorP
 		updateData["bio"] = req.Bio
 	}
 
-	if len(updateData) == 0 {
+	businessUpdated := req.BusinessName != nil || req.BusinessAddress != nil || req.TaxId != nil ||
+		req.TaxName != nil || req.TaxRateBasisPoints != nil
+
+	if len(updateData) == 0 && !businessUpdated {
 		return nil, status.Error(codes.InvalidArgument, "No fields provided for update")
 	}
 
+	if req.TaxRateBasisPoints != nil && (*req.TaxRateBasisPoints < 0 || *req.TaxRateBasisPoints > maxTaxRateBasisPoints) {
+		return nil, status.Errorf(codes.InvalidArgument, "tax_rate_basis_points must be between 0 and %d", maxTaxRateBasisPoints)
+	}
+
 	vendorUUID, err := uuid.Parse(req.VendorId)
 	if err != nil {
 		return nil, fmt.Errorf("invalid vendor ID format: %v", err)
 	}
 
-	err = s.vendorRepo.UpdateVendorProfile(ctx, vendorUUID, updateData)
-	if err != nil {
-		return nil, status.Error(codes.Internal, err.Error())
+	if len(updateData) > 0 {
+		err = s.vendorRepo.UpdateVendorProfile(ctx, vendorUUID, updateData)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+	}
+
+	if businessUpdated {
+		business, err := s.vendorRepo.GetVendorBusinessProfile(ctx, vendorUUID)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to fetch business profile: %v", err)
+		}
+
+		if req.BusinessName != nil {
+			business.BusinessName = strings.TrimSpace(*req.BusinessName)
+		}
+		if req.BusinessAddress != nil {
+			business.BusinessAddress = strings.TrimSpace(*req.BusinessAddress)
+		}
+		if req.TaxId != nil {
+			business.TaxID = strings.TrimSpace(*req.TaxId)
+		}
+		if req.TaxName != nil {
+			business.TaxName = strings.TrimSpace(*req.TaxName)
+		}
+		if req.TaxRateBasisPoints != nil {
+			business.TaxRateBasisPoints = int(*req.TaxRateBasisPoints)
+		}
+
+		if err := s.vendorRepo.SaveVendorBusinessProfile(ctx, business); err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to save business profile: %v", err)
+		}
 	}
 
 	return &pb.UpdateVendorProfileResponse{
@@ -194,6 +246,22 @@ func (s *VendorService) CreateService(ctx context.Context, req *pb.CreateService
 		}, nil
 	}
 
+	if req.MinNoticeHours < 0 || req.MaxAdvanceDays < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "notice and advance window cannot be negative")
+	}
+
+	servicePrice, err := wholeMoneyFromProto(req.ServicePrice, "service_price")
+	if err != nil {
+		return nil, err
+	}
+	additionalHourPrice, err := wholeMoneyFromProto(req.AdditionalHourPrice, "additional_hour_price")
+	if err != nil {
+		return nil, err
+	}
+	if servicePrice.IsNegative() || additionalHourPrice.IsNegative() {
+		return nil, status.Errorf(codes.InvalidArgument, "prices cannot be negative")
+	}
+
 	var availableDate time.Time
 	if len(req.AvailableDates) > 0 && req.AvailableDates[0] != nil {
 		availableDate = req.AvailableDates[0].AsTime()
@@ -209,8 +277,10 @@ func (s *VendorService) CreateService(ctx context.Context, req *pb.CreateService
 		CancellationPolicy:  req.CancellationPolicy,
 		TermsAndConditions:  req.TermsAndConditions,
 		ServiceDuration:     int(req.ServiceDuration),
-		ServicePrice:        int(req.ServicePrice),
-		AdditionalHourPrice: int(req.AdditionalHourPrice),
+		ServicePrice:        servicePrice,
+		AdditionalHourPrice: additionalHourPrice,
+		MinNoticeHours:      int(req.MinNoticeHours),
+		MaxAdvanceDays:      int(req.MaxAdvanceDays),
 	}
 
 	if err := s.vendorRepo.CreateService(&service); err != nil {
@@ -230,6 +300,22 @@ func (s *VendorService) UpdateService(ctx context.Context, req *pb.UpdateService
 		return nil, fmt.Errorf("invalid service ID format: %v", err)
 	}
 
+	if req.MinNoticeHours < 0 || req.MaxAdvanceDays < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "notice and advance window cannot be negative")
+	}
+
+	servicePrice, err := wholeMoneyFromProto(req.ServicePrice, "service_price")
+	if err != nil {
+		return nil, err
+	}
+	additionalHourPrice, err := wholeMoneyFromProto(req.AdditionalHourPrice, "additional_hour_price")
+	if err != nil {
+		return nil, err
+	}
+	if servicePrice.IsNegative() || additionalHourPrice.IsNegative() {
+		return nil, status.Errorf(codes.InvalidArgument, "prices cannot be negative")
+	}
+
 	var availableDate time.Time
 	if len(req.AvailableDates) > 0 && req.AvailableDates[0] != nil {
 		availableDate = req.AvailableDates[0].AsTime()
@@ -244,8 +330,10 @@ func (s *VendorService) UpdateService(ctx context.Context, req *pb.UpdateService
 		CancellationPolicy:  req.CancellationPolicy,
 		TermsAndConditions:  req.TermsAndConditions,
 		ServiceDuration:     int(req.ServiceDuration),
-		ServicePrice:        int(req.ServicePrice),
-		AdditionalHourPrice: int(*req.AdditionalHourPrice),
+		ServicePrice:        servicePrice,
+		AdditionalHourPrice: additionalHourPrice,
+		MinNoticeHours:      int(req.MinNoticeHours),
+		MaxAdvanceDays:      int(req.MaxAdvanceDays),
 	}
 
 	err = s.vendorRepo.UpdateService(serviceUUID, updatedService)
@@ -298,9 +386,13 @@ func (s *VendorService) GetVendorDashboard(ctx context.Context, req *pb.GetVendo
 
 	var revenues []*pb.MonthRevenue
 	for _, res := range monthlyRevenue {
+		revenue, err := majorToProto(res.Revenue)
+		if err != nil {
+			return nil, err
+		}
 		revenues = append(revenues, &pb.MonthRevenue{
 			Month:   res.Month,
-			Revenue: res.Revenue,
+			Revenue: revenue,
 		})
 	}
 
@@ -309,9 +401,17 @@ func (s *VendorService) GetVendorDashboard(ctx context.Context, req *pb.GetVendo
 		return nil, status.Errorf(codes.Internal, "failed to fetch top services: %v", err)
 	}
 
-	averageRevenuePerBooking := float64(0)
+	totalRevenue, err := money.FromMajor(dash.TotalRevenue)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "invalid total revenue: %v", err)
+	}
+
+	averageRevenuePerBooking := money.Zero()
 	if dash.TotalBookings > 0 {
-		averageRevenuePerBooking = float64(dash.TotalRevenue) / float64(dash.TotalBookings)
+		averageRevenuePerBooking, err = totalRevenue.MulDiv(1, int64(dash.TotalBookings))
+		if err != nil {
+			return nil, moneyError(err)
+		}
 	}
 
 	bookingGrowthRate := utils.CalculateGrowthRate(int32(dash.CurrentMonthBookings), int32(dash.PreviousMonthBookings))
@@ -328,10 +428,10 @@ func (s *VendorService) GetVendorDashboard(ctx context.Context, req *pb.GetVendo
 	resp := &pb.GetVendorDashboardResponse{
 		TotalClientsServed:       dash.TotalClientsServed,
 		TotalBookings:            dash.TotalBookings,
-		TotalRevenue:             dash.TotalRevenue,
+		TotalRevenue:             moneyToProto(totalRevenue),
 		MonthlyRevenueTrend:      revenues,
 		TopServices:              topServicesResp,
-		AverageRevenuePerBooking: averageRevenuePerBooking,
+		AverageRevenuePerBooking: moneyToProto(averageRevenuePerBooking),
 		BookingGrowthRate:        bookingGrowthRate,
 		ClientGrowthRate:         clientGrowthRate,
 		PendingPayments:          dash.PendingPayments,
@@ -371,8 +471,10 @@ func (s *VendorService) GetVendorServices(ctx context.Context, req *pb.GetVendor
 			CancellationPolicy:  service.CancellationPolicy,
 			TermsAndConditions:  service.TermsAndConditions,
 			ServiceDuration:     int64(service.ServiceDuration),
-			ServicePrice:        int64(service.ServicePrice),
-			AdditionalHourPrice: int64(service.AdditionalHourPrice),
+			ServicePrice:        moneyToProto(service.ServicePrice),
+			AdditionalHourPrice: moneyToProto(service.AdditionalHourPrice),
+			MinNoticeHours:      int64(service.MinNoticeHours),
+			MaxAdvanceDays:      int64(service.MaxAdvanceDays),
 		})
 	}
 
@@ -393,12 +495,16 @@ func (s *VendorService) GetBookingRequests(ctx context.Context, req *pb.GetBooki
 
 	var bookingList []*pb.BookingRequest
 	for _, booking := range vendorBookings {
+		price, err := majorToProto(int64(booking.Price))
+		if err != nil {
+			return nil, err
+		}
 		bookingList = append(bookingList, &pb.BookingRequest{
 			BookingId:  booking.BookingID,
 			ClientName: booking.ClientName,
 			Service:    booking.Service,
 			Date:       timestamppb.New(booking.Date),
-			Price:      int32(booking.Price),
+			Price:      price,
 			Status:     booking.Status,
 			BookedAt:   booking.CreatedAt.String(),
 		})
@@ -417,120 +523,317 @@ func (s *VendorService) ApproveBooking(ctx context.Context, req *pb.ApproveBooki
 	if req.VendorId == "" {
 		return nil, status.Errorf(codes.InvalidArgument, "vendor_id is required")
 	}
-	booking, err := s.vendorRepo.GetBookingById(ctx, req.GetBookingId())
+
+	event, err := bookingDecisionEvent(req.Status, req.RejectionReasonCode, req.RejectionNote)
 	if err != nil {
-		return nil, status.Errorf(codes.NotFound, "booking not found: %v", err)
+		return nil, err
 	}
 
-	vendorUUID, _ := uuid.Parse(req.VendorId)
-
-	if booking.VendorID != vendorUUID {
-		return nil, status.Errorf(codes.PermissionDenied, "booking does not belong to the vendor")
+	if err := s.decideBooking(ctx, req.VendorId, req.GetBookingId(), req.Status, event); err != nil {
+		return nil, err
 	}
 
-	err = s.vendorRepo.UpdateBookingStatus(ctx, req.BookingId, req.Status)
-	if err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to update booking status: %v", err)
+	return &pb.ApproveBookingResponse{
+		Message: fmt.Sprintf("Booking %s successfully", req.Status),
+	}, nil
+}
+
+func bookingDecisionEvent(decision, reasonCode, note string) (*models.BookingEvent, error) {
+	if decision != "approved" && decision != "rejected" {
+		return nil, status.Errorf(codes.InvalidArgument, "Invalid status. Allowed values: 'approved', 'rejected'")
 	}
 
-	if req.Status == "rejected" {
+	event := &models.BookingEvent{
+		ActorRole: "vendor",
+		Action:    "booking_" + decision,
+	}
 
-		newTransaction := &clientModel.Transaction{
-			UserID:        booking.ClientID,
-			Purpose:       "Vendor Booking",
-			AmountPaid:    booking.Price,
-			PaymentMethod: "wallet",
-			DateOfPayment: time.Now(),
-			PaymentStatus: "refunded",
+	if decision == "rejected" {
+		if _, ok := models.RejectionReasons[reasonCode]; !ok {
+			return nil, status.Errorf(codes.InvalidArgument, "a valid rejection_reason_code is required")
 		}
-
-		newAdminWalletTransaction := &adminModel.AdminWalletTransaction{
-			Date:   time.Now(),
-			Type:   "Vendor Booking",
-			Amount: float64(booking.Price),
-			Status: "refunded",
+		if strings.TrimSpace(note) == "" {
+			return nil, status.Errorf(codes.InvalidArgument, "rejection_note is required")
 		}
+		event.ReasonCode = reasonCode
+		event.Reason = strings.TrimSpace(note)
+	}
 
-		clientIDStr := booking.ClientID.String()
-
-		err = s.vendorRepo.RefundAmount(ctx, s.cfg.ADMIN_EMAIL, clientIDStr, booking.Price)
+	return event, nil
+}
 
-		if err != nil {
-			return nil, status.Errorf(codes.Internal, "failed to refund amount %v", err)
-		}
+// decideBooking applies a vendor's approve or reject decision to a pending
+// booking, refunding the client on rejection.
+func (s *VendorService) decideBooking(ctx context.Context, vendorID, bookingID, decision string, event *models.BookingEvent) error {
+	vendorUUID, err := uuid.Parse(vendorID)
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid vendor ID format: %v", err)
+	}
 
-		err = s.vendorRepo.CreateTransaction(ctx, newTransaction)
-		if err != nil {
-			return nil, status.Errorf(codes.Internal, "failed to create transaction: %v", err)
-		}
+	booking, err := s.vendorRepo.GetBookingById(ctx, bookingID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return status.Errorf(codes.NotFound, "booking not found")
+	} else if err != nil {
+		return status.Errorf(codes.Internal, "failed to fetch booking: %v", err)
+	}
 
-		err = s.vendorRepo.CreateAdminWalletTransaction(ctx, newAdminWalletTransaction)
-		if err != nil {
-			return nil, status.Errorf(codes.Internal, "failed to create admin wallet transaction")
-		}
+	if booking.VendorID != vendorUUID {
+		return status.Errorf(codes.PermissionDenied, "booking does not belong to the vendor")
+	}
 
-		return &pb.ApproveBookingResponse{
-			Message: fmt.Sprintf("Booking %s successfully", req.Status),
-		}, nil
+	if booking.Status != "pending" {
+		return status.Errorf(codes.FailedPrecondition, "booking is already %s", booking.Status)
+	}
 
+	if _, err := s.vendorRepo.GetBundleItemByBookingID(ctx, booking.BookingID); err == nil {
+		return status.Errorf(codes.FailedPrecondition, "booking is part of a bundle, decide the bundle instead")
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return status.Errorf(codes.Internal, "failed to check bundle: %v", err)
 	}
 
-	err = s.vendorRepo.UpdateVendorApproval(ctx, req.BookingId, true)
-	if err != nil {
+	if decision != "rejected" {
+		if err := s.checkBookingApprovable(ctx, vendorID, booking); err != nil {
+			return err
+		}
+	}
 
-		return nil, status.Errorf(codes.Internal, "failed to update vendor approval: %v", err)
+	// System decisions, such as instant booking rules, keep uuid.Nil as
+	// their actor rather than being attributed to the vendor.
+	if event.ActorRole == "vendor" {
+		event.ActorID = vendorUUID
+	}
+	_, err = s.vendorRepo.DecideBooking(ctx, booking, decision, event)
+	if errors.Is(err, repository.ErrInvalidTransition) {
+		return status.Errorf(codes.FailedPrecondition, "%v", err)
+	} else if err != nil {
+		return status.Errorf(codes.Internal, "failed to record booking decision: %v", err)
 	}
-	if booking.IsVendorApproved && booking.IsClientApproved && !booking.IsFundReleased {
-		err = s.vendorRepo.ReleasePaymentToVendor(ctx, booking.VendorID.String(), float64(booking.Price))
-		if err != nil {
-			return nil, status.Errorf(codes.Internal, "failed to release payment: %v", err)
-		}
 
-		err = s.vendorRepo.MarkBookingAsConfirmedAndReleased(ctx, req.BookingId)
-		if err != nil {
-			return nil, status.Errorf(codes.Internal, "failed to update booking status: %v", err)
+	if decision == "rejected" {
+		if _, err := s.offerBookingSlot(ctx, booking); err != nil {
+			s.log.Error("Failed to offer freed slot to waitlist", bookingID, err)
 		}
+	}
+	return nil
+}
 
+// checkBookingApprovable verifies the booking is inside its service's booking
+// window and does not clash with the vendor's blocked time.
+func (s *VendorService) checkBookingApprovable(ctx context.Context, vendorID string, booking *adminModel.Booking) error {
+	duration := serviceDuration(nil)
+	if service, err := s.vendorRepo.GetServiceByTitle(ctx, vendorID, booking.Service); err == nil {
+		if reason := bookingWindowViolation(service, booking.CreatedAt, booking.Date); reason != "" {
+			return status.Errorf(codes.FailedPrecondition, "%s", reason)
+		}
+		duration = serviceDuration(service)
 	}
 
-	return &pb.ApproveBookingResponse{
-		Message: fmt.Sprintf("Booking %s successfully", req.Status),
-	}, nil
+	return s.checkBlockedTime(ctx, vendorID, booking.Date, booking.Date.Add(duration))
 }
 
+// GetVendorWallet splits what the vendor is owed three ways: pending money
+// still in escrow for their bookings, money held by disputes and withdrawals
+// in flight, and the available wallet balance they can withdraw. Balance is
+// the available balance, kept for older clients.
 func (s *VendorService) GetVendorWallet(ctx context.Context, req *pb.GetVendorWalletRequest) (*pb.GetVendorWalletResponse, error) {
 	vendorID := req.GetVendorId()
+	vendorUUID, err := uuid.Parse(vendorID)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid vendor ID format: %v", err)
+	}
+
 	wallet, err := s.vendorRepo.GetVendorWallet(ctx, vendorID)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to fetch client wallet: %v", err)
 	}
 
+	holds, err := s.vendorRepo.ListFundHolds(ctx, vendorUUID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to fetch fund holds: %v", err)
+	}
+
+	pending, held := money.Zero(), money.Zero()
+	var protoHolds []*pb.FundHold
+	for _, hold := range holds {
+		if hold.Kind == models.FundHoldEscrow {
+			pending, err = pending.Add(hold.Amount)
+		} else {
+			held, err = held.Add(hold.Amount)
+		}
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "invalid held amount: %v", err)
+		}
+
+		protoHolds = append(protoHolds, &pb.FundHold{
+			HoldId:     hold.ID.String(),
+			Kind:       hold.Kind,
+			SourceType: hold.SourceType,
+			SourceId:   hold.SourceID,
+			Amount:     moneyToProto(hold.Amount),
+			HeldSince:  timestamppb.New(hold.CreatedAt),
+		})
+	}
+
+	balance, err := majorToProto(wallet.WalletBalance)
+	if err != nil {
+		return nil, err
+	}
+	deposits, err := majorToProto(wallet.TotalDeposits)
+	if err != nil {
+		return nil, err
+	}
+	withdrawals, err := majorToProto(wallet.TotalWithdrawals)
+	if err != nil {
+		return nil, err
+	}
+
 	return &pb.GetVendorWalletResponse{
-		Balance:          float32(wallet.WalletBalance),
-		TotalDeposits:    float32(wallet.TotalDeposits),
-		TotalWithdrawals: float32(wallet.TotalWithdrawals),
+		Balance:          balance,
+		TotalDeposits:    deposits,
+		TotalWithdrawals: withdrawals,
+		PendingBalance:   moneyToProto(pending),
+		HeldBalance:      moneyToProto(held),
+		AvailableBalance: balance,
+		Holds:            protoHolds,
 	}, nil
 }
 
+// GetVendorTransactions lists the vendor's transactions oldest first, with
+// the running balance after each one.
 func (s *VendorService) GetVendorTransactions(ctx context.Context, req *pb.ViewVendorTransactionsRequest) (*pb.ViewVendorTransactionResponse, error) {
-	vendorID := req.GetVendorId()
-	walletTransactions, err := s.vendorRepo.GetVendorTransactions(ctx, vendorID)
+	filter, err := vendorTransactionFilter(req)
+	if err != nil {
+		return nil, err
+	}
+
+	walletTransactions, err := s.vendorRepo.GetVendorTransactions(ctx, filter)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to retrieve admin wallet transactions: %v", err.Error())
 	}
 
+	commissions, err := s.payoutCommissionsFor(ctx, walletTransactions)
+	if err != nil {
+		return nil, err
+	}
+
 	var protoTransactions []*pb.VendorTransaction
-	for _, txn := range walletTransactions {
-		protoTransactions = append(protoTransactions, &pb.VendorTransaction{
-			TransactionId: txn.TransactionID.String(),
-			Date:          txn.DateOfPayment.String(),
-			Type:          txn.Purpose,
-			Amount:        float32(txn.AmountPaid),
-			Status:        txn.PaymentStatus,
-		})
+	for i := range walletTransactions {
+		protoTransaction, err := vendorTransactionToProto(&walletTransactions[i], commissions)
+		if err != nil {
+			return nil, err
+		}
+		protoTransactions = append(protoTransactions, protoTransaction)
 	}
 
 	return &pb.ViewVendorTransactionResponse{
 		Transactions: protoTransactions,
 	}, nil
 }
+
+func (s *VendorService) GetBookingDetail(ctx context.Context, req *pb.GetBookingDetailRequest) (*pb.GetBookingDetailResponse, error) {
+	if req.BookingId == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "booking_id is required")
+	}
+
+	if req.VendorId == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "vendor_id is required")
+	}
+
+	vendorUUID, err := uuid.Parse(req.VendorId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid vendor ID format: %v", err)
+	}
+
+	booking, err := s.vendorRepo.GetBookingById(ctx, req.BookingId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, status.Errorf(codes.NotFound, "booking not found")
+	} else if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to fetch booking: %v", err)
+	}
+
+	if booking.VendorID != vendorUUID {
+		return nil, status.Errorf(codes.PermissionDenied, "booking does not belong to the vendor")
+	}
+
+	detail, err := s.vendorRepo.GetBookingDetail(ctx, req.BookingId)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to fetch booking detail: %v", err)
+	}
+
+	// Bookings made before snapshots were kept have none.
+	var serviceSnapshot *pb.Service
+	snapshot, err := s.vendorRepo.GetBookingSnapshot(ctx, req.BookingId)
+	if err == nil {
+		serviceSnapshot = &pb.Service{
+			Id:                  snapshot.ServiceID.String(),
+			ServiceTitle:        snapshot.ServiceTitle,
+			YearOfExperience:    int64(snapshot.YearOfExperience),
+			AvailableDate:       snapshot.AvailableDate.Format(time.RFC3339),
+			ServiceDescription:  snapshot.ServiceDescription,
+			CancellationPolicy:  snapshot.CancellationPolicy,
+			TermsAndConditions:  snapshot.TermsAndConditions,
+			ServiceDuration:     int64(snapshot.ServiceDuration),
+			ServicePrice:        moneyToProto(snapshot.ServicePrice),
+			AdditionalHourPrice: moneyToProto(snapshot.AdditionalHourPrice),
+			MinNoticeHours:      int64(snapshot.MinNoticeHours),
+			MaxAdvanceDays:      int64(snapshot.MaxAdvanceDays),
+		}
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, status.Errorf(codes.Internal, "failed to fetch service snapshot: %v", err)
+	}
+
+	transactions, err := s.vendorRepo.GetBookingTransactions(ctx, req.BookingId)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to fetch booking transactions: %v", err)
+	}
+
+	bookingEvents, err := s.vendorRepo.GetBookingEvents(ctx, req.BookingId)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to fetch booking events: %v", err)
+	}
+
+	events, err := buildBookingTimeline(detail, bookingEvents, transactions)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to build booking timeline: %v", err)
+	}
+
+	var timeline []*pb.BookingTimelineEvent
+	for _, event := range events {
+		timeline = append(timeline, &pb.BookingTimelineEvent{
+			Event:       event.Event,
+			Description: event.Description,
+			Amount:      moneyToProto(event.Amount),
+			Status:      event.Status,
+			OccurredAt:  timestamppb.New(event.OccurredAt),
+		})
+	}
+
+	price, err := majorToProto(int64(detail.Price))
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.GetBookingDetailResponse{
+		BookingId: detail.BookingID,
+		Service:   detail.Service,
+		Date:      timestamppb.New(detail.Date),
+		Price:     price,
+		Status:    detail.Status,
+		BookedAt:  detail.CreatedAt.Format(time.RFC3339),
+		Client: &pb.BookingClient{
+			ClientId:    detail.ClientID,
+			FirstName:   detail.ClientFirstName,
+			LastName:    detail.ClientLastName,
+			Email:       detail.ClientEmail,
+			PhoneNumber: detail.ClientPhone,
+		},
+		ServiceSnapshot: serviceSnapshot,
+		Payment: &pb.BookingPayment{
+			IsVendorApproved: detail.IsVendorApproved,
+			IsClientApproved: detail.IsClientApproved,
+			IsFundReleased:   detail.IsFundReleased,
+			EscrowStatus:     escrowStatus(detail),
+		},
+		Timeline: timeline,
+	}, nil
+}
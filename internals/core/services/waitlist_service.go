@@ -0,0 +1,312 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"time"
+
+	pb "github.com/AthulKrishna2501/proto-repo/vendor"
+	adminModel "github.com/AthulKrishna2501/zyra-admin-service/internals/core/models"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/models"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/repository"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultWaitlistOffer        = 24 * time.Hour
+	waitlistOfferExpiryInterval = 5 * time.Minute
+	waitlistNotificationJoined  = "waitlist_joined"
+	waitlistNotificationOffered = "waitlist_offer"
+	waitlistNotificationExpired = "waitlist_offer_expired"
+)
+
+var slotReleasingStatuses = []string{"rejected", "cancelled", "expired"}
+
+func notificationChannel(userID uuid.UUID) string {
+	return "notifications:" + userID.String()
+}
+
+// notifyUser publishes a notification for the user on Redis. Delivery is best
+// effort; failures are logged and never fail the calling request.
+func (s *VendorService) notifyUser(ctx context.Context, userID uuid.UUID, kind string, data interface{}) {
+	payload, err := json.Marshal(map[string]interface{}{
+		"type":       kind,
+		"data":       data,
+		"created_at": time.Now(),
+	})
+	if err == nil {
+		err = s.redisClient.Publish(ctx, notificationChannel(userID), payload).Err()
+	}
+	if err != nil {
+		s.log.Warn("Failed to publish notification", userID, kind, err)
+	}
+}
+
+func (s *VendorService) waitlistOfferDuration() time.Duration {
+	if s.cfg.WAITLIST_OFFER_HOURS > 0 {
+		return time.Duration(s.cfg.WAITLIST_OFFER_HOURS) * time.Hour
+	}
+	return defaultWaitlistOffer
+}
+
+func (s *VendorService) JoinWaitlist(ctx context.Context, req *pb.JoinWaitlistRequest) (*pb.JoinWaitlistResponse, error) {
+	clientUUID, err := uuid.Parse(req.ClientId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid client ID format: %v", err)
+	}
+
+	if req.Date == nil || !req.Date.AsTime().After(time.Now()) {
+		return nil, status.Errorf(codes.InvalidArgument, "date must be in the future")
+	}
+
+	service, err := s.vendorRepo.GetServiceByID(ctx, req.ServiceId)
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "service not found: %v", err)
+	}
+
+	if err := s.ensureClientNotBlocked(ctx, service.VendorID, clientUUID); err != nil {
+		return nil, err
+	}
+
+	day := startOfDay(req.Date.AsTime())
+
+	booked, err := s.vendorRepo.CountBookingsOnDate(ctx, service.VendorID, day, uuid.Nil)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to check availability: %v", err)
+	}
+	if booked == 0 {
+		return nil, status.Errorf(codes.FailedPrecondition, "date is still available, book it directly")
+	}
+
+	entry := &models.WaitlistEntry{
+		ServiceID: service.ID,
+		VendorID:  service.VendorID,
+		ClientID:  clientUUID,
+		Date:      day,
+		Status:    "waiting",
+	}
+
+	err = s.vendorRepo.CreateWaitlistEntry(ctx, entry)
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return nil, status.Errorf(codes.AlreadyExists, "already on the waitlist for this date")
+	} else if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to join waitlist: %v", err)
+	}
+
+	ahead, err := s.vendorRepo.CountWaitlistAhead(ctx, entry)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to fetch waitlist position: %v", err)
+	}
+
+	s.notifyUser(ctx, service.VendorID, waitlistNotificationJoined, map[string]interface{}{
+		"service_id": service.ID,
+		"date":       day,
+	})
+
+	return &pb.JoinWaitlistResponse{
+		EntryId:  entry.ID.String(),
+		Position: int32(ahead) + 1,
+		Message:  "Added to the waitlist",
+	}, nil
+}
+
+func (s *VendorService) LeaveWaitlist(ctx context.Context, req *pb.LeaveWaitlistRequest) (*pb.LeaveWaitlistResponse, error) {
+	if req.EntryId == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "entry_id is required")
+	}
+
+	clientUUID, err := uuid.Parse(req.ClientId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid client ID format: %v", err)
+	}
+
+	entry, err := s.vendorRepo.LeaveWaitlist(ctx, req.EntryId, clientUUID)
+	if errors.Is(err, repository.ErrWaitlistEntryNotFound) {
+		return nil, status.Errorf(codes.NotFound, "%v", err)
+	} else if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to leave waitlist: %v", err)
+	}
+
+	// A client giving up an offer frees the slot for the next one in line.
+	if entry.Status == "offered" {
+		if _, err := s.offerFreedSlot(ctx, entry.VendorID, entry.ServiceID, entry.Date); err != nil {
+			s.log.Error("Failed to offer slot to next waitlisted client", entry.VendorID, entry.Date, err)
+		}
+	}
+
+	return &pb.LeaveWaitlistResponse{
+		Message: "Removed from the waitlist",
+	}, nil
+}
+
+func (s *VendorService) ListWaitlist(ctx context.Context, req *pb.ListWaitlistRequest) (*pb.ListWaitlistResponse, error) {
+	if req.VendorId == "" && req.ClientId == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "vendor_id or client_id is required")
+	}
+
+	var vendorUUID, clientUUID uuid.UUID
+	var err error
+	if req.VendorId != "" {
+		if vendorUUID, err = uuid.Parse(req.VendorId); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid vendor ID format: %v", err)
+		}
+	}
+	if req.ClientId != "" {
+		if clientUUID, err = uuid.Parse(req.ClientId); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid client ID format: %v", err)
+		}
+	}
+
+	entries, err := s.vendorRepo.ListWaitlistEntries(ctx, vendorUUID, clientUUID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to fetch waitlist: %v", err)
+	}
+
+	var protoEntries []*pb.WaitlistEntry
+	for i := range entries {
+		entry := &entries[i]
+
+		ahead, err := s.vendorRepo.CountWaitlistAhead(ctx, entry)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to fetch waitlist position: %v", err)
+		}
+
+		protoEntry := &pb.WaitlistEntry{
+			EntryId:   entry.ID.String(),
+			ServiceId: entry.ServiceID.String(),
+			ClientId:  entry.ClientID.String(),
+			Date:      timestamppb.New(entry.Date),
+			Status:    entry.Status,
+			Position:  int32(ahead) + 1,
+		}
+		if entry.OfferExpiresAt != nil {
+			protoEntry.OfferExpiresAt = timestamppb.New(*entry.OfferExpiresAt)
+		}
+		protoEntries = append(protoEntries, protoEntry)
+	}
+
+	return &pb.ListWaitlistResponse{
+		Entries: protoEntries,
+	}, nil
+}
+
+// NotifySlotReleased is called when a booking is cancelled or expires outside
+// this service so the freed date can be offered to the waitlist.
+func (s *VendorService) NotifySlotReleased(ctx context.Context, req *pb.NotifySlotReleasedRequest) (*pb.NotifySlotReleasedResponse, error) {
+	if req.BookingId == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "booking_id is required")
+	}
+
+	booking, err := s.vendorRepo.GetBookingById(ctx, req.BookingId)
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "booking not found: %v", err)
+	}
+
+	if !slices.Contains(slotReleasingStatuses, booking.Status) {
+		return nil, status.Errorf(codes.FailedPrecondition, "booking is %s and still holds its slot", booking.Status)
+	}
+
+	entry, err := s.offerBookingSlot(ctx, booking)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to offer slot: %v", err)
+	}
+
+	response := &pb.NotifySlotReleasedResponse{Message: "No waitlisted client to offer the slot to"}
+	if entry != nil {
+		response.Offered = true
+		response.Message = "Slot offered to the next waitlisted client"
+	}
+	return response, nil
+}
+
+// offerBookingSlot offers the day a released booking held to the next client
+// waiting for the same service.
+func (s *VendorService) offerBookingSlot(ctx context.Context, booking *adminModel.Booking) (*models.WaitlistEntry, error) {
+	var serviceID uuid.UUID
+	snapshot, err := s.vendorRepo.GetBookingSnapshot(ctx, booking.BookingID.String())
+	if err == nil {
+		serviceID = snapshot.ServiceID
+	} else if errors.Is(err, gorm.ErrRecordNotFound) {
+		service, err := s.vendorRepo.GetServiceByTitle(ctx, booking.VendorID.String(), booking.Service)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		} else if err != nil {
+			return nil, err
+		}
+		serviceID = service.ID
+	} else {
+		return nil, err
+	}
+
+	return s.offerFreedSlot(ctx, booking.VendorID, serviceID, booking.Date)
+}
+
+// offerFreedSlot offers the vendor's day to the next client waiting for the
+// service once no booking holds it any more.
+func (s *VendorService) offerFreedSlot(ctx context.Context, vendorID, serviceID uuid.UUID, date time.Time) (*models.WaitlistEntry, error) {
+	day := startOfDay(date)
+
+	booked, err := s.vendorRepo.CountBookingsOnDate(ctx, vendorID, day, uuid.Nil)
+	if err != nil || booked > 0 {
+		return nil, err
+	}
+
+	entry, err := s.vendorRepo.OfferNextWaitlistEntry(ctx, serviceID, day, time.Now().Add(s.waitlistOfferDuration()))
+	if err != nil || entry == nil {
+		return nil, err
+	}
+
+	s.notifyUser(ctx, entry.ClientID, waitlistNotificationOffered, map[string]interface{}{
+		"entry_id":   entry.ID,
+		"service_id": entry.ServiceID,
+		"date":       entry.Date,
+		"expires_at": entry.OfferExpiresAt,
+	})
+
+	return entry, nil
+}
+
+// ExpireWaitlistOffers lapses offers that were not taken up in time and passes
+// each slot on to the next client in line.
+func (s *VendorService) ExpireWaitlistOffers(ctx context.Context) error {
+	expired, err := s.vendorRepo.ExpireWaitlistOffers(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range expired {
+		s.notifyUser(ctx, entry.ClientID, waitlistNotificationExpired, map[string]interface{}{
+			"entry_id":   entry.ID,
+			"service_id": entry.ServiceID,
+			"date":       entry.Date,
+		})
+
+		if _, err := s.offerFreedSlot(ctx, entry.VendorID, entry.ServiceID, entry.Date); err != nil {
+			s.log.Error("Failed to offer slot to next waitlisted client", entry.VendorID, entry.Date, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *VendorService) StartWaitlistOfferExpiry(ctx context.Context) {
+	ticker := time.NewTicker(waitlistOfferExpiryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.ExpireWaitlistOffers(ctx); err != nil {
+				s.log.Error("Waitlist offer expiry run failed", err)
+			}
+		}
+	}
+}
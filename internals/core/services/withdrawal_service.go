@@ -0,0 +1,299 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	pb "github.com/AthulKrishna2501/proto-repo/vendor"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/app/payout"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/models"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/money"
+	"github.com/AthulKrishna2501/zyra-vendor-service/internals/core/repository"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultWithdrawalMinAmount  = 500
+	defaultWithdrawalDailyLimit = 50000
+
+	withdrawalReconcileInterval = 10 * time.Minute
+
+	withdrawalNotificationDecided = "withdrawal_decided"
+)
+
+// withdrawalMinAmount and withdrawalDailyLimit read their configured values
+// in whole units.
+func (s *VendorService) withdrawalMinAmount() (money.Money, error) {
+	if s.cfg.WITHDRAWAL_MIN_AMOUNT > 0 {
+		return money.FromMajor(s.cfg.WITHDRAWAL_MIN_AMOUNT)
+	}
+	return money.FromMajor(defaultWithdrawalMinAmount)
+}
+
+func (s *VendorService) withdrawalDailyLimit() (money.Money, error) {
+	if s.cfg.WITHDRAWAL_DAILY_LIMIT > 0 {
+		return money.FromMajor(s.cfg.WITHDRAWAL_DAILY_LIMIT)
+	}
+	return money.FromMajor(defaultWithdrawalDailyLimit)
+}
+
+// RequestWithdrawal holds the amount from the vendor's wallet and queues the
+// withdrawal for admin approval.
+func (s *VendorService) RequestWithdrawal(ctx context.Context, req *pb.RequestWithdrawalRequest) (*pb.RequestWithdrawalResponse, error) {
+	vendorUUID, err := uuid.Parse(req.VendorId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid vendor ID format: %v", err)
+	}
+
+	amount, err := wholeMoneyFromProto(req.Amount, "amount")
+	if err != nil {
+		return nil, err
+	}
+
+	minAmount, err := s.withdrawalMinAmount()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "invalid minimum withdrawal amount: %v", err)
+	}
+	if below, err := amount.LessThan(minAmount); err != nil || below {
+		return nil, status.Errorf(codes.InvalidArgument, "minimum withdrawal amount is %s", minAmount)
+	}
+
+	dailyLimit, err := s.withdrawalDailyLimit()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "invalid daily withdrawal limit: %v", err)
+	}
+
+	withdrawal := &models.Withdrawal{
+		VendorID: vendorUUID,
+		Amount:   amount,
+	}
+
+	err = s.vendorRepo.RequestWithdrawal(ctx, withdrawal, dailyLimit)
+	if errors.Is(err, repository.ErrInsufficientBalance) {
+		return nil, status.Errorf(codes.FailedPrecondition, "insufficient wallet balance")
+	} else if errors.Is(err, repository.ErrWithdrawalLimitExceeded) {
+		return nil, status.Errorf(codes.ResourceExhausted, "withdrawals are limited to %s per day", dailyLimit)
+	} else if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to request withdrawal: %v", err)
+	}
+
+	return &pb.RequestWithdrawalResponse{
+		Message:    "Withdrawal requested successfully",
+		Withdrawal: withdrawalToProto(withdrawal),
+	}, nil
+}
+
+func (s *VendorService) ListWithdrawals(ctx context.Context, req *pb.ListWithdrawalsRequest) (*pb.ListWithdrawalsResponse, error) {
+	vendorUUID, err := uuid.Parse(req.VendorId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid vendor ID format: %v", err)
+	}
+
+	withdrawals, err := s.vendorRepo.ListWithdrawals(ctx, vendorUUID, req.Status)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to fetch withdrawals: %v", err)
+	}
+
+	return &pb.ListWithdrawalsResponse{
+		Withdrawals: withdrawalsToProto(withdrawals),
+	}, nil
+}
+
+// AdminListWithdrawals lists withdrawals across vendors, defaulting to the
+// ones still awaiting a decision.
+func (s *VendorService) AdminListWithdrawals(ctx context.Context, req *pb.AdminListWithdrawalsRequest) (*pb.ListWithdrawalsResponse, error) {
+	if _, err := s.authorizeAdmin(ctx, req.AdminId); err != nil {
+		return nil, err
+	}
+
+	statusFilter := req.Status
+	if statusFilter == "" {
+		statusFilter = "pending"
+	}
+
+	withdrawals, err := s.vendorRepo.ListWithdrawals(ctx, uuid.Nil, statusFilter)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to fetch withdrawals: %v", err)
+	}
+
+	return &pb.ListWithdrawalsResponse{
+		Withdrawals: withdrawalsToProto(withdrawals),
+	}, nil
+}
+
+// DecideWithdrawal approves or rejects a pending withdrawal. Approved
+// withdrawals are sent to the payout provider straight away; if the provider
+// declines, the withdrawal is marked failed and the hold returned to the
+// vendor. When the outcome is unknown the withdrawal stays processing and is
+// retried later under the same reference. Approving a withdrawal that is
+// already processing retries the payout. Without a payout provider nothing
+// can be approved.
+func (s *VendorService) DecideWithdrawal(ctx context.Context, req *pb.DecideWithdrawalRequest) (*pb.DecideWithdrawalResponse, error) {
+	adminUUID, err := s.authorizeAdmin(ctx, req.AdminId)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.WithdrawalId == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "withdrawal_id is required")
+	}
+
+	var withdrawal *models.Withdrawal
+	if req.Approve {
+		if s.payouts == nil {
+			return nil, status.Errorf(codes.FailedPrecondition, "no payout provider is configured")
+		}
+		withdrawal, err = s.vendorRepo.ApproveWithdrawal(ctx, req.WithdrawalId, adminUUID)
+	} else {
+		reason := strings.TrimSpace(req.Reason)
+		if reason == "" {
+			return nil, status.Errorf(codes.InvalidArgument, "reason is required when rejecting a withdrawal")
+		}
+		withdrawal, err = s.vendorRepo.RejectWithdrawal(ctx, req.WithdrawalId, adminUUID, reason)
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, status.Errorf(codes.NotFound, "withdrawal not found")
+	} else if errors.Is(err, repository.ErrWithdrawalNotPending) {
+		return nil, status.Errorf(codes.FailedPrecondition, "%v", err)
+	} else if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to update withdrawal: %v", err)
+	}
+
+	message := "Withdrawal rejected"
+	if req.Approve {
+		withdrawal, err = s.sendWithdrawal(ctx, withdrawal)
+		if err != nil {
+			return nil, err
+		}
+		switch withdrawal.Status {
+		case "paid":
+			message = "Withdrawal paid out"
+		case "failed":
+			message = "Withdrawal payout failed"
+		default:
+			message = "Withdrawal payout is processing"
+		}
+	}
+
+	s.notifyUser(ctx, withdrawal.VendorID, withdrawalNotificationDecided, map[string]interface{}{
+		"withdrawal_id": withdrawal.ID.String(),
+		"amount":        withdrawal.Amount.String(),
+		"status":        withdrawal.Status,
+	})
+
+	return &pb.DecideWithdrawalResponse{
+		Message:    message,
+		Withdrawal: withdrawalToProto(withdrawal),
+	}, nil
+}
+
+// sendWithdrawal pays out a processing withdrawal and records the result. The
+// withdrawal ID is the payout reference, so sending again after an unknown
+// outcome cannot pay the vendor twice. Only a definite decline releases the
+// hold; any other error leaves the withdrawal processing.
+func (s *VendorService) sendWithdrawal(ctx context.Context, withdrawal *models.Withdrawal) (*models.Withdrawal, error) {
+	reference, sendErr := s.payouts.Send(ctx, payout.Request{
+		Reference: withdrawal.ID.String(),
+		VendorID:  withdrawal.VendorID,
+		Amount:    withdrawal.Amount,
+	})
+	if errors.Is(sendErr, payout.ErrDeclined) {
+		s.log.Warn("Payout provider declined withdrawal", withdrawal.ID, sendErr)
+
+		failed, err := s.vendorRepo.FailWithdrawal(ctx, withdrawal.ID.String(), sendErr.Error())
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to record failed payout: %v", err)
+		}
+		return failed, nil
+	} else if sendErr != nil {
+		s.log.Warn("Payout outcome unknown, withdrawal left processing", withdrawal.ID, sendErr)
+		return withdrawal, nil
+	}
+
+	paid, err := s.vendorRepo.CompleteWithdrawal(ctx, withdrawal.ID.String(), reference)
+	if err != nil {
+		s.log.Error("Withdrawal paid out but not recorded", withdrawal.ID, reference, err)
+		return nil, status.Errorf(codes.Internal, "failed to record payout: %v", err)
+	}
+	return paid, nil
+}
+
+// ReconcileWithdrawals sends every processing withdrawal to the payout
+// provider again to settle payouts whose outcome was not known.
+func (s *VendorService) ReconcileWithdrawals(ctx context.Context) error {
+	if s.payouts == nil {
+		return nil
+	}
+
+	withdrawals, err := s.vendorRepo.ListWithdrawals(ctx, uuid.Nil, "processing")
+	if err != nil {
+		return err
+	}
+
+	for i := range withdrawals {
+		withdrawal, err := s.sendWithdrawal(ctx, &withdrawals[i])
+		if err != nil {
+			s.log.Error("Failed to reconcile withdrawal", withdrawals[i].ID, err)
+			continue
+		}
+		if withdrawal.Status != "processing" {
+			s.notifyUser(ctx, withdrawal.VendorID, withdrawalNotificationDecided, map[string]interface{}{
+				"withdrawal_id": withdrawal.ID.String(),
+				"amount":        withdrawal.Amount.String(),
+				"status":        withdrawal.Status,
+			})
+		}
+	}
+
+	return nil
+}
+
+func (s *VendorService) StartWithdrawalReconciler(ctx context.Context) {
+	ticker := time.NewTicker(withdrawalReconcileInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.ReconcileWithdrawals(ctx); err != nil {
+				s.log.Error("Withdrawal reconciliation run failed", err)
+			}
+		}
+	}
+}
+
+func withdrawalToProto(withdrawal *models.Withdrawal) *pb.Withdrawal {
+	protoWithdrawal := &pb.Withdrawal{
+		WithdrawalId:      withdrawal.ID.String(),
+		VendorId:          withdrawal.VendorID.String(),
+		Amount:            moneyToProto(withdrawal.Amount),
+		Status:            withdrawal.Status,
+		Reason:            withdrawal.Reason,
+		ProviderReference: withdrawal.ProviderReference,
+		FailureReason:     withdrawal.FailureReason,
+		CreatedAt:         timestamppb.New(withdrawal.CreatedAt),
+	}
+	if withdrawal.DecidedAt != nil {
+		protoWithdrawal.DecidedAt = timestamppb.New(*withdrawal.DecidedAt)
+	}
+	if withdrawal.PaidAt != nil {
+		protoWithdrawal.PaidAt = timestamppb.New(*withdrawal.PaidAt)
+	}
+	return protoWithdrawal
+}
+
+func withdrawalsToProto(withdrawals []models.Withdrawal) []*pb.Withdrawal {
+	var protoWithdrawals []*pb.Withdrawal
+	for i := range withdrawals {
+		protoWithdrawals = append(protoWithdrawals, withdrawalToProto(&withdrawals[i]))
+	}
+	return protoWithdrawals
+}
@@ -0,0 +1,608 @@
+package utils
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const icalTimeFormat = "20060102T150405Z"
+
+type CalendarEvent struct {
+	UID          string
+	Summary      string
+	Description  string
+	Start        time.Time
+	End          time.Time
+	Status       string
+	LastModified time.Time
+}
+
+func BuildICalendar(calendarName string, events []CalendarEvent) string {
+	var b strings.Builder
+
+	writeICalLine(&b, "BEGIN:VCALENDAR")
+	writeICalLine(&b, "VERSION:2.0")
+	writeICalLine(&b, "PRODID:-//Zyra//Vendor Bookings//EN")
+	writeICalLine(&b, "CALSCALE:GREGORIAN")
+	writeICalLine(&b, "METHOD:PUBLISH")
+	writeICalLine(&b, "X-WR-CALNAME:"+escapeICalText(calendarName))
+
+	stamp := time.Now().UTC().Format(icalTimeFormat)
+	for _, event := range events {
+		writeICalLine(&b, "BEGIN:VEVENT")
+		writeICalLine(&b, "UID:"+event.UID)
+		writeICalLine(&b, "DTSTAMP:"+stamp)
+		writeICalLine(&b, "DTSTART:"+event.Start.UTC().Format(icalTimeFormat))
+		writeICalLine(&b, "DTEND:"+event.End.UTC().Format(icalTimeFormat))
+		writeICalLine(&b, "SUMMARY:"+escapeICalText(event.Summary))
+		if event.Description != "" {
+			writeICalLine(&b, "DESCRIPTION:"+escapeICalText(event.Description))
+		}
+		if event.Status != "" {
+			writeICalLine(&b, "STATUS:"+event.Status)
+		}
+		if !event.LastModified.IsZero() {
+			writeICalLine(&b, "LAST-MODIFIED:"+event.LastModified.UTC().Format(icalTimeFormat))
+		}
+		writeICalLine(&b, "END:VEVENT")
+	}
+
+	writeICalLine(&b, "END:VCALENDAR")
+	return b.String()
+}
+
+func escapeICalText(text string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+	return replacer.Replace(text)
+}
+
+func unescapeICalText(text string) string {
+	replacer := strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n")
+	return replacer.Replace(text)
+}
+
+// writeICalLine folds content lines longer than 75 octets as required by RFC 5545.
+func writeICalLine(b *strings.Builder, line string) {
+	for len(line) > 75 {
+		cut := 75
+		for cut > 0 && !isUTF8Boundary(line, cut) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
+
+func isUTF8Boundary(s string, i int) bool {
+	return i >= len(s) || s[i]&0xC0 != 0x80
+}
+
+// maxRecurrenceSteps bounds how many periods of a recurrence rule are walked,
+// so a rule with a tiny interval and no end cannot stall a sync.
+const maxRecurrenceSteps = 50000
+
+// ParseICalendar extracts the busy events of every VEVENT in an iCalendar
+// document. Cancelled and transparent (free) events are skipped. Recurring
+// events are expanded into their occurrences that overlap [from, until),
+// leaving out EXDATEs and occurrences moved or cancelled by a RECURRENCE-ID
+// override; one-off events are returned whatever their date.
+func ParseICalendar(r io.Reader, from, until time.Time) ([]CalendarEvent, error) {
+	lines, err := unfoldICalLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var components []icalComponent
+	var current icalComponent
+	for _, line := range lines {
+		switch {
+		case line == "BEGIN:VEVENT":
+			current = icalComponent{}
+		case line == "END:VEVENT":
+			if current == nil {
+				continue
+			}
+			components = append(components, current)
+			current = nil
+		case current != nil:
+			if prop, ok := parseICalProperty(line); ok {
+				current[prop.name] = append(current[prop.name], prop)
+			}
+		}
+	}
+
+	// Overrides replace single occurrences of a series; the occurrence they
+	// replace is dropped from the series whether or not the override itself
+	// is busy.
+	overridden := map[string]map[int64]bool{}
+	for _, component := range components {
+		recurrenceID, ok := component.first("RECURRENCE-ID")
+		if !ok {
+			continue
+		}
+		at, _, err := parseICalTime(recurrenceID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RECURRENCE-ID %q: %w", recurrenceID.value, err)
+		}
+		uid := component.value("UID")
+		if overridden[uid] == nil {
+			overridden[uid] = map[int64]bool{}
+		}
+		overridden[uid][at.Unix()] = true
+	}
+
+	var events []CalendarEvent
+	for _, component := range components {
+		event, ok, err := icalEventFromComponent(component)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+
+		rrule, recurring := component.first("RRULE")
+		if !recurring {
+			events = append(events, event)
+			continue
+		}
+		if _, isOverride := component.first("RECURRENCE-ID"); isOverride {
+			events = append(events, event)
+			continue
+		}
+
+		rule, err := parseRecurrenceRule(rrule.value, event.Start.Location())
+		if err != nil {
+			return nil, fmt.Errorf("invalid RRULE %q: %w", rrule.value, err)
+		}
+
+		excluded := overridden[event.UID]
+		for _, exdate := range component["EXDATE"] {
+			for _, value := range strings.Split(exdate.value, ",") {
+				at, _, err := parseICalTime(icalProperty{params: exdate.params, value: value})
+				if err != nil {
+					return nil, fmt.Errorf("invalid EXDATE %q: %w", value, err)
+				}
+				if excluded == nil {
+					excluded = map[int64]bool{}
+				}
+				excluded[at.Unix()] = true
+			}
+		}
+
+		duration := event.End.Sub(event.Start)
+		for _, start := range rule.occurrences(event.Start, from.Add(-duration), until) {
+			if excluded[start.Unix()] {
+				continue
+			}
+			occurrence := event
+			occurrence.Start = start
+			occurrence.End = start.Add(duration)
+			events = append(events, occurrence)
+		}
+	}
+
+	return events, nil
+}
+
+// icalComponent holds a VEVENT's properties by name, in document order.
+type icalComponent map[string][]icalProperty
+
+func (c icalComponent) first(name string) (icalProperty, bool) {
+	if props := c[name]; len(props) > 0 {
+		return props[0], true
+	}
+	return icalProperty{}, false
+}
+
+func (c icalComponent) value(name string) string {
+	prop, _ := c.first(name)
+	return prop.value
+}
+
+// recurrenceRule is the subset of an RFC 5545 RRULE this package expands:
+// FREQ, INTERVAL, COUNT, UNTIL, BYDAY, BYMONTHDAY and BYMONTH.
+type recurrenceRule struct {
+	freq       string
+	interval   int
+	count      int
+	until      time.Time
+	byDay      []recurrenceDay
+	byMonthDay []int
+	byMonth    []time.Month
+}
+
+// recurrenceDay is a BYDAY entry such as MO, or 2TU and -1FR for the second
+// Tuesday and last Friday of the month.
+type recurrenceDay struct {
+	ordinal int
+	weekday time.Weekday
+}
+
+var icalWeekdays = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+func parseRecurrenceRule(value string, loc *time.Location) (*recurrenceRule, error) {
+	rule := &recurrenceRule{interval: 1}
+	for _, part := range strings.Split(value, ";") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("malformed part %q", part)
+		}
+		name, val := strings.ToUpper(kv[0]), strings.ToUpper(kv[1])
+
+		var err error
+		switch name {
+		case "FREQ":
+			switch val {
+			case "DAILY", "WEEKLY", "MONTHLY", "YEARLY":
+				rule.freq = val
+			default:
+				return nil, fmt.Errorf("unsupported frequency %s", val)
+			}
+		case "INTERVAL":
+			if rule.interval, err = strconv.Atoi(val); err != nil || rule.interval < 1 {
+				return nil, fmt.Errorf("invalid interval %s", val)
+			}
+		case "COUNT":
+			if rule.count, err = strconv.Atoi(val); err != nil || rule.count < 1 {
+				return nil, fmt.Errorf("invalid count %s", val)
+			}
+		case "UNTIL":
+			if rule.until, _, err = parseICalTime(icalProperty{params: map[string]string{}, value: val}); err != nil {
+				return nil, fmt.Errorf("invalid until %s", val)
+			}
+			if len(val) == 8 {
+				// A date-only UNTIL includes the whole day.
+				rule.until = time.Date(rule.until.Year(), rule.until.Month(), rule.until.Day(), 23, 59, 59, 0, loc)
+			}
+		case "BYDAY":
+			for _, day := range strings.Split(val, ",") {
+				if len(day) < 2 {
+					return nil, fmt.Errorf("invalid day %s", day)
+				}
+				weekday, ok := icalWeekdays[day[len(day)-2:]]
+				if !ok {
+					return nil, fmt.Errorf("invalid day %s", day)
+				}
+				ordinal := 0
+				if prefix := day[:len(day)-2]; prefix != "" {
+					if ordinal, err = strconv.Atoi(prefix); err != nil || ordinal == 0 {
+						return nil, fmt.Errorf("invalid day %s", day)
+					}
+				}
+				rule.byDay = append(rule.byDay, recurrenceDay{ordinal: ordinal, weekday: weekday})
+			}
+		case "BYMONTHDAY":
+			for _, day := range strings.Split(val, ",") {
+				n, err := strconv.Atoi(day)
+				if err != nil || n == 0 || n < -31 || n > 31 {
+					return nil, fmt.Errorf("invalid month day %s", day)
+				}
+				rule.byMonthDay = append(rule.byMonthDay, n)
+			}
+		case "BYMONTH":
+			for _, month := range strings.Split(val, ",") {
+				n, err := strconv.Atoi(month)
+				if err != nil || n < 1 || n > 12 {
+					return nil, fmt.Errorf("invalid month %s", month)
+				}
+				rule.byMonth = append(rule.byMonth, time.Month(n))
+			}
+		case "WKST":
+		default:
+			return nil, fmt.Errorf("unsupported part %s", name)
+		}
+	}
+
+	if rule.freq == "" {
+		return nil, fmt.Errorf("FREQ is required")
+	}
+	return rule, nil
+}
+
+// occurrences returns the starts of the series beginning at dtstart that fall
+// in [from, until), in order. COUNT counts from dtstart, including
+// occurrences before from.
+func (rule *recurrenceRule) occurrences(dtstart, from, until time.Time) []time.Time {
+	if !rule.until.IsZero() && rule.until.Before(until) {
+		until = rule.until.Add(time.Second)
+	}
+
+	var starts []time.Time
+	seen := 0
+	for step := 0; step < maxRecurrenceSteps; step++ {
+		candidates := rule.period(dtstart, step)
+		if len(candidates) == 0 {
+			continue
+		}
+		for _, start := range candidates {
+			if start.Before(dtstart) {
+				continue
+			}
+			if !start.Before(until) || (rule.count > 0 && seen >= rule.count) {
+				return starts
+			}
+			seen++
+			if !start.Before(from) {
+				starts = append(starts, start)
+			}
+		}
+	}
+	return starts
+}
+
+// period returns the sorted candidate starts in the step-th period of the
+// rule, at dtstart's time of day.
+func (rule *recurrenceRule) period(dtstart time.Time, step int) []time.Time {
+	at := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, dtstart.Hour(), dtstart.Minute(), dtstart.Second(), 0, dtstart.Location())
+	}
+	n := step * rule.interval
+
+	var days []time.Time
+	switch rule.freq {
+	case "DAILY":
+		day := at(dtstart.Year(), dtstart.Month(), dtstart.Day()+n)
+		if rule.matchesMonth(day) && rule.matchesWeekday(day) && rule.matchesMonthDay(day) {
+			days = append(days, day)
+		}
+	case "WEEKLY":
+		// Weeks start on Monday.
+		offset := (int(dtstart.Weekday()) + 6) % 7
+		monday := at(dtstart.Year(), dtstart.Month(), dtstart.Day()-offset+7*n)
+		for i := 0; i < 7; i++ {
+			day := monday.AddDate(0, 0, i)
+			if len(rule.byDay) == 0 && day.Weekday() != dtstart.Weekday() {
+				continue
+			}
+			if rule.matchesMonth(day) && rule.matchesWeekday(day) {
+				days = append(days, day)
+			}
+		}
+	case "MONTHLY":
+		first := at(dtstart.Year(), dtstart.Month()+time.Month(n), 1)
+		if rule.matchesMonth(first) {
+			days = rule.daysInMonth(first, dtstart.Day())
+		}
+	case "YEARLY":
+		months := rule.byMonth
+		if len(months) == 0 {
+			months = []time.Month{dtstart.Month()}
+		}
+		for _, month := range months {
+			days = append(days, rule.daysInMonth(at(dtstart.Year()+n, month, 1), dtstart.Day())...)
+		}
+	}
+
+	sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+	return days
+}
+
+// daysInMonth returns the days of the month starting at first that the
+// rule's BYMONTHDAY or BYDAY select, or defaultDay when it has neither.
+// Months too short for defaultDay are skipped, as RFC 5545 requires.
+func (rule *recurrenceRule) daysInMonth(first time.Time, defaultDay int) []time.Time {
+	length := first.AddDate(0, 1, -1).Day()
+
+	var days []time.Time
+	switch {
+	case len(rule.byMonthDay) > 0:
+		for _, n := range rule.byMonthDay {
+			if n < 0 {
+				n = length + 1 + n
+			}
+			if n >= 1 && n <= length {
+				days = append(days, first.AddDate(0, 0, n-1))
+			}
+		}
+	case len(rule.byDay) > 0:
+		for _, byDay := range rule.byDay {
+			var matches []time.Time
+			for d := 0; d < length; d++ {
+				if day := first.AddDate(0, 0, d); day.Weekday() == byDay.weekday {
+					matches = append(matches, day)
+				}
+			}
+			switch {
+			case byDay.ordinal == 0:
+				days = append(days, matches...)
+			case byDay.ordinal > 0 && byDay.ordinal <= len(matches):
+				days = append(days, matches[byDay.ordinal-1])
+			case byDay.ordinal < 0 && -byDay.ordinal <= len(matches):
+				days = append(days, matches[len(matches)+byDay.ordinal])
+			}
+		}
+	default:
+		if defaultDay <= length {
+			days = append(days, first.AddDate(0, 0, defaultDay-1))
+		}
+	}
+	return days
+}
+
+func (rule *recurrenceRule) matchesMonth(day time.Time) bool {
+	return len(rule.byMonth) == 0 || slices.Contains(rule.byMonth, day.Month())
+}
+
+func (rule *recurrenceRule) matchesWeekday(day time.Time) bool {
+	if len(rule.byDay) == 0 {
+		return true
+	}
+	for _, byDay := range rule.byDay {
+		if byDay.weekday == day.Weekday() {
+			return true
+		}
+	}
+	return false
+}
+
+func (rule *recurrenceRule) matchesMonthDay(day time.Time) bool {
+	if len(rule.byMonthDay) == 0 {
+		return true
+	}
+	length := day.AddDate(0, 1, -day.Day()).Day()
+	for _, n := range rule.byMonthDay {
+		if n == day.Day() || length+1+n == day.Day() {
+			return true
+		}
+	}
+	return false
+}
+
+type icalProperty struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+func unfoldICalLines(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var lines []string
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return lines, nil
+}
+
+func parseICalProperty(line string) (icalProperty, bool) {
+	colon := strings.Index(line, ":")
+	if colon < 0 {
+		return icalProperty{}, false
+	}
+
+	parts := strings.Split(line[:colon], ";")
+	prop := icalProperty{
+		name:   strings.ToUpper(parts[0]),
+		params: map[string]string{},
+		value:  line[colon+1:],
+	}
+	for _, param := range parts[1:] {
+		if kv := strings.SplitN(param, "=", 2); len(kv) == 2 {
+			prop.params[strings.ToUpper(kv[0])] = strings.Trim(kv[1], `"`)
+		}
+	}
+	return prop, true
+}
+
+func icalEventFromComponent(component icalComponent) (CalendarEvent, bool, error) {
+	if strings.EqualFold(component.value("STATUS"), "CANCELLED") || strings.EqualFold(component.value("TRANSP"), "TRANSPARENT") {
+		return CalendarEvent{}, false, nil
+	}
+
+	startProp, ok := component.first("DTSTART")
+	if !ok {
+		return CalendarEvent{}, false, nil
+	}
+
+	start, allDay, err := parseICalTime(startProp)
+	if err != nil {
+		return CalendarEvent{}, false, fmt.Errorf("invalid DTSTART %q: %w", startProp.value, err)
+	}
+
+	var end time.Time
+	if endProp, ok := component.first("DTEND"); ok {
+		end, _, err = parseICalTime(endProp)
+		if err != nil {
+			return CalendarEvent{}, false, fmt.Errorf("invalid DTEND %q: %w", endProp.value, err)
+		}
+	} else if durationProp, ok := component.first("DURATION"); ok {
+		duration, err := parseICalDuration(durationProp.value)
+		if err != nil {
+			return CalendarEvent{}, false, fmt.Errorf("invalid DURATION %q: %w", durationProp.value, err)
+		}
+		end = start.Add(duration)
+	} else if allDay {
+		end = start.AddDate(0, 0, 1)
+	} else {
+		end = start
+	}
+
+	if !end.After(start) {
+		return CalendarEvent{}, false, nil
+	}
+
+	return CalendarEvent{
+		UID:     component.value("UID"),
+		Summary: unescapeICalText(component.value("SUMMARY")),
+		Start:   start,
+		End:     end,
+	}, true, nil
+}
+
+func parseICalTime(prop icalProperty) (time.Time, bool, error) {
+	if prop.params["VALUE"] == "DATE" || len(prop.value) == 8 {
+		t, err := time.ParseInLocation("20060102", prop.value, time.UTC)
+		return t, true, err
+	}
+
+	if strings.HasSuffix(prop.value, "Z") {
+		t, err := time.Parse(icalTimeFormat, prop.value)
+		return t, false, err
+	}
+
+	loc := time.UTC
+	if tzid := prop.params["TZID"]; tzid != "" {
+		if l, err := time.LoadLocation(tzid); err == nil {
+			loc = l
+		}
+	}
+	t, err := time.ParseInLocation("20060102T150405", prop.value, loc)
+	return t, false, err
+}
+
+func parseICalDuration(value string) (time.Duration, error) {
+	value = strings.TrimPrefix(value, "+")
+	if !strings.HasPrefix(value, "P") {
+		return 0, fmt.Errorf("duration must start with P")
+	}
+
+	var total time.Duration
+	var number int
+	inTime := false
+	for _, ch := range value[1:] {
+		switch {
+		case ch >= '0' && ch <= '9':
+			number = number*10 + int(ch-'0')
+			continue
+		case ch == 'T':
+			inTime = true
+			continue
+		case ch == 'W':
+			total += time.Duration(number) * 7 * 24 * time.Hour
+		case ch == 'D':
+			total += time.Duration(number) * 24 * time.Hour
+		case ch == 'H' && inTime:
+			total += time.Duration(number) * time.Hour
+		case ch == 'M' && inTime:
+			total += time.Duration(number) * time.Minute
+		case ch == 'S' && inTime:
+			total += time.Duration(number) * time.Second
+		default:
+			return 0, fmt.Errorf("unexpected %q in duration", ch)
+		}
+		number = 0
+	}
+	return total, nil
+}
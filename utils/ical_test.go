@@ -0,0 +1,221 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func icalDoc(events ...string) string {
+	return "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n" + strings.Join(events, "") + "END:VCALENDAR\r\n"
+}
+
+func icalTime(t *testing.T, value string) time.Time {
+	t.Helper()
+	at, err := time.Parse(icalTimeFormat, value)
+	if err != nil {
+		t.Fatalf("bad test time %q: %v", value, err)
+	}
+	return at
+}
+
+func TestBuildICalendar(t *testing.T) {
+	start := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		event   CalendarEvent
+		want    []string
+		notWant []string
+	}{
+		{
+			name:  "escapes text",
+			event: CalendarEvent{UID: "a", Summary: "Wedding; hall, stage\\lights", Start: start, End: start.Add(time.Hour)},
+			want:  []string{`SUMMARY:Wedding\; hall\, stage\\lights`, "DTSTART:20260301T100000Z", "DTEND:20260301T110000Z"},
+		},
+		{
+			name:    "omits empty optional fields",
+			event:   CalendarEvent{UID: "b", Summary: "Shoot", Start: start, End: start.Add(time.Hour)},
+			notWant: []string{"DESCRIPTION:", "STATUS:", "LAST-MODIFIED:"},
+		},
+		{
+			name:  "folds long lines",
+			event: CalendarEvent{UID: "c", Summary: strings.Repeat("x", 100), Start: start, End: start.Add(time.Hour)},
+			want:  []string{"SUMMARY:" + strings.Repeat("x", 67) + "\r\n " + strings.Repeat("x", 33) + "\r\n"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := BuildICalendar("Bookings", []CalendarEvent{tt.event})
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("calendar does not contain %q:\n%s", want, got)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(got, notWant) {
+					t.Errorf("calendar unexpectedly contains %q:\n%s", notWant, got)
+				}
+			}
+			for _, line := range strings.Split(got, "\r\n") {
+				if len(line) > 75 {
+					t.Errorf("line longer than 75 octets: %q", line)
+				}
+			}
+		})
+	}
+}
+
+func TestBuildICalendarRoundTrip(t *testing.T) {
+	start := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	in := CalendarEvent{UID: "round", Summary: "Mehndi, sangeet; reception — " + strings.Repeat("ॐ", 40), Start: start, End: start.Add(2 * time.Hour)}
+
+	events, err := ParseICalendar(strings.NewReader(BuildICalendar("Bookings", []CalendarEvent{in})), start, start.AddDate(0, 1, 0))
+	if err != nil {
+		t.Fatalf("ParseICalendar: %v", err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("got %d events, want 1", len(events))
+	}
+	if got := events[0]; got.Summary != in.Summary || !got.Start.Equal(in.Start) || !got.End.Equal(in.End) {
+		t.Errorf("round trip = %+v, want %+v", got, in)
+	}
+}
+
+func TestParseICalendar(t *testing.T) {
+	from := icalTime(t, "20260301T000000Z")
+	until := icalTime(t, "20260401T000000Z")
+
+	tests := []struct {
+		name   string
+		doc    string
+		starts []string
+		ends   []string
+	}{
+		{
+			name:   "single event",
+			doc:    icalDoc("BEGIN:VEVENT\r\nUID:1\r\nDTSTART:20260305T100000Z\r\nDTEND:20260305T120000Z\r\nEND:VEVENT\r\n"),
+			starts: []string{"20260305T100000Z"},
+			ends:   []string{"20260305T120000Z"},
+		},
+		{
+			name:   "duration instead of end",
+			doc:    icalDoc("BEGIN:VEVENT\r\nUID:1\r\nDTSTART:20260305T100000Z\r\nDURATION:PT1H30M\r\nEND:VEVENT\r\n"),
+			starts: []string{"20260305T100000Z"},
+			ends:   []string{"20260305T113000Z"},
+		},
+		{
+			name:   "all-day event",
+			doc:    icalDoc("BEGIN:VEVENT\r\nUID:1\r\nDTSTART;VALUE=DATE:20260305\r\nEND:VEVENT\r\n"),
+			starts: []string{"20260305T000000Z"},
+			ends:   []string{"20260306T000000Z"},
+		},
+		{
+			name: "cancelled and free events are skipped",
+			doc: icalDoc(
+				"BEGIN:VEVENT\r\nUID:1\r\nSTATUS:CANCELLED\r\nDTSTART:20260305T100000Z\r\nDTEND:20260305T120000Z\r\nEND:VEVENT\r\n",
+				"BEGIN:VEVENT\r\nUID:2\r\nTRANSP:TRANSPARENT\r\nDTSTART:20260306T100000Z\r\nDTEND:20260306T120000Z\r\nEND:VEVENT\r\n",
+			),
+		},
+		{
+			name:   "folded lines are unfolded",
+			doc:    icalDoc("BEGIN:VEVENT\r\nUID:1\r\nDTSTART:20260305T1\r\n 00000Z\r\nDTEND:20260305T120000Z\r\nEND:VEVENT\r\n"),
+			starts: []string{"20260305T100000Z"},
+			ends:   []string{"20260305T120000Z"},
+		},
+		{
+			name:   "daily with count",
+			doc:    icalDoc("BEGIN:VEVENT\r\nUID:1\r\nDTSTART:20260305T100000Z\r\nDTEND:20260305T110000Z\r\nRRULE:FREQ=DAILY;COUNT=3\r\nEND:VEVENT\r\n"),
+			starts: []string{"20260305T100000Z", "20260306T100000Z", "20260307T100000Z"},
+		},
+		{
+			name:   "daily with interval and until",
+			doc:    icalDoc("BEGIN:VEVENT\r\nUID:1\r\nDTSTART:20260305T100000Z\r\nDTEND:20260305T110000Z\r\nRRULE:FREQ=DAILY;INTERVAL=2;UNTIL=20260309T100000Z\r\nEND:VEVENT\r\n"),
+			starts: []string{"20260305T100000Z", "20260307T100000Z", "20260309T100000Z"},
+		},
+		{
+			name:   "weekly by day",
+			doc:    icalDoc("BEGIN:VEVENT\r\nUID:1\r\nDTSTART:20260302T090000Z\r\nDTEND:20260302T100000Z\r\nRRULE:FREQ=WEEKLY;BYDAY=MO,WE;COUNT=4\r\nEND:VEVENT\r\n"),
+			starts: []string{"20260302T090000Z", "20260304T090000Z", "20260309T090000Z", "20260311T090000Z"},
+		},
+		{
+			name:   "exdate removes an occurrence",
+			doc:    icalDoc("BEGIN:VEVENT\r\nUID:1\r\nDTSTART:20260305T100000Z\r\nDTEND:20260305T110000Z\r\nRRULE:FREQ=DAILY;COUNT=3\r\nEXDATE:20260306T100000Z\r\nEND:VEVENT\r\n"),
+			starts: []string{"20260305T100000Z", "20260307T100000Z"},
+		},
+		{
+			name: "recurrence-id override moves an occurrence",
+			doc: icalDoc(
+				"BEGIN:VEVENT\r\nUID:1\r\nDTSTART:20260305T100000Z\r\nDTEND:20260305T110000Z\r\nRRULE:FREQ=DAILY;COUNT=3\r\nEND:VEVENT\r\n",
+				"BEGIN:VEVENT\r\nUID:1\r\nRECURRENCE-ID:20260306T100000Z\r\nDTSTART:20260306T150000Z\r\nDTEND:20260306T160000Z\r\nEND:VEVENT\r\n",
+			),
+			starts: []string{"20260305T100000Z", "20260307T100000Z", "20260306T150000Z"},
+		},
+		{
+			name: "cancelled override drops an occurrence",
+			doc: icalDoc(
+				"BEGIN:VEVENT\r\nUID:1\r\nDTSTART:20260305T100000Z\r\nDTEND:20260305T110000Z\r\nRRULE:FREQ=DAILY;COUNT=3\r\nEND:VEVENT\r\n",
+				"BEGIN:VEVENT\r\nUID:1\r\nRECURRENCE-ID:20260306T100000Z\r\nSTATUS:CANCELLED\r\nDTSTART:20260306T100000Z\r\nDTEND:20260306T110000Z\r\nEND:VEVENT\r\n",
+			),
+			starts: []string{"20260305T100000Z", "20260307T100000Z"},
+		},
+		{
+			name:   "monthly on the last friday",
+			doc:    icalDoc("BEGIN:VEVENT\r\nUID:1\r\nDTSTART:20260130T180000Z\r\nDTEND:20260130T200000Z\r\nRRULE:FREQ=MONTHLY;BYDAY=-1FR\r\nEND:VEVENT\r\n"),
+			starts: []string{"20260327T180000Z"},
+		},
+		{
+			name:   "monthly by month day",
+			doc:    icalDoc("BEGIN:VEVENT\r\nUID:1\r\nDTSTART:20260115T080000Z\r\nDTEND:20260115T090000Z\r\nRRULE:FREQ=MONTHLY;BYMONTHDAY=1,15\r\nEND:VEVENT\r\n"),
+			starts: []string{"20260301T080000Z", "20260315T080000Z"},
+		},
+		{
+			name:   "only occurrences overlapping the window",
+			doc:    icalDoc("BEGIN:VEVENT\r\nUID:1\r\nDTSTART:20250101T230000Z\r\nDTEND:20250102T010000Z\r\nRRULE:FREQ=YEARLY;BYMONTH=2,3;BYMONTHDAY=28,31\r\nEND:VEVENT\r\n"),
+			starts: []string{"20260228T230000Z", "20260328T230000Z", "20260331T230000Z"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events, err := ParseICalendar(strings.NewReader(tt.doc), from, until)
+			if err != nil {
+				t.Fatalf("ParseICalendar: %v", err)
+			}
+			if len(events) != len(tt.starts) {
+				t.Fatalf("got %d events %+v, want %d", len(events), events, len(tt.starts))
+			}
+			for i, event := range events {
+				if want := icalTime(t, tt.starts[i]); !event.Start.Equal(want) {
+					t.Errorf("event %d starts at %s, want %s", i, event.Start, want)
+				}
+				if i < len(tt.ends) {
+					if want := icalTime(t, tt.ends[i]); !event.End.Equal(want) {
+						t.Errorf("event %d ends at %s, want %s", i, event.End, want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestParseICalendarErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+	}{
+		{"bad start", icalDoc("BEGIN:VEVENT\r\nUID:1\r\nDTSTART:tomorrow\r\nEND:VEVENT\r\n")},
+		{"bad duration", icalDoc("BEGIN:VEVENT\r\nUID:1\r\nDTSTART:20260305T100000Z\r\nDURATION:1H\r\nEND:VEVENT\r\n")},
+		{"unsupported frequency", icalDoc("BEGIN:VEVENT\r\nUID:1\r\nDTSTART:20260305T100000Z\r\nDTEND:20260305T110000Z\r\nRRULE:FREQ=HOURLY\r\nEND:VEVENT\r\n")},
+		{"unsupported rule part", icalDoc("BEGIN:VEVENT\r\nUID:1\r\nDTSTART:20260305T100000Z\r\nDTEND:20260305T110000Z\r\nRRULE:FREQ=DAILY;BYSETPOS=1\r\nEND:VEVENT\r\n")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from := icalTime(t, "20260301T000000Z")
+			if _, err := ParseICalendar(strings.NewReader(tt.doc), from, from.AddDate(0, 1, 0)); err == nil {
+				t.Error("ParseICalendar succeeded, want an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	PDFPageWidth  = 595.0
+	PDFPageHeight = 842.0
+)
+
+// PDFText is a run of text placed on a page. X and Y are in points from the
+// bottom-left corner.
+type PDFText struct {
+	X    float64
+	Y    float64
+	Size float64
+	Bold bool
+	Text string
+}
+
+// BuildPDF writes an A4 document with one page per entry in pages, using the
+// standard Helvetica fonts so nothing has to be embedded. Those fonts only
+// cover WinAnsiEncoding, so other characters are transliterated where that
+// reads naturally ("ś" as "s", "₹" as "Rs.") and otherwise printed as '?'.
+// Text in other scripts, such as Devanagari names, needs the HTML rendering.
+func BuildPDF(pages [][]PDFText) []byte {
+	if len(pages) == 0 {
+		pages = [][]PDFText{nil}
+	}
+
+	var objects []string
+
+	// Objects 1-4 are the catalog, page tree and the two fonts; each page
+	// then takes a page object followed by its content stream.
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+2*i)
+	}
+	objects = append(objects,
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>",
+	)
+
+	for i, page := range pages {
+		var content bytes.Buffer
+		for _, text := range page {
+			font := "F1"
+			if text.Bold {
+				font = "F2"
+			}
+			fmt.Fprintf(&content, "BT /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n", font, text.Size, text.X, text.Y, escapePDFText(text.Text))
+		}
+
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+				PDFPageWidth, PDFPageHeight, 6+2*i),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()),
+		)
+	}
+
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.4\n")
+
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = b.Len()
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	return b.Bytes()
+}
+
+// winAnsiExtras are the characters WinAnsiEncoding places between 0x80 and
+// 0x9f, where Latin-1 has control codes.
+var winAnsiExtras = map[rune]byte{
+	'€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87,
+	'ˆ': 0x88, '‰': 0x89, 'Š': 0x8a, '‹': 0x8b, 'Œ': 0x8c, 'Ž': 0x8e,
+	'‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97,
+	'˜': 0x98, '™': 0x99, 'š': 0x9a, '›': 0x9b, 'œ': 0x9c, 'ž': 0x9e, 'Ÿ': 0x9f,
+}
+
+// pdfSubstitutes spells out characters the standard fonts lack.
+var pdfSubstitutes = map[rune]string{
+	'₹':      "Rs.",
+	'\u2009': " ",
+	'\u202f': " ",
+	'\u2212': "-",
+}
+
+func winAnsiByte(r rune) (byte, bool) {
+	if (r >= 0x20 && r < 0x7f) || (r >= 0xa0 && r <= 0xff) {
+		return byte(r), true
+	}
+	c, ok := winAnsiExtras[r]
+	return c, ok
+}
+
+// toWinAnsi encodes text for the standard fonts.
+func toWinAnsi(text string) []byte {
+	var out []byte
+	for _, r := range text {
+		if c, ok := winAnsiByte(r); ok {
+			out = append(out, c)
+			continue
+		}
+		if r == '\n' || r == '\r' || r == '\t' {
+			out = append(out, ' ')
+			continue
+		}
+		if sub, ok := pdfSubstitutes[r]; ok {
+			out = append(out, sub...)
+			continue
+		}
+		out = append(out, transliterate(r)...)
+	}
+	return out
+}
+
+// transliterate falls back to the compatibility decomposition of r without
+// its accents, or '?' when that still cannot be encoded. A combining accent
+// on its own is dropped.
+func transliterate(r rune) []byte {
+	var out []byte
+	marks := 0
+	for _, d := range norm.NFKD.String(string(r)) {
+		if unicode.Is(unicode.Mn, d) {
+			marks++
+			continue
+		}
+		c, ok := winAnsiByte(d)
+		if !ok {
+			return []byte{'?'}
+		}
+		out = append(out, c)
+	}
+	if len(out) == 0 && marks == 0 {
+		return []byte{'?'}
+	}
+	return out
+}
+
+// escapePDFText encodes text as the body of a PDF string literal in
+// WinAnsiEncoding.
+func escapePDFText(text string) string {
+	var b strings.Builder
+	for _, c := range toWinAnsi(text) {
+		switch {
+		case c == '\\' || c == '(' || c == ')':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c < 0x80:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "\\%03o", c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,89 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"testing"
+)
+
+func TestEscapePDFText(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{name: "ascii", text: "Invoice INV-000001", want: "Invoice INV-000001"},
+		{name: "string delimiters", text: `a (b) \c`, want: `a \(b\) \\c`},
+		{name: "line breaks", text: "12 Main St\nKochi\r\n", want: "12 Main St Kochi  "},
+		{name: "latin-1", text: "Café Zoë", want: `Caf\351 Zo\353`},
+		{name: "win-ansi extras", text: "“Gold” – €5 …", want: `\223Gold\224 \226 \2005 \205`},
+		{name: "rupee sign", text: "₹1,500", want: "Rs.1,500"},
+		{name: "accents outside latin-1", text: "Śrī Kṛṣṇa", want: "Sri Krsna"},
+		{name: "decomposed accents", text: "Cafe\u0301", want: "Cafe"},
+		{name: "ligature", text: "ﬁne", want: "fine"},
+		{name: "other scripts", text: "राम", want: "???"},
+		{name: "control characters", text: "a\x00b\x7fc", want: "a?b?c"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := escapePDFText(tt.text); got != tt.want {
+				t.Errorf("escapePDFText(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildPDF(t *testing.T) {
+	tests := []struct {
+		name  string
+		pages [][]PDFText
+		count int
+	}{
+		{name: "no pages", count: 1},
+		{name: "one page", pages: [][]PDFText{{{X: 40, Y: 800, Size: 12, Bold: true, Text: "Receipt (copy)"}}}, count: 1},
+		{name: "two pages", pages: [][]PDFText{{{X: 40, Y: 800, Size: 10, Text: "one"}}, {{X: 40, Y: 800, Size: 10, Text: "two"}}}, count: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := BuildPDF(tt.pages)
+
+			if !bytes.HasPrefix(doc, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(doc, []byte("%%EOF\n")) {
+				t.Fatalf("document is not framed as a PDF:\n%s", doc)
+			}
+			if want := fmt.Sprintf("/Count %d", tt.count); !bytes.Contains(doc, []byte(want)) {
+				t.Errorf("document does not contain %q", want)
+			}
+
+			// Every xref entry has to point at the start of its object.
+			match := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(doc)
+			if match == nil {
+				t.Fatal("no startxref")
+			}
+			xref, _ := strconv.Atoi(string(match[1]))
+			if !bytes.HasPrefix(doc[xref:], []byte("xref\n")) {
+				t.Fatalf("startxref %d does not point at the xref table", xref)
+			}
+			entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(doc[xref:], -1)
+			if len(entries) != 4+2*tt.count {
+				t.Fatalf("xref has %d objects, want %d", len(entries), 4+2*tt.count)
+			}
+			for i, entry := range entries {
+				offset, _ := strconv.Atoi(string(entry[1]))
+				if want := fmt.Sprintf("%d 0 obj\n", i+1); !bytes.HasPrefix(doc[offset:], []byte(want)) {
+					t.Errorf("xref entry %d points at %q", i+1, doc[offset:offset+10])
+				}
+			}
+
+			// Stream lengths must match their content.
+			for _, stream := range regexp.MustCompile(`(?s)<< /Length (\d+) >>\nstream\n(.*?)endstream`).FindAllSubmatch(doc, -1) {
+				if length, _ := strconv.Atoi(string(stream[1])); length != len(stream[2]) {
+					t.Errorf("stream length %d, content is %d bytes", length, len(stream[2]))
+				}
+			}
+		})
+	}
+}